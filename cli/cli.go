@@ -0,0 +1,201 @@
+// Package cli implements the medix command: global flags, the project
+// config and a registry of subcommands, one file per command.
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+
+	"github.com/mrizkifadil26/medix/internal/db"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/logger"
+)
+
+// Command is a medix subcommand.
+type Command struct {
+	Name    string
+	Summary string
+	Run     func(env *Env, args []string) error
+}
+
+var commandRegistry = utils.NewRegistry[Command]()
+
+// Register adds a subcommand; commands register themselves from init.
+func Register(cmd Command) {
+	commandRegistry.Register(cmd.Name, cmd)
+}
+
+// Env is what every command receives: the resolved project settings and
+// where to write results.
+type Env struct {
+	Project Project
+
+	// Out receives command results. With --json it is the real stdout while
+	// os.Stdout is pointed at stderr, so progress chatter from the packages
+	// underneath never mixes with the JSON document.
+	Out io.Writer
+
+	ctx context.Context
+}
+
+// Context is cancelled on interrupt.
+func (e *Env) Context() context.Context { return e.ctx }
+
+// ConfigPath joins parts onto the project config directory.
+func (e *Env) ConfigPath(parts ...string) string {
+	return filepath.Join(append([]string{e.Project.ConfigDir}, parts...)...)
+}
+
+// OpenDB opens the project database.
+func (e *Env) OpenDB() *sql.DB {
+	return db.Open(e.Project.DB)
+}
+
+// Emit writes v as JSON under --json, otherwise calls human.
+func (e *Env) Emit(v any, human func(w io.Writer)) error {
+	if e.Project.JSON {
+		enc := json.NewEncoder(e.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	if human != nil {
+		human(e.Out)
+	}
+
+	return nil
+}
+
+// Main runs medix with os.Args[1:] and returns the exit code.
+//
+//	medix [global flags] <command> [command flags]
+func Main(args []string) int {
+	global := flag.NewFlagSet("medix", flag.ContinueOnError)
+	global.Usage = func() { usage(global.Output()) }
+
+	var (
+		projectPath = global.String("project", "", "Project config file (default medix.json or medix.yaml)")
+		dbPath      = global.String("db", "", "SQLite database path")
+		configDir   = global.String("config-dir", "", "Directory holding stage configs")
+		logLevel    = global.String("log-level", "", "error, warn, info, debug or trace")
+		jsonOut     = global.Bool("json", false, "Print results as JSON")
+	)
+
+	if err := global.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if global.NArg() == 0 || global.Arg(0) == "help" {
+		usage(os.Stdout)
+		return 0
+	}
+
+	project, err := LoadProject(*projectPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return 1
+	}
+
+	if *dbPath != "" {
+		project.DB = *dbPath
+	}
+	if *configDir != "" {
+		project.ConfigDir = *configDir
+	}
+	if *logLevel != "" {
+		project.LogLevel = *logLevel
+	}
+	if *jsonOut {
+		project.JSON = true
+	}
+
+	level, err := logger.ParseLevel(project.LogLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return 2
+	}
+	logger.InitSimple(true, level, os.Stderr)
+
+	name := global.Arg(0)
+	cmd, ok := commandRegistry.Get(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "❌ unknown command %q\n\n", name)
+		usage(os.Stderr)
+		return 2
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the first Ctrl-C cancels ctx so the command can save its state; after
+	// that the default handler is back and a second one quits immediately
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	go func() {
+		select {
+		case <-interrupt:
+			signal.Stop(interrupt)
+			fmt.Fprintln(os.Stderr, "⏹ interrupted, saving state (Ctrl-C again to quit now)")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	env := &Env{Project: project, Out: os.Stdout, ctx: ctx}
+	if project.JSON {
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+	}
+
+	if err := cmd.Run(env, global.Args()[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", name, err)
+		return 1
+	}
+
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: medix [global flags] <command> [command flags]
+
+Global flags:
+  -project     Project config file (default medix.json or medix.yaml)
+  -db          SQLite database path
+  -config-dir  Directory holding stage configs
+  -log-level   error, warn, info, debug or trace
+  -json        Print results as JSON
+
+Commands:
+`)
+
+	cmds := commandRegistry.All()
+	names := make([]string, 0, len(cmds))
+	for name := range cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, cmds[name].Summary)
+	}
+
+	fmt.Fprintln(w, "\nRun 'medix <command> -h' for command flags.")
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/iconconv"
+	"github.com/mrizkifadil26/medix/progress"
+)
+
+func init() {
+	Register(Command{
+		Name:    "convert-icons",
+		Summary: "Build multi-resolution .ico files from the PNG folder of an icon pack",
+		Run:     runConvertIcons,
+	})
+}
+
+type convertResult struct {
+	*iconconv.Result
+	Progress *progress.Progress `json:"progress,omitempty"`
+}
+
+func runConvertIcons(env *Env, argv []string) error {
+	fset := flag.NewFlagSet("convert-icons", flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: medix convert-icons [-icons dir | -in dir -out dir] [-sizes 16,32,...] [-resample filter] [-square pad|stretch] [-force]")
+		fset.PrintDefaults()
+	}
+
+	var (
+		iconDir  = fset.String("icons", env.Project.Progress.IconDir, "Icon pack directory with PNG and ICO folders")
+		in       = fset.String("in", "", "PNG folder (default <icons>/PNG)")
+		out      = fset.String("out", "", "ICO folder (default <icons>/ICO)")
+		sizes    = fset.String("sizes", "", "Icon sizes (default 16,24,32,48,64,128,256)")
+		resample = fset.String("resample", "lanczos", "Resampling filter: "+strings.Join(iconconv.FilterNames(), ", "))
+		square   = fset.String("square", iconconv.SquarePad, "Non-square PNGs: pad (transparent border) or stretch")
+		force    = fset.Bool("force", false, "Rebuild icons that are up to date")
+		workers  = fset.Int("workers", 4, "Icons converted at once")
+	)
+
+	if err := fset.Parse(argv); err != nil {
+		return err
+	}
+
+	cfg := iconconv.Config{
+		Input:    *in,
+		Output:   *out,
+		Resample: *resample,
+		Square:   *square,
+		Force:    *force,
+		Workers:  *workers,
+	}
+	if *iconDir != "" {
+		if cfg.Input == "" {
+			cfg.Input = filepath.Join(*iconDir, "PNG")
+		}
+		if cfg.Output == "" {
+			cfg.Output = filepath.Join(*iconDir, "ICO")
+		}
+	}
+	if cfg.Input == "" || cfg.Output == "" {
+		return fmt.Errorf("no icon directory: pass -icons, -in and -out, or set progress.iconDir in the project config")
+	}
+
+	if *sizes != "" {
+		parsed, err := iconconv.ParseSizes(*sizes)
+		if err != nil {
+			return err
+		}
+		cfg.Sizes = parsed
+	}
+
+	res, err := iconconv.Run(cfg)
+	if err != nil {
+		return err
+	}
+
+	result := convertResult{Result: res}
+	if *iconDir != "" {
+		if result.Progress, err = progress.Collect(*iconDir); err != nil {
+			return err
+		}
+	}
+
+	return env.Emit(result, func(w io.Writer) {
+		for _, path := range res.Converted {
+			fmt.Fprintf(w, "🖼️ %s\n", path)
+		}
+		for _, f := range res.Failed {
+			fmt.Fprintf(w, "❌ %s: %s\n", f.Path, f.Error)
+		}
+		fmt.Fprintf(w, "✅ %d converted, %d up to date, %d failed\n", len(res.Converted), res.UpToDate, len(res.Failed))
+
+		if result.Progress != nil {
+			fmt.Fprintln(w)
+			result.Progress.Print(w)
+		}
+	})
+}
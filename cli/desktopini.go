@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/utils/desktopini"
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+)
+
+func init() {
+	Register(Command{
+		Name:    "desktop-ini",
+		Summary: "Check folder desktop.ini files and write missing ones",
+		Run:     runDesktopIni,
+	})
+}
+
+type desktopIniResult struct {
+	Reports []desktopini.Report `json:"reports"`
+	Written int                 `json:"written"`
+	Journal string              `json:"journal,omitempty"`
+	Script  string              `json:"script,omitempty"`
+}
+
+func runDesktopIni(env *Env, argv []string) error {
+	fset := flag.NewFlagSet("desktop-ini", flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: medix desktop-ini [-apply] [-encoding auto|ansi|utf16] [-script file.cmd] <dir>...")
+		fset.PrintDefaults()
+	}
+
+	var (
+		apply    = fset.Bool("apply", false, "Write desktop.ini into folders with an icon but none")
+		encoding = fset.String("encoding", string(desktopini.Auto), "Encoding of written files: auto, ansi or utf16")
+		script   = fset.String("script", "", "Write the attrib commands Explorer needs to a .cmd file to run on Windows")
+		journal  = fset.String("journal", fsjournal.DefaultDir, "Journal directory")
+	)
+
+	if err := fset.Parse(argv); err != nil {
+		return err
+	}
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return fmt.Errorf("missing <dir>")
+	}
+
+	enc := desktopini.Encoding(*encoding)
+	switch enc {
+	case desktopini.Auto, desktopini.ANSI, desktopini.UTF16:
+	default:
+		return fmt.Errorf("unknown encoding %q", *encoding)
+	}
+
+	var result desktopIniResult
+	for _, root := range fset.Args() {
+		reports, err := desktopini.Scan(root)
+		if err != nil {
+			return err
+		}
+		result.Reports = append(result.Reports, reports...)
+	}
+
+	if *apply {
+		var j *fsjournal.Journal
+		for i := range result.Reports {
+			r := &result.Reports[i]
+			if r.Status != desktopini.StatusMissing || r.Icon == "" {
+				continue
+			}
+			if j == nil {
+				var err error
+				if j, err = fsjournal.Begin(*journal, "desktop-ini"); err != nil {
+					return err
+				}
+				defer j.Close()
+				result.Journal = j.ID()
+			}
+			if err := desktopini.Generate(r, enc, j); err != nil {
+				return fmt.Errorf("wrote %d files (undo with: %s): %w", result.Written, undoCommand(*journal, result.Journal), err)
+			}
+			result.Written++
+		}
+	}
+
+	if *script != "" {
+		var b strings.Builder
+		b.WriteString("@echo off\r\n")
+		for _, r := range result.Reports {
+			for _, a := range r.Attributes {
+				b.WriteString(a.Command + "\r\n")
+			}
+		}
+		if err := os.WriteFile(*script, []byte(b.String()), 0o644); err != nil {
+			return err
+		}
+		result.Script = *script
+	}
+
+	return env.Emit(result, func(w io.Writer) {
+		counts := map[desktopini.Status]int{}
+		for _, r := range result.Reports {
+			counts[r.Status]++
+			switch r.Status {
+			case desktopini.StatusInvalid:
+				fmt.Fprintf(w, "❌ %s\n", r.Ini)
+			case desktopini.StatusMissing:
+				if r.Icon != "" {
+					fmt.Fprintf(w, "📝 %s: no %s, icon %s\n", r.Dir, desktopini.Name, r.Icon)
+				} else {
+					fmt.Fprintf(w, "⚠️ %s: no %s\n", r.Dir, desktopini.Name)
+				}
+			}
+			for _, p := range r.Problems {
+				fmt.Fprintf(w, "   %s\n", p)
+			}
+		}
+
+		fmt.Fprintf(w, "✅ %d ok, %d invalid, %d missing", counts[desktopini.StatusOK], counts[desktopini.StatusInvalid], counts[desktopini.StatusMissing])
+		if result.Journal != "" {
+			fmt.Fprintf(w, "; wrote %d (undo with: %s)", result.Written, undoCommand(*journal, result.Journal))
+		}
+		fmt.Fprintln(w)
+
+		if result.Script != "" {
+			fmt.Fprintf(w, "🪟 Explorer only reads desktop.ini from read-only folders; run %s on Windows to set the attributes\n", result.Script)
+		} else {
+			fmt.Fprintln(w, "🪟 Explorer only reads desktop.ini from read-only folders; use -script to get the attrib commands")
+		}
+	})
+}
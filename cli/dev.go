@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"flag"
+	"time"
+
+	"github.com/mrizkifadil26/medix/server"
+	"github.com/mrizkifadil26/medix/utils/logger"
+	"github.com/mrizkifadil26/medix/webgen"
+)
+
+func init() {
+	Register(Command{
+		Name:    "dev",
+		Summary: "Serve the site locally and rebuild on changes",
+		Run:     runDev,
+	})
+}
+
+func runDev(env *Env, argv []string) error {
+	fs := flag.NewFlagSet("dev", flag.ContinueOnError)
+
+	var (
+		port      = fs.String("port", env.Project.Dev.Port, "HTTP port")
+		inputDir  = fs.String("input", env.Project.Webgen.Input, "Input directory")
+		outputDir = fs.String("output", env.Project.Webgen.Output, "Output directory")
+	)
+
+	if err := fs.Parse(argv); err != nil {
+		return err
+	}
+
+	logger.Info("🔁 Starting dev server with auto-rebuild")
+
+	if err := webgen.GenerateSite(*inputDir, *outputDir); err != nil {
+		logger.Error("❌ Initial site generation failed: " + err.Error())
+	}
+	logger.Info("Initial site generation complete")
+
+	ctx := env.Context()
+
+	go server.WatchAndBuild()
+	go server.OpenBrowser("http://localhost:" + *port)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(*outputDir, *port)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Warn("⚠️  Received shutdown signal. Cleaning up...")
+
+	// Optional: give time to finish writes, close file handles, etc.
+	time.Sleep(300 * time.Millisecond)
+	logger.Info("👋 Gracefully exited.")
+	return nil
+}
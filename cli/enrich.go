@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mrizkifadil26/medix/enricher"
+	_ "github.com/mrizkifadil26/medix/enricher/local"
+	_ "github.com/mrizkifadil26/medix/enricher/tmdb"
+	"github.com/mrizkifadil26/medix/schema"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+func init() {
+	Register(Command{
+		Name:    "enrich",
+		Summary: "Enrich normalized output (TMDb, local files)",
+		Run:     runEnrich,
+	})
+}
+
+func runEnrich(env *Env, argv []string) error {
+	args, err := enricher.ParseCLI(argv)
+	if err != nil {
+		return err
+	}
+
+	configPath, err := env.stageConfig(args.ConfigPath, "enricher", args.Args)
+	if err != nil {
+		return err
+	}
+
+	config, err := enricher.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if err := utils.MergeInto(&config, &args.Config, utils.MergeOptions{
+		Overwrite: true,
+		Recursive: true,
+	}); err != nil {
+		return err
+	}
+
+	if config.Stream {
+		fmt.Println("⚡ Streaming enrichment from:", config.Root)
+		if err := enricher.EnrichFile(env.Context(), config.Root, config.Output, &config); err != nil {
+			return err
+		}
+	} else {
+		data := utils.NewOrderedMap[string, any]()
+
+		fmt.Println("⚡ Loading root data for enrichment from:", config.Root)
+		if err := utils.LoadJSON(config.Root, data); err != nil {
+			return fmt.Errorf("load root data from %s: %w", config.Root, err)
+		}
+
+		enriched, err := enricher.Enrich(env.Context(), data, &config)
+		if err != nil {
+			return err
+		}
+
+		if config.Validate {
+			if err := schema.Validate(schema.Enriched, enriched); err != nil {
+				return err
+			}
+		}
+
+		fmt.Println("💾 Writing output to:", config.Output)
+		if err := utils.WriteJSONAtomic(config.Output, enriched); err != nil {
+			return fmt.Errorf("save output: %w", err)
+		}
+	}
+
+	return env.Emit(map[string]string{
+		"input":  config.Root,
+		"output": config.Output,
+	}, func(w io.Writer) {
+		fmt.Fprintln(w, "✅ Done enriching.")
+	})
+}
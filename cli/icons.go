@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mrizkifadil26/medix/iconsync"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+func init() {
+	Register(Command{
+		Name:    "index-icons",
+		Summary: "Index the .ico files of the icon packs",
+		Run:     runIndexIcons,
+	})
+	Register(Command{
+		Name:    "sync-icons",
+		Summary: "Match indexed icons to scanned media folders",
+		Run:     runSyncIcons,
+	})
+}
+
+// iconConfig resolves -config, or a label such as movies to
+// <configDir>/<prefix>-<label>.json.
+func (e *Env) iconConfig(configPath *string, prefix string, args []string) (string, error) {
+	if *configPath != "" {
+		return *configPath, nil
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("missing -config or <label>")
+	}
+	return e.ConfigPath(prefix + "-" + args[0] + ".json"), nil
+}
+
+func runIndexIcons(env *Env, argv []string) error {
+	fset := flag.NewFlagSet("index-icons", flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: medix index-icons [-config file | <label>]")
+		fset.PrintDefaults()
+	}
+
+	configPath := fset.String("config", "", "Path to config file (JSON or YAML)")
+	if err := fset.Parse(argv); err != nil {
+		return err
+	}
+
+	path, err := env.iconConfig(configPath, "iconmap", fset.Args())
+	if err != nil {
+		return err
+	}
+
+	config, err := utils.LoadConfig[iconsync.IndexConfig](path)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	index, err := iconsync.BuildIndex(&config)
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteJSONAtomic(config.Output, index); err != nil {
+		return err
+	}
+
+	return env.Emit(index, func(w io.Writer) {
+		fmt.Fprintf(w, "✅ Indexed %d icons in %d groups → %s\n", index.TotalItems, index.GroupCount, config.Output)
+	})
+}
+
+func runSyncIcons(env *Env, argv []string) error {
+	fset := flag.NewFlagSet("sync-icons", flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: medix sync-icons [-config file | <label>]")
+		fset.PrintDefaults()
+	}
+
+	configPath := fset.String("config", "", "Path to config file (JSON or YAML)")
+	if err := fset.Parse(argv); err != nil {
+		return err
+	}
+
+	path, err := env.iconConfig(configPath, "sync", fset.Args())
+	if err != nil {
+		return err
+	}
+
+	config, err := utils.LoadConfig[iconsync.SyncConfig](path)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	out, err := iconsync.Sync(&config)
+	if err != nil {
+		return err
+	}
+
+	report := out.Report
+	return env.Emit(report, func(w io.Writer) {
+		for _, a := range report.Ambiguous {
+			fmt.Fprintf(w, "⚠️ %s: %s\n", a.Path, a.Reason)
+			for _, c := range a.Candidates {
+				fmt.Fprintf(w, "   %.2f %s\n", c.Score, c.Path)
+			}
+		}
+		fmt.Fprintf(w, "✅ %d of %d entries have an icon (%d by slug, %d by title); %d unmatched, %d ambiguous, %d icons unused\n",
+			report.BySlug+report.ByTitle, report.Media, report.BySlug, report.ByTitle,
+			len(report.Unmatched), len(report.Ambiguous), report.Unused)
+		fmt.Fprintf(w, "   → %s, %s, %s\n", config.OutMedia, config.OutIcon, iconsync.ReportPath(&config))
+	})
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mrizkifadil26/medix/internal/db"
+)
+
+func init() {
+	Register(Command{
+		Name:    "migrate",
+		Summary: "Apply database migrations",
+		Run:     runMigrate,
+	})
+}
+
+func runMigrate(env *Env, argv []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	migrationsDir := fs.String("migrations", env.Project.Migrations, "Migrations directory")
+
+	if err := fs.Parse(argv); err != nil {
+		return err
+	}
+
+	d := env.OpenDB()
+	defer d.Close()
+
+	db.RunMigrations(d, *migrationsDir)
+
+	return env.Emit(map[string]string{
+		"db":         env.Project.DB,
+		"migrations": *migrationsDir,
+	}, func(w io.Writer) {
+		fmt.Fprintln(w, "✅ Migrations applied successfully!")
+	})
+}
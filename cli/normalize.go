@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mrizkifadil26/medix/normalizer"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/extractor"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/formatter"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/replacer"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/transformer"
+	"github.com/mrizkifadil26/medix/schema"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+func init() {
+	Register(Command{
+		Name:    "normalize",
+		Summary: "Normalize scan output with a field/action config",
+		Run:     runNormalize,
+	})
+}
+
+func runNormalize(env *Env, argv []string) error {
+	args, err := normalizer.ParseCLI(argv)
+	if err != nil {
+		return err
+	}
+
+	configPath, err := env.stageConfig(args.ConfigPath, "normalizer", args.Args)
+	if err != nil {
+		return err
+	}
+
+	config, err := utils.LoadConfig[normalizer.Config](configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if err := utils.MergeInto(&config, &args.Config, utils.MergeOptions{
+		Overwrite: true,
+		Recursive: true,
+	}); err != nil {
+		return err
+	}
+
+	n := normalizer.New(&config)
+
+	if config.Stream {
+		if config.OutputPath == "" {
+			return fmt.Errorf("streaming mode requires an output path")
+		}
+
+		if err := n.NormalizeFile(config.Root, config.OutputPath); err != nil {
+			return err
+		}
+	} else {
+		data := utils.NewOrderedMap[string, any]()
+		if err := utils.LoadJSON(config.Root, data); err != nil {
+			return err
+		}
+
+		result, normErr := n.Normalize(data)
+
+		// Drifted output is never written
+		if normErr == nil && config.Validate {
+			if err := schema.Validate(schema.Normalized, result); err != nil {
+				return err
+			}
+		}
+
+		// Always try to write output, even if errors occurred
+		if config.OutputPath != "" {
+			if err := utils.WriteJSON(config.OutputPath, result); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+		}
+
+		if normErr != nil {
+			return normErr
+		}
+	}
+
+	return env.Emit(map[string]string{
+		"input":  config.Root,
+		"output": config.OutputPath,
+	}, func(w io.Writer) {
+		fmt.Fprintf(w, "✅ Normalized %s → %s\n", config.Root, config.OutputPath)
+	})
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mrizkifadil26/medix/normdb"
+)
+
+func init() {
+	Register(Command{
+		Name:    "normdb",
+		Summary: "Group scanned files into movies in the database",
+		Run:     runNormDB,
+	})
+}
+
+func runNormDB(env *Env, argv []string) error {
+	fs := flag.NewFlagSet("normdb", flag.ContinueOnError)
+	kind := fs.String("kind", env.Project.Scan.Kind, "Parent type to normalize")
+
+	if err := fs.Parse(argv); err != nil {
+		return err
+	}
+
+	conn := env.OpenDB()
+	defer conn.Close()
+
+	if err := conn.Ping(); err != nil {
+		return fmt.Errorf("ping db: %w", err)
+	}
+
+	// group movie files
+	movieGroups, _, err := normdb.GroupFiles(conn, *kind)
+	if err != nil {
+		return fmt.Errorf("grouping movies failed: %w", err)
+	}
+
+	// normalize movies into DB
+	var groups []normdb.MovieGroup
+	for _, g := range movieGroups {
+		groups = append(groups, *g)
+	}
+
+	if err := normdb.NormalizeMovies(conn, groups, *kind); err != nil {
+		return fmt.Errorf("normalize movies failed: %w", err)
+	}
+
+	return env.Emit(map[string]any{
+		"kind":   *kind,
+		"movies": len(groups),
+	}, func(w io.Writer) {
+		fmt.Fprintf(w, "✅ Normalized %d movies into DB\n", len(groups))
+	})
+}
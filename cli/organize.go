@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mrizkifadil26/medix/organizer"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+)
+
+func init() {
+	Register(Command{
+		Name:    "organize",
+		Summary: "Plan and apply folder moves and renames from enriched output",
+		Run:     runOrganize,
+	})
+}
+
+type organizeResult struct {
+	Plan    *organizer.Plan `json:"plan"`
+	Applied bool            `json:"applied"`
+	Done    int             `json:"done,omitempty"`
+	Journal string          `json:"journal,omitempty"`
+}
+
+func runOrganize(env *Env, argv []string) error {
+	fset := flag.NewFlagSet("organize", flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: medix organize [-apply] [-config file | <label>]")
+		fset.PrintDefaults()
+	}
+
+	var (
+		configPath = fset.String("config", "", "Path to config file (JSON or YAML)")
+		apply      = fset.Bool("apply", false, "Carry out the plan instead of printing it")
+	)
+
+	if err := fset.Parse(argv); err != nil {
+		return err
+	}
+
+	path, err := env.stageConfig(configPath, "organizer", fset.Args())
+	if err != nil {
+		return err
+	}
+
+	config, err := utils.LoadConfig[organizer.Config](path)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	plan, err := organizer.PlanFile(config)
+	if err != nil {
+		return err
+	}
+
+	result := organizeResult{Plan: plan}
+	if *apply && len(plan.Ops) > 0 {
+		j, err := fsjournal.Begin(config.JournalDir, "organize")
+		if err != nil {
+			return err
+		}
+
+		result.Journal = j.ID()
+		result.Done, err = organizer.Apply(plan, j)
+		j.Close()
+		if err != nil {
+			return fmt.Errorf("applied %d of %d steps (undo with: %s): %w", result.Done, len(plan.Ops), undoCommand(config.JournalDir, result.Journal), err)
+		}
+		result.Applied = true
+	}
+
+	return env.Emit(result, func(w io.Writer) {
+		if !result.Applied {
+			for _, op := range plan.Ops {
+				if op.Kind == organizer.OpMkdir {
+					fmt.Fprintf(w, "📁 %s\n", op.Dst)
+				} else {
+					fmt.Fprintf(w, "➡️ %s\n   → %s\n", op.Src, op.Dst)
+				}
+			}
+		}
+
+		for _, s := range plan.Skipped {
+			fmt.Fprintf(w, "⚠️ skipped %s: %s\n", s.Path, s.Reason)
+		}
+
+		switch {
+		case result.Applied:
+			fmt.Fprintf(w, "✅ Applied %d steps; undo with: %s\n", result.Done, undoCommand(config.JournalDir, result.Journal))
+		case len(plan.Ops) == 0:
+			fmt.Fprintln(w, "✅ Nothing to do.")
+		default:
+			fmt.Fprintf(w, "📝 Dry run: %d steps, %d items skipped. Run with -apply to carry them out.\n", len(plan.Ops), len(plan.Skipped))
+		}
+	})
+}
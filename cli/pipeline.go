@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/pipeline"
+)
+
+func init() {
+	Register(Command{
+		Name:    "pipeline",
+		Summary: "Run scan → normalize → enrich → webgen from a pipeline definition",
+		Run:     runPipeline,
+	})
+}
+
+func runPipeline(env *Env, argv []string) error {
+	fs := flag.NewFlagSet("pipeline", flag.ContinueOnError)
+
+	var (
+		configPath = fs.String("config", env.ConfigPath(env.Project.Pipeline.Definition), "Path to pipeline definition (JSON or YAML)")
+		force      = fs.Bool("force", false, "Run every stage even if its inputs are unchanged")
+		only       = fs.String("only", "", "Comma-separated label pipelines to run")
+		parallel   = fs.Int("parallel", 0, "Label pipelines to run at once (overrides the definition)")
+	)
+
+	if err := fs.Parse(argv); err != nil {
+		return err
+	}
+
+	def, err := pipeline.LoadDefinition(*configPath)
+	if err != nil {
+		return err
+	}
+
+	opts := pipeline.Options{
+		Force:    *force,
+		Parallel: *parallel,
+	}
+	if *only != "" {
+		opts.Only = strings.Split(*only, ",")
+	}
+
+	report, err := pipeline.Run(env.Context(), def, opts)
+	if err != nil && report == nil {
+		return err
+	}
+
+	if emitErr := env.Emit(report, report.Print); emitErr != nil {
+		return emitErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return report.Err()
+}
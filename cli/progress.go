@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mrizkifadil26/medix/progress"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+func init() {
+	Register(Command{
+		Name:    "progress",
+		Summary: "Count RAW/PNG/ICO icon progress per genre",
+		Run:     runProgress,
+	})
+}
+
+func runProgress(env *Env, argv []string) error {
+	fs := flag.NewFlagSet("progress", flag.ContinueOnError)
+
+	var (
+		iconDir    = fs.String("icons", env.Project.Progress.IconDir, "Icon pack directory with RAW, PNG and ICO folders")
+		outputPath = fs.String("out", env.Project.Progress.Output, "Output JSON file")
+	)
+
+	if err := fs.Parse(argv); err != nil {
+		return err
+	}
+
+	if *iconDir == "" {
+		return fmt.Errorf("no icon directory: pass -icons or set progress.iconDir in the project config")
+	}
+
+	result, err := progress.Collect(*iconDir)
+	if err != nil {
+		return err
+	}
+
+	if *outputPath != "" {
+		if err := utils.WriteJSON(*outputPath, result); err != nil {
+			return fmt.Errorf("write JSON: %w", err)
+		}
+	}
+
+	return env.Emit(result, func(w io.Writer) {
+		result.Print(w)
+		if *outputPath != "" {
+			fmt.Fprintf(w, "✅ JSON written to %s\n", *outputPath)
+		}
+	})
+}
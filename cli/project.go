@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+// Project is the medix.json (or medix.yaml) file at the project root. It
+// supplies defaults for every subcommand; global flags override it and
+// command flags override both.
+type Project struct {
+	DB        string `json:"db,omitempty" yaml:"db,omitempty"`
+	ConfigDir string `json:"configDir,omitempty" yaml:"configDir,omitempty"`
+	LogLevel  string `json:"logLevel,omitempty" yaml:"logLevel,omitempty"`
+	JSON      bool   `json:"json,omitempty" yaml:"json,omitempty"`
+
+	Migrations string         `json:"migrations,omitempty" yaml:"migrations,omitempty"`
+	Scan       ScanConfig     `json:"scan,omitempty" yaml:"scan,omitempty"`
+	Progress   ProgressConfig `json:"progress,omitempty" yaml:"progress,omitempty"`
+	Webgen     WebgenConfig   `json:"webgen,omitempty" yaml:"webgen,omitempty"`
+	Dev        DevConfig      `json:"dev,omitempty" yaml:"dev,omitempty"`
+	Watch      WatchConfig    `json:"watch,omitempty" yaml:"watch,omitempty"`
+	Pipeline   PipelineConfig `json:"pipeline,omitempty" yaml:"pipeline,omitempty"`
+}
+
+type ScanConfig struct {
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"` // parent type stored in the DB: movie or tv
+}
+
+type ProgressConfig struct {
+	IconDir string `json:"iconDir,omitempty" yaml:"iconDir,omitempty"` // icon pack with RAW/PNG/ICO folders
+	Output  string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+type WebgenConfig struct {
+	Input  string `json:"input,omitempty" yaml:"input,omitempty"`
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+type DevConfig struct {
+	Port string `json:"port,omitempty" yaml:"port,omitempty"`
+}
+
+type WatchConfig struct {
+	Dir    string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Script string `json:"script,omitempty" yaml:"script,omitempty"`
+}
+
+type PipelineConfig struct {
+	Definition string `json:"definition,omitempty" yaml:"definition,omitempty"` // relative to configDir
+}
+
+// projectFiles are tried in order when --project is not given.
+var projectFiles = []string{"medix.json", "medix.yaml", "medix.yml"}
+
+func DefaultProject() Project {
+	return Project{
+		DB:         "db/sqlite/media.db",
+		ConfigDir:  "config",
+		LogLevel:   "info",
+		Migrations: "migrations",
+		Scan:       ScanConfig{Kind: "movie"},
+		Progress:   ProgressConfig{Output: "data/progress.json"},
+		Webgen:     WebgenConfig{Input: "data", Output: "dist"},
+		Dev:        DevConfig{Port: "8080"},
+		Watch:      WatchConfig{Dir: "data", Script: "./scripts/push-data.sh"},
+		Pipeline:   PipelineConfig{Definition: "pipeline.json"},
+	}
+}
+
+// LoadProject merges the project file over the defaults. path may be empty,
+// in which case $MEDIX_PROJECT and then medix.json/medix.yaml in the working
+// directory are used if present.
+func LoadProject(path string) (Project, error) {
+	project := DefaultProject()
+
+	if path == "" {
+		path = os.Getenv("MEDIX_PROJECT")
+	}
+
+	if path == "" {
+		for _, candidate := range projectFiles {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+	}
+
+	if path == "" {
+		return project, nil
+	}
+
+	file, err := utils.LoadConfig[Project](path)
+	if err != nil {
+		return project, fmt.Errorf("project %s: %w", path, err)
+	}
+
+	if err := utils.MergeInto(&project, &file, utils.MergeOptions{
+		Overwrite: true,
+		Recursive: true,
+	}); err != nil {
+		return project, err
+	}
+
+	return project, nil
+}
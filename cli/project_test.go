@@ -0,0 +1,50 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/cli"
+)
+
+func TestLoadProject_MergesOverDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "medix.yaml")
+	os.WriteFile(path, []byte("db: other.db\nprogress:\n  iconDir: /icons\n"), 0644)
+
+	project, err := cli.LoadProject(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if project.DB != "other.db" || project.Progress.IconDir != "/icons" {
+		t.Errorf("project values not applied: %+v", project)
+	}
+
+	// untouched keys keep their defaults
+	if project.ConfigDir != "config" || project.Progress.Output != "data/progress.json" {
+		t.Errorf("defaults lost: %+v", project)
+	}
+}
+
+func TestMain_UnknownCommand(t *testing.T) {
+	if code := cli.Main([]string{"-project", filepath.Join("..", "medix.json"), "nope"}); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+}
+
+func TestLoadProject_IconDirFromEnv(t *testing.T) {
+	project := filepath.Join("..", "medix.json")
+
+	t.Setenv("MEDIX_ICON_DIR", "")
+	p, err := cli.LoadProject(project)
+	if err != nil || p.Progress.IconDir != "" {
+		t.Errorf("unset: IconDir = %q, %v; want empty", p.Progress.IconDir, err)
+	}
+
+	t.Setenv("MEDIX_ICON_DIR", "/icons/Movies")
+	p, err = cli.LoadProject(project)
+	if err != nil || p.Progress.IconDir != "/icons/Movies" {
+		t.Errorf("set: IconDir = %q, %v", p.Progress.IconDir, err)
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mrizkifadil26/medix/scanner"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+func init() {
+	Register(Command{
+		Name:    "scan",
+		Summary: "Scan a media root into the database",
+		Run:     runScan,
+	})
+}
+
+func runScan(env *Env, argv []string) error {
+	config := scanner.DefaultConfig()
+
+	args, err := scanner.ParseCLI(argv)
+	if err != nil {
+		return err
+	}
+
+	configPath, err := env.stageConfig(args.ConfigPath, "scanner", args.Args)
+	if err == nil {
+		fileConfig, err := utils.LoadConfig[scanner.Config](configPath)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+
+		if err := utils.MergeInto(&config, &fileConfig, utils.MergeOptions{
+			Overwrite: true,
+			Recursive: true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// CLI overrides file + defaults
+	if err := utils.MergeInto(&config, &args.Config, utils.MergeOptions{
+		Overwrite: true,
+		Recursive: true,
+	}); err != nil {
+		return err
+	}
+
+	if config.Root == "" {
+		return fmt.Errorf("-root is required (or must be in config file)")
+	}
+
+	database := env.OpenDB()
+	defer database.Close()
+
+	if err := scanner.ScanDirectory(database, config.Root, env.Project.Scan.Kind); err != nil {
+		return err
+	}
+
+	return env.Emit(map[string]string{
+		"root": config.Root,
+		"kind": env.Project.Scan.Kind,
+		"db":   env.Project.DB,
+	}, func(w io.Writer) {
+		fmt.Fprintf(w, "✅ Scanned %s into %s\n", config.Root, env.Project.DB)
+	})
+}
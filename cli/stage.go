@@ -0,0 +1,18 @@
+package cli
+
+import "fmt"
+
+// stageConfig picks the config file for a stage command: -config wins,
+// otherwise the first positional argument is a label such as movies.final,
+// resolved to <configDir>/<dir>/media/<label>.json like the Makefile does.
+func (e *Env) stageConfig(configPath *string, dir string, args []string) (string, error) {
+	if configPath != nil && *configPath != "" {
+		return *configPath, nil
+	}
+
+	if len(args) == 0 {
+		return "", fmt.Errorf("missing -config or <label>")
+	}
+
+	return e.ConfigPath(dir, "media", args[0]+".json"), nil
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+)
+
+func init() {
+	Register(Command{
+		Name:    "undo",
+		Summary: "Reverse the file changes recorded in a journal",
+		Run:     runUndo,
+	})
+}
+
+func runUndo(env *Env, argv []string) error {
+	fset := flag.NewFlagSet("undo", flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: medix undo [-dir dir] <journal-id>")
+		fmt.Fprintln(fset.Output(), "       medix undo [-dir dir]            list journals")
+		fset.PrintDefaults()
+	}
+
+	dir := fset.String("dir", fsjournal.DefaultDir, "Journal directory")
+
+	if err := fset.Parse(argv); err != nil {
+		return err
+	}
+
+	if fset.NArg() == 0 {
+		infos, err := fsjournal.List(*dir)
+		if err != nil {
+			return err
+		}
+
+		return env.Emit(infos, func(w io.Writer) {
+			if len(infos) == 0 {
+				fmt.Fprintf(w, "No journals in %s\n", *dir)
+			}
+			for _, info := range infos {
+				state := ""
+				if info.Undone == info.Ops {
+					state = " (undone)"
+				} else if info.Undone > 0 {
+					state = fmt.Sprintf(" (%d undone)", info.Undone)
+				}
+				fmt.Fprintf(w, "%-32s %s  %d ops%s\n", info.ID, info.Started.Local().Format("2006-01-02 15:04"), info.Ops, state)
+			}
+		})
+	}
+
+	id := fset.Arg(0)
+	undone, err := fsjournal.Undo(*dir, id)
+	if err != nil {
+		return fmt.Errorf("%d steps reversed, the rest were left alone; run again after fixing:\n%w", undone, err)
+	}
+
+	return env.Emit(map[string]any{"journal": id, "undone": undone}, func(w io.Writer) {
+		fmt.Fprintf(w, "↩️ Reversed %d steps from %s\n", undone, id)
+	})
+}
+
+// undoCommand is the command that reverses journal id, naming the journal
+// directory when it is not the one undo looks in by default.
+func undoCommand(dir, id string) string {
+	if dir == "" || filepath.Clean(dir) == filepath.Clean(fsjournal.DefaultDir) {
+		return "medix undo " + id
+	}
+	if strings.ContainsAny(dir, " \t\"'") {
+		dir = strconv.Quote(dir)
+	}
+	return "medix undo -dir " + dir + " " + id
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/schema"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+func init() {
+	Register(Command{
+		Name:    "validate",
+		Summary: "Check stage outputs against their JSON Schema",
+		Run:     runValidate,
+	})
+}
+
+// fileReport is the result for one validated file.
+type fileReport struct {
+	File       string          `json:"file"`
+	Stage      schema.Stage    `json:"stage"`
+	Violations []itemViolation `json:"violations"`
+}
+
+type itemViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Item    *int   `json:"item,omitempty"` // index in items, when the path is inside one
+	Name    string `json:"name,omitempty"`
+}
+
+func runValidate(env *Env, argv []string) error {
+	fset := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: medix validate [-stage scan|normalized|enriched] <file or directory>...")
+		fset.PrintDefaults()
+	}
+
+	stageName := fset.String("stage", "", "Schema to use (default: from the path, e.g. output/normalized/...)")
+
+	if err := fset.Parse(argv); err != nil {
+		return err
+	}
+
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return fmt.Errorf("no files given")
+	}
+
+	var forced schema.Stage
+	if *stageName != "" {
+		stage, err := schema.ParseStage(*stageName)
+		if err != nil {
+			return err
+		}
+		forced = stage
+	}
+
+	files, err := jsonFiles(fset.Args())
+	if err != nil {
+		return err
+	}
+
+	var (
+		reports []fileReport
+		total   int
+	)
+
+	for _, file := range files {
+		stage := forced
+		if stage == "" {
+			inferred, ok := schema.StageFromPath(file)
+			if !ok {
+				return fmt.Errorf("%s: cannot tell the stage from the path, pass -stage", file)
+			}
+			stage = inferred
+		}
+
+		report, err := validateFile(file, stage)
+		if err != nil {
+			return err
+		}
+
+		total += len(report.Violations)
+		reports = append(reports, report)
+	}
+
+	if err := env.Emit(reports, func(w io.Writer) { printReports(w, reports) }); err != nil {
+		return err
+	}
+
+	if total > 0 {
+		return fmt.Errorf("%d schema violation(s)", total)
+	}
+
+	return nil
+}
+
+func validateFile(path string, stage schema.Stage) (fileReport, error) {
+	report := fileReport{File: path, Stage: stage, Violations: []itemViolation{}}
+
+	doc := utils.NewOrderedMap[string, any]()
+	if err := utils.LoadJSON(path, doc); err != nil {
+		return report, fmt.Errorf("%s: %w", path, err)
+	}
+
+	violations, err := schema.Check(stage, doc)
+	if err != nil {
+		return report, err
+	}
+
+	for _, v := range violations {
+		iv := itemViolation{Path: v.Path, Message: v.Message}
+
+		if idx, ok := itemIndex(v.Path); ok {
+			iv.Item = &idx
+			if name, err := jsonpath.Get(doc, fmt.Sprintf("items.%d.name", idx)); err == nil {
+				iv.Name, _ = name.(string)
+			}
+		}
+
+		report.Violations = append(report.Violations, iv)
+	}
+
+	return report, nil
+}
+
+// itemIndex extracts N from a violation path of the form items.N[...].
+func itemIndex(path string) (int, bool) {
+	rest, ok := strings.CutPrefix(path, "items.")
+	if !ok {
+		return 0, false
+	}
+
+	if i := strings.IndexByte(rest, '.'); i >= 0 {
+		rest = rest[:i]
+	}
+
+	idx, err := strconv.Atoi(rest)
+	return idx, err == nil
+}
+
+// jsonFiles expands directories into the .json files below them.
+func jsonFiles(args []string) ([]string, error) {
+	var files []string
+
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && filepath.Ext(path) == ".json" {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+func printReports(w io.Writer, reports []fileReport) {
+	for _, r := range reports {
+		if len(r.Violations) == 0 {
+			fmt.Fprintf(w, "✅ %s (%s)\n", r.File, r.Stage)
+			continue
+		}
+
+		fmt.Fprintf(w, "❌ %s (%s): %d violation(s)\n", r.File, r.Stage, len(r.Violations))
+		for _, v := range r.Violations {
+			if v.Name != "" {
+				fmt.Fprintf(w, "   %s: %s [%s]\n", v.Path, v.Message, v.Name)
+			} else {
+				fmt.Fprintf(w, "   %s: %s\n", v.Path, v.Message)
+			}
+		}
+	}
+}
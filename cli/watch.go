@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"flag"
+	"log"
+	"os/exec"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func init() {
+	Register(Command{
+		Name:    "watch",
+		Summary: "Run the deploy script when generated data changes",
+		Run:     runWatch,
+	})
+}
+
+func runWatch(env *Env, argv []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+
+	var (
+		dir    = fs.String("dir", env.Project.Watch.Dir, "Directory to watch")
+		script = fs.String("script", env.Project.Watch.Script, "Script to run after changes")
+	)
+
+	if err := fs.Parse(argv); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(*dir); err != nil {
+		return err
+	}
+	log.Printf("Watching '%s' for changes...", *dir)
+
+	ctx := env.Context()
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				log.Println("Detected change:", event.Name)
+				debounce = time.After(2 * time.Second)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Println("Watcher error:", err)
+
+		case <-debounce:
+			log.Println("Running deploy after change...")
+			runDeployScript(*script)
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func runDeployScript(script string) {
+	cmd := exec.Command("bash", script)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Printf("Deploy error: %v\nOutput: %s", err, output)
+		return
+	}
+	log.Println("Deploy successful.")
+}
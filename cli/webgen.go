@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mrizkifadil26/medix/webgen"
+)
+
+func init() {
+	Register(Command{
+		Name:    "webgen",
+		Summary: "Generate the static site",
+		Run:     runWebgen,
+	})
+}
+
+func runWebgen(env *Env, argv []string) error {
+	fs := flag.NewFlagSet("webgen", flag.ContinueOnError)
+
+	var (
+		inputDir  = fs.String("input", env.Project.Webgen.Input, "Input directory")
+		outputDir = fs.String("output", env.Project.Webgen.Output, "Output directory")
+		dryRun    = fs.Bool("dry", false, "Dry-run mode (no output written)")
+	)
+
+	if err := fs.Parse(argv); err != nil {
+		return err
+	}
+
+	webgen.DryRun = *dryRun
+
+	if err := webgen.GenerateSite(*inputDir, *outputDir); err != nil {
+		return err
+	}
+
+	return env.Emit(map[string]any{
+		"input":  *inputDir,
+		"output": *outputDir,
+		"dry":    *dryRun,
+	}, func(w io.Writer) {
+		fmt.Fprintf(w, "✅ Site generated in %s\n", *outputDir)
+	})
+}
@@ -0,0 +1,159 @@
+package enricher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mrizkifadil26/medix/enricher/core"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+// DefaultCheckpointInterval applies when Config.Checkpoint is empty.
+const DefaultCheckpointInterval = 30 * time.Second
+
+// checkpointInterval parses Config.Checkpoint; "off" or "0" disables
+// checkpoints.
+func (c *Config) checkpointInterval() (time.Duration, error) {
+	switch c.Checkpoint {
+	case "":
+		return DefaultCheckpointInterval, nil
+	case "off", "0":
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(c.Checkpoint)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("checkpoint: want a duration such as 30s or \"off\", got %q", c.Checkpoint)
+	}
+
+	return d, nil
+}
+
+// startCheckpoints calls save every interval until the returned stop
+// function is called. stop waits for a running save to finish.
+func startCheckpoints(interval time.Duration, save func()) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				save()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// checkpointEnrichers saves the state of every enricher that has some.
+func checkpointEnrichers(jobs []*job) {
+	for _, j := range jobs {
+		if c, ok := j.enricher.(core.Checkpointer); ok {
+			if err := c.Checkpoint(); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️ %s checkpoint: %v\n", j.name(), err)
+			}
+		}
+	}
+}
+
+// checkpointDocument writes data to path while enrichers may still be
+// running, holding every item lock for the time it takes to encode.
+func checkpointDocument(data any, locks *core.Locks, path string) error {
+	unlock := locks.Item(core.Root)
+	items, _ := jsonpath.Query(data, "items.#")
+	unlock()
+
+	unlock = locks.Document(len(items))
+	raw, err := json.Marshal(data)
+	unlock()
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJSONAtomic(path, json.RawMessage(raw))
+}
+
+// previousResults reads the "enriched" value of every item in the output
+// of an earlier run, keyed by item path. A missing file yields nothing.
+func previousResults(path string) (map[string]any, error) {
+	in, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var (
+		mu      sync.Mutex
+		results = map[string]any{}
+	)
+
+	err = utils.StreamItems(in, io.Discard, utils.StreamOptions{}, func(_ int, item any) (any, error) {
+		p, _ := jsonpath.Get(item, "path")
+		enriched, err := jsonpath.Get(item, "enriched")
+		if s, ok := p.(string); ok && err == nil && enriched != nil {
+			mu.Lock()
+			results[s] = enriched
+			mu.Unlock()
+		}
+
+		return item, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read previous output %s: %w", path, err)
+	}
+
+	return results, nil
+}
+
+// restoreItem copies a previous result into item unless it already has
+// one. It reports whether anything was restored.
+func restoreItem(item any, previous map[string]any) bool {
+	if len(previous) == 0 {
+		return false
+	}
+
+	if v, err := jsonpath.Get(item, "enriched"); err == nil && v != nil {
+		return false
+	}
+
+	p, _ := jsonpath.Get(item, "path")
+	s, ok := p.(string)
+	if !ok {
+		return false
+	}
+
+	enriched, ok := previous[s]
+	if !ok {
+		return false
+	}
+
+	return jsonpath.Set(item, "enriched", enriched) == nil
+}
@@ -0,0 +1,127 @@
+package enricher_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/enricher"
+	"github.com/mrizkifadil26/medix/enricher/core"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+// lookupEnricher stands in for a slow remote enricher: it writes
+// "enriched" and cancels the run after stopAfter items.
+type lookupEnricher struct {
+	mu        sync.Mutex
+	calls     int
+	stopAfter int
+	cancel    context.CancelFunc
+}
+
+func (l *lookupEnricher) Name() string { return "test-lookup" }
+
+func (l *lookupEnricher) Enrich(ctx context.Context, data any, opts core.Options) error {
+	items, _ := jsonpath.Query(data, "items.#")
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := l.EnrichItem(ctx, item, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *lookupEnricher) EnrichItem(ctx context.Context, item any, opts core.Options) error {
+	if v, _ := jsonpath.Get(item, "enriched"); opts.Resume && v != nil {
+		return nil
+	}
+
+	name, _ := jsonpath.Get(item, "name")
+	_ = jsonpath.Set(item, "enriched", map[string]any{"title": name})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls++
+	if l.stopAfter > 0 && l.calls == l.stopAfter {
+		l.cancel()
+	}
+
+	return nil
+}
+
+func (l *lookupEnricher) Finish() (map[string]any, error) { return nil, nil }
+
+func TestInterruptedRunResumes(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		name := "in-memory"
+		if stream {
+			name = "stream"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := filepath.Join(dir, "normalized.json")
+			output := filepath.Join(dir, "enriched.json")
+
+			doc := document(10)
+			doc.Set("item_count", 10)
+			if err := utils.WriteJSON(input, doc); err != nil {
+				t.Fatal(err)
+			}
+
+			lookup := &lookupEnricher{stopAfter: 4}
+			enricher.Register("test-lookup", func(enricher.EnricherConfig) (core.Enricher, error) {
+				return lookup, nil
+			})
+
+			run := func(ctx context.Context, resume bool) error {
+				config := parseConfig(t, `[{"name": "test-lookup", "concurrency": 1}]`)
+				config.Options.Concurrency = 1
+				config.Root, config.Output, config.Resume = input, output, resume
+
+				if stream {
+					return enricher.EnrichFile(ctx, input, output, config)
+				}
+
+				data := utils.NewOrderedMap[string, any]()
+				if err := utils.LoadJSON(input, data); err != nil {
+					t.Fatal(err)
+				}
+				_, err := enricher.Enrich(ctx, data, config)
+				return err
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			lookup.cancel = cancel
+
+			err := run(ctx, false)
+			if err == nil || !strings.Contains(err.Error(), "interrupted") {
+				t.Fatalf("expected an interrupted error, got %v", err)
+			}
+
+			partial := utils.NewOrderedMap[string, any]()
+			if err := utils.LoadJSON(output, partial); err != nil {
+				t.Fatalf("no partial output: %v", err)
+			}
+			enriched, _ := jsonpath.Query(partial, "items.#.enriched")
+			if len(enriched) != 4 {
+				t.Errorf("partial output has %d enriched items, want 4", len(enriched))
+			}
+
+			lookup.calls, lookup.stopAfter = 0, 0
+			if err := run(context.Background(), true); err != nil {
+				t.Fatal(err)
+			}
+			if lookup.calls != 6 {
+				t.Errorf("resume looked up %d items, want the 6 missing ones", lookup.calls)
+			}
+		})
+	}
+}
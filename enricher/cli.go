@@ -2,28 +2,32 @@ package enricher
 
 import (
 	"flag"
-	"fmt"
 )
 
 type CLIArgs struct {
+	Args       []string // positional arguments after the flags
 	ConfigPath *string
 	Refresh    *bool
 	Config     Config
 }
 
-func ParseCLI() (*CLIArgs, error) {
+// ParseCLI parses the enrich subcommand flags. ConfigPath is nil when -config
+// is not given.
+func ParseCLI(argv []string) (*CLIArgs, error) {
+	fs := flag.NewFlagSet("enrich", flag.ContinueOnError)
+
 	var (
-		configPath = flag.String("config", "", "Path to config file (JSON or YAML)")
-		outputPath = flag.String("output", "", "Output result path")
-		root       = flag.String("root", "", "Root directory to scan")
-		refresh    = flag.Bool("refresh", false, "Force enrichment by ignoring cache")
+		configPath = fs.String("config", "", "Path to config file (JSON or YAML)")
+		outputPath = fs.String("output", "", "Output result path")
+		root       = fs.String("root", "", "Root directory to scan")
+		refresh    = fs.Bool("refresh", false, "Force enrichment by ignoring cache")
+		stream     = fs.Bool("stream", false, "Process items one at a time instead of loading the whole file")
+		validate   = fs.Bool("validate", false, "Fail when the output does not match the enriched schema")
+		resume     = fs.Bool("resume", false, "Keep results already in the output and only enrich the rest")
 	)
 
-	flag.Parse()
-
-	// Ensure config file is provided
-	if configPath == nil || *configPath == "" {
-		return nil, fmt.Errorf("missing required -config argument")
+	if err := fs.Parse(argv); err != nil {
+		return nil, err
 	}
 
 	// Start with config file if provided
@@ -35,14 +39,33 @@ func ParseCLI() (*CLIArgs, error) {
 		shouldPopulate = true
 	}
 
+	if stream != nil && *stream {
+		cfg.Stream = *stream
+		shouldPopulate = true
+	}
+
+	if resume != nil && *resume {
+		cfg.Resume = *resume
+		shouldPopulate = true
+	}
+
+	if validate != nil && *validate {
+		cfg.Validate = *validate
+		shouldPopulate = true
+	}
+
 	if outputPath != nil && *outputPath != "" {
 		cfg.Output = *outputPath
 		shouldPopulate = true
 	}
 
 	args := &CLIArgs{
-		ConfigPath: configPath,
-		Refresh:    refresh,
+		Refresh: refresh,
+	}
+
+	args.Args = fs.Args()
+	if *configPath != "" {
+		args.ConfigPath = configPath
 	}
 
 	if shouldPopulate {
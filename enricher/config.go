@@ -1,18 +1,83 @@
 package enricher
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
 type EnricherConfig struct {
-	Name    string      `json:"name"`
-	Config  interface{} `json:"config,omitempty"` // per-enricher config
-	Filters []string    `json:"filters,omitempty"`
+	Name   string          `json:"name"`
+	Config json.RawMessage `json:"config,omitempty"` // per-enricher config, decoded by its factory
+
+	// Filters selects which of the enricher's filters run, e.g. ["media",
+	// "subtitle"] for local. Same as config.filters; enrichers without
+	// filters reject it.
+	Filters []string `json:"filters,omitempty"`
+
+	Concurrency int      `json:"concurrency,omitempty"` // items at once, overrides options.concurrency
+	DependsOn   []string `json:"depends_on,omitempty"`  // enrichers that must finish first
+}
+
+// UnmarshalJSON rejects unknown keys in an enrichers entry.
+func (c *EnricherConfig) UnmarshalJSON(data []byte) error {
+	type plain EnricherConfig
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode((*plain)(c)); err != nil {
+		return fmt.Errorf("enricher entry: %w", err)
+	}
+
+	return nil
+}
+
+// UnmarshalYAML keeps Config as JSON so factories decode YAML and JSON
+// configs the same way.
+func (c *EnricherConfig) UnmarshalYAML(node *yaml.Node) error {
+	var raw struct {
+		Name        string         `yaml:"name"`
+		Config      map[string]any `yaml:"config"`
+		Filters     []string       `yaml:"filters"`
+		Concurrency int            `yaml:"concurrency"`
+		DependsOn   []string       `yaml:"depends_on"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+
+	c.Name, c.Filters = raw.Name, raw.Filters
+	c.Concurrency, c.DependsOn = raw.Concurrency, raw.DependsOn
+	if raw.Config != nil {
+		data, err := json.Marshal(raw.Config)
+		if err != nil {
+			return err
+		}
+		c.Config = data
+	}
+
+	return nil
 }
 
 type Options struct {
-	Concurrency int              `json:"concurrency"`
+	Concurrency int              `json:"concurrency"` // default for enrichers without their own
 	Enrichers   []EnricherConfig `json:"enrichers"`
 }
 
 type Config struct {
-	Root    string  `json:"root"`   // Path to raw media entries (scanned)
-	Output  string  `json:"output"` // Path to write enriched result
-	Options Options `json:"options"`
+	Root     string  `json:"root"`   // Path to raw media entries (scanned)
+	Output   string  `json:"output"` // Path to write enriched result
+	Options  Options `json:"options"`
+	Stream   bool    `json:"stream,omitempty"`   // Process items one at a time
+	Validate bool    `json:"validate,omitempty"` // Check output against the enriched schema
+
+	// Checkpoint is how often partial output and enricher caches are saved
+	// during a run, e.g. "30s" (the default) or "off".
+	Checkpoint string `json:"checkpoint,omitempty"`
+
+	// Resume restores results from an existing Output and only looks up
+	// the items still missing, e.g. after an interrupted run.
+	Resume bool `json:"resume,omitempty"`
 }
@@ -0,0 +1,51 @@
+package core
+
+import "sync"
+
+// Root is the lock index for the top level of the document.
+const Root = -1
+
+// Locks hands out one mutex per item index.
+type Locks struct {
+	mu    sync.Mutex
+	items map[int]*sync.Mutex
+}
+
+func NewLocks() *Locks {
+	return &Locks{items: make(map[int]*sync.Mutex)}
+}
+
+// Item locks item index (or Root) and returns the unlock function. On a
+// nil *Locks it does nothing.
+func (l *Locks) Item(index int) (unlock func()) {
+	if l == nil {
+		return func() {}
+	}
+
+	l.mu.Lock()
+	m, ok := l.items[index]
+	if !ok {
+		m = &sync.Mutex{}
+		l.items[index] = m
+	}
+	l.mu.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+// Document locks the root and items 0..n-1, for reading the whole document
+// while enrichers are running. Enrichers hold one lock at a time, so taking
+// them in order cannot deadlock.
+func (l *Locks) Document(n int) (unlock func()) {
+	unlocks := []func(){l.Item(Root)}
+	for i := 0; i < n; i++ {
+		unlocks = append(unlocks, l.Item(i))
+	}
+
+	return func() {
+		for _, u := range unlocks {
+			u()
+		}
+	}
+}
@@ -1,7 +1,59 @@
 package core
 
-// The shared interface
+import "context"
+
+// The shared interface. Enrich modifies data in place; enrichers that do
+// not depend on each other may run on the same document at the same time,
+// so every read or write of an item must hold opts.Locks.Item for it.
 type Enricher interface {
 	Name() string
-	Enrich(data any, options map[string]string) (any, error)
+	Enrich(ctx context.Context, data any, opts Options) error
+}
+
+// ItemEnricher is an Enricher that can also work on one item at a time,
+// which streaming mode requires. EnrichItem may be called concurrently.
+type ItemEnricher interface {
+	Enricher
+	EnrichItem(ctx context.Context, item any, opts Options) error
+
+	// Finish runs once after the last item. The returned keys are added to
+	// the top level of the output, e.g. collected errors.
+	Finish() (map[string]any, error)
+}
+
+// Dependent is implemented by enrichers that read another enricher's
+// output. Names that are not configured in the run are ignored.
+type Dependent interface {
+	DependsOn() []string
+}
+
+// Checkpointer is implemented by enrichers with state worth saving during
+// a long run, e.g. a lookup cache. Checkpoint may run concurrently with
+// Enrich.
+type Checkpointer interface {
+	Checkpoint() error
+}
+
+// Options are the per-enricher run settings.
+type Options struct {
+	// Concurrency is how many items the enricher works on at once; 0 lets
+	// the enricher pick its default.
+	Concurrency int
+
+	// Locks is shared by all enrichers of a run. It is nil when items are
+	// not shared, e.g. in streaming mode, where Item is a no-op.
+	Locks *Locks
+
+	// Resume asks the enricher to leave items that already carry its
+	// output alone, e.g. those restored from an interrupted run.
+	Resume bool
+}
+
+// ConcurrencyOr returns Concurrency, or fallback when it is not set.
+func (o Options) ConcurrencyOr(fallback int) int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+
+	return fallback
 }
@@ -1,14 +1,24 @@
 package enricher
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/mrizkifadil26/medix/enricher/core"
-	"github.com/mrizkifadil26/medix/enricher/local"
-	"github.com/mrizkifadil26/medix/enricher/tmdb"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
 )
 
+// Enrich runs the configured enrichers on data in place. Enrichers that do
+// not depend on each other run concurrently, and each works on its own
+// concurrency (the entry's, else options.concurrency) items at once.
+//
+// When config.Output is set the partial document is written there every
+// config.Checkpoint, and once more if the run fails or is cancelled, so an
+// interrupted run can continue with config.Resume. On such an error the
+// partial data is returned along with it.
 func Enrich(
+	ctx context.Context,
 	data any,
 	config *Config,
 ) (any, error) {
@@ -16,71 +26,76 @@ func Enrich(
 		return nil, fmt.Errorf("data is nil")
 	}
 
-	// Build enrichers from registry
-	var enrichers []core.Enricher
-	for _, eCfg := range config.Options.Enrichers {
-		enricher, err := buildEnricher(eCfg)
-		if err != nil {
+	interval, err := config.checkpointInterval()
+	if err != nil {
+		return nil, err
+	}
+
+	locks := core.NewLocks()
+	jobs, err := buildJobs(config, locks)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Resume && config.Output != "" {
+		if err := restore(data, config.Output); err != nil {
 			return nil, err
 		}
-
-		enrichers = append(enrichers, enricher)
 	}
 
-	params := map[string]string{
-		"concurrency": fmt.Sprint(config.Options.Concurrency),
+	for _, j := range jobs {
+		j.opts.Resume = config.Resume
 	}
 
-	// Run each enricher sequentially
-	var err error
-	for _, enricher := range enrichers {
-		data, err = enricher.Enrich(data, params)
-		if err != nil {
-			return nil, fmt.Errorf("%s enricher failed: %w", enricher.Name(), err)
+	save := func() {
+		checkpointEnrichers(jobs)
+		if config.Output != "" {
+			if err := checkpointDocument(data, locks, config.Output); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️ checkpoint %s: %v\n", config.Output, err)
+			}
 		}
 	}
 
-	return data, nil
-}
+	stop := startCheckpoints(interval, save)
+	err = runJobs(ctx, jobs, func(ctx context.Context, j *job) error {
+		return j.enricher.Enrich(ctx, data, j.opts)
+	})
+	stop()
 
-func buildEnricher(eCfg EnricherConfig) (core.Enricher, error) {
-	switch eCfg.Name {
-	case "tmdb":
-		cfgMap, ok := eCfg.Config.(map[string]interface{})
-		if !ok {
-			return nil, fmt.Errorf("invalid tmdb config")
+	if err != nil {
+		if config.Output == "" {
+			return nil, err
 		}
 
-		cfg := &tmdb.Config{}
-		if apiKey, ok := cfgMap["api_key"].(string); ok {
-			cfg.APIKey = apiKey
+		save()
+		if ctx.Err() != nil {
+			return data, fmt.Errorf("interrupted, partial output saved to %s (rerun with --resume): %w", config.Output, err)
 		}
+		return data, fmt.Errorf("%w (partial output saved to %s)", err, config.Output)
+	}
 
-		// Optional fetch flags
-		if fetchCredits, ok := cfgMap["fetch_credits"].(bool); ok {
-			cfg.FetchCredits = fetchCredits
-		}
+	return data, nil
+}
 
-		return tmdb.NewTMDbEnricher(cfg), nil
+// restore copies results of an earlier run at path into data.
+func restore(data any, path string) error {
+	previous, err := previousResults(path)
+	if err != nil {
+		return err
+	}
 
-	case "local":
-		cfgMap, ok := eCfg.Config.(map[string]interface{})
-		if !ok {
-			// no config provided, just use default (all filters)
-			return local.NewLocalEnricher(&local.Config{}), nil
-		}
+	items, err := jsonpath.Query(data, "items.#")
+	if err != nil {
+		return fmt.Errorf("failed to get items: %w", err)
+	}
 
-		var cfg local.Config
-		if f, exists := cfgMap["filters"]; exists {
-			for _, v := range f.([]any) {
-				if s, ok := v.(string); ok {
-					cfg.Filters = append(cfg.Filters, s)
-				}
-			}
+	restored := 0
+	for _, item := range items {
+		if restoreItem(item, previous) {
+			restored++
 		}
-		return local.NewLocalEnricher(&cfg), nil
-
-	default:
-		return nil, fmt.Errorf("unknown enricher: %s", eCfg.Name)
 	}
+
+	fmt.Printf("⏩ Restored %d results from %s\n", restored, path)
+	return nil
 }
@@ -0,0 +1,262 @@
+package local
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// detectLimit is how much of a subtitle file language detection reads.
+const detectLimit = 64 << 10
+
+// readSubtitleText reads up to limit bytes of a text subtitle as a string,
+// decoding UTF-16 and Latin-1 files; limit <= 0 reads it all.
+func readSubtitleText(path string, limit int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit > 0 {
+		r = io.LimitReader(f, limit)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	return decodeText(data), nil
+}
+
+// decodeText honours a UTF-8 or UTF-16 byte order mark and falls back to
+// Latin-1 for bytes that are not UTF-8.
+func decodeText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUTF16(data[2:], false)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUTF16(data[2:], true)
+	}
+
+	if utf8.Valid(data) {
+		return string(data)
+	}
+
+	// a cut in the middle of a rune at the read limit is still UTF-8
+	if trimmed := trimPartialRune(data); utf8.Valid(trimmed) {
+		return string(trimmed)
+	}
+
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+var (
+	markupTag   = regexp.MustCompile(`<[^>]*>|\{[^}]*\}`)
+	assDialogue = regexp.MustCompile(`^Dialogue:(?:[^,]*,){9}(.*)$`)
+)
+
+// dialogue keeps only the spoken text of SRT, VTT, ASS/SSA or MicroDVD
+// subtitles: no numbers, timings, headers or styling.
+func dialogue(text string) string {
+	var out strings.Builder
+
+	// in ASS/SSA everything but the Dialogue lines is styling
+	ass := strings.Contains(text, "[Script Info]") || strings.Contains(text, "[Events]")
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+
+		if ass {
+			m := assDialogue.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			line = strings.ReplaceAll(m[1], `\N`, " ")
+		} else if line == "" || strings.Contains(line, "-->") || isDigits(line) ||
+			strings.HasPrefix(line, "WEBVTT") || strings.HasPrefix(line, "NOTE") ||
+			strings.HasPrefix(line, "Kind:") || strings.HasPrefix(line, "Language:") {
+			// cue numbers, timings and VTT headers
+			continue
+		}
+
+		line = markupTag.ReplaceAllString(line, " ")
+		line = strings.ReplaceAll(line, "|", " ")
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+
+	return out.String()
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// scripts identify a language by its writing system alone.
+var scripts = []struct {
+	code  string
+	table *unicode.RangeTable
+}{
+	{"ja", unicode.Hiragana},
+	{"ja", unicode.Katakana},
+	{"ko", unicode.Hangul},
+	{"th", unicode.Thai},
+	{"ar", unicode.Arabic},
+	{"he", unicode.Hebrew},
+	{"el", unicode.Greek},
+	{"ru", unicode.Cyrillic},
+	{"hi", unicode.Devanagari},
+	{"zh", unicode.Han}, // last: Japanese uses Han too
+}
+
+// stopwords are frequent words that tell Latin-script languages apart.
+var stopwords = map[string][]string{
+	"en": {"the", "you", "and", "what", "is", "it's", "this", "that", "don't", "have", "are", "your", "with", "for", "was", "know", "just", "we", "i'm", "he"},
+	"id": {"yang", "tidak", "aku", "kau", "kamu", "ini", "itu", "apa", "dan", "saya", "ada", "akan", "bisa", "sudah", "dengan", "untuk", "tak", "kita", "mereka", "jangan"},
+	"es": {"que", "el", "la", "es", "y", "en", "lo", "un", "por", "qué", "los", "se", "con", "para", "una", "está", "pero", "sí", "muy", "bien"},
+	"fr": {"le", "je", "vous", "est", "pas", "la", "que", "et", "un", "tu", "il", "les", "ce", "une", "ne", "ça", "à", "mais", "qui", "suis"},
+	"de": {"ich", "du", "die", "der", "und", "nicht", "das", "ist", "sie", "es", "ein", "zu", "wir", "was", "mit", "den", "ja", "mir", "auf", "hast"},
+	"pt": {"que", "não", "o", "e", "a", "é", "você", "um", "eu", "se", "para", "uma", "está", "com", "do", "isso", "os", "mas", "da", "está"},
+	"it": {"che", "non", "di", "è", "e", "il", "la", "un", "per", "mi", "sono", "ti", "ho", "lo", "cosa", "ma", "questo", "una", "sei", "bene"},
+	"nl": {"de", "het", "een", "ik", "je", "niet", "is", "dat", "en", "van", "wat", "zijn", "we", "hij", "maar", "op", "te", "met", "voor", "heb"},
+}
+
+var stopwordIndex = func() map[string][]string {
+	idx := make(map[string][]string)
+	for lang, words := range stopwords {
+		seen := map[string]bool{}
+		for _, w := range words {
+			if !seen[w] {
+				seen[w] = true
+				idx[w] = append(idx[w], lang)
+			}
+		}
+	}
+	return idx
+}()
+
+// detectLanguage guesses the language of subtitle dialogue: by script for
+// non-Latin text, by stopword frequency otherwise. It returns nil when the
+// text is too short or too mixed to tell.
+func detectLanguage(text string) *Language {
+	counts := map[string]int{}
+	letters, latin := 0, 0
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			continue
+		}
+		for _, s := range scripts {
+			if unicode.Is(s.table, r) {
+				counts[s.code]++
+				break
+			}
+		}
+	}
+
+	if letters < 50 {
+		return nil
+	}
+
+	if latin*2 < letters {
+		best := ""
+		for _, s := range scripts {
+			if best == "" || counts[s.code] > counts[best] {
+				best = s.code
+			}
+		}
+		// kana is what tells Japanese from Chinese
+		if counts["ja"] > 0 && counts["ja"]*10 >= counts["zh"] {
+			best = "ja"
+		}
+		if counts[best] == 0 {
+			return nil // a script we have no entry for
+		}
+
+		l, _ := LookupLanguage(best)
+		return l
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	if len(words) < 20 {
+		return nil
+	}
+
+	hits := map[string]int{}
+	for _, w := range words {
+		for _, lang := range stopwordIndex[w] {
+			hits[lang]++
+		}
+	}
+
+	best, second := "", 0
+	for lang, n := range hits {
+		if best == "" || n > hits[best] || n == hits[best] && lang < best {
+			if best != "" {
+				second = max(second, hits[best])
+			}
+			best = lang
+		} else {
+			second = max(second, n)
+		}
+	}
+
+	// at least one word in six a stopword, well ahead of the runner-up
+	if best == "" || hits[best]*6 < len(words) || hits[best]*10 < second*13 {
+		return nil
+	}
+
+	l, _ := LookupLanguage(best)
+	return l
+}
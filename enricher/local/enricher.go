@@ -1,10 +1,14 @@
 package local
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
+	"github.com/mrizkifadil26/medix/enricher/core"
+	"github.com/mrizkifadil26/medix/utils/concurrency"
 	"github.com/mrizkifadil26/medix/utils/jsonpath"
 )
 
@@ -14,9 +18,11 @@ type LocalEnricher struct {
 
 var allFilters = []Filter{
 	MediaFilter{},
+	ProbeFilter{}, // after media, whose file it reads
 	SubtitlesFilter{},
 	IconFilter{},
 	CollectionFilter{},
+	NFOFilter{},
 }
 
 func NewLocalEnricher(cfg *Config) *LocalEnricher {
@@ -27,13 +33,96 @@ func (s *LocalEnricher) Name() string {
 	return "local"
 }
 
+// Enrich runs the enabled filters on every item, opts.Concurrency items at
+// a time (default 4). The work is filesystem lookups only.
 func (e *LocalEnricher) Enrich(
+	ctx context.Context,
 	data any,
-	options map[string]string,
-) (any, error) {
+	opts core.Options,
+) error {
+	unlock := opts.Locks.Item(core.Root)
+	items, err := jsonpath.Query(data, "items.#")
+	unlock()
+	if err != nil {
+		return fmt.Errorf("failed to get items: %w", err)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		errsMu sync.Mutex
+		errs   []error
+		exec   = concurrency.GoroutineExecutor(opts.ConcurrencyOr(4))
+	)
+
+	allowed := e.allowedFilters()
+	for i, item := range items {
+		wg.Add(1)
+
+		err := exec(ctx, func(ctx context.Context) error {
+			defer wg.Done()
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			var itemErrs []error
+			unlock := opts.Locks.Item(i)
+			e.applyFilters(item, allowed, &itemErrs)
+			unlock()
+
+			if len(itemErrs) > 0 {
+				errsMu.Lock()
+				errs = append(errs, itemErrs...)
+				errsMu.Unlock()
+			}
+			return nil
+		})
+		if err != nil {
+			// cancelled before the item could start
+			wg.Done()
+			break
+		}
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+// EnrichItem runs the enabled filters on a single item.
+func (e *LocalEnricher) EnrichItem(
+	ctx context.Context,
+	item any,
+	opts core.Options,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	var errs []error
+	e.applyFilters(item, e.allowedFilters(), &errs)
 
-	// Build allowed map
+	return errors.Join(errs...)
+}
+
+// Finish has nothing to add; filter errors are reported per item.
+func (e *LocalEnricher) Finish() (map[string]any, error) {
+	return nil, nil
+}
+
+func (e *LocalEnricher) applyFilters(item any, allowed map[string]bool, errs *[]error) {
+	for _, f := range allFilters {
+		if allowed[f.Name()] {
+			f.Apply(item, errs)
+		}
+	}
+}
+
+// allowedFilters enables every filter unless the config lists some.
+func (e *LocalEnricher) allowedFilters() map[string]bool {
 	allowed := map[string]bool{}
 	if len(e.config.Filters) == 0 {
 		for _, f := range allFilters {
@@ -48,28 +137,5 @@ func (e *LocalEnricher) Enrich(
 		}
 	}
 
-	// Get items using your Get helper
-	nodes, err := jsonpath.Get(data, "items.#")
-	if err != nil {
-		return data, nil
-	}
-
-	items, ok := nodes.([]any)
-	if !ok {
-		return data, fmt.Errorf("items is not an array, got %T", nodes)
-	}
-
-	for _, item := range items {
-		for _, f := range allFilters {
-			if allowed[f.Name()] {
-				f.Apply(item, &errs)
-			}
-		}
-	}
-
-	if len(errs) > 0 {
-		return data, errors.Join(errs...)
-	}
-
-	return data, nil
+	return allowed
 }
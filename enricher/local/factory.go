@@ -0,0 +1,58 @@
+package local
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/enricher"
+	"github.com/mrizkifadil26/medix/enricher/core"
+)
+
+func init() {
+	enricher.Register("local", newFromConfig)
+}
+
+// newFromConfig accepts filters either in config.filters or on the entry
+// itself. Without any, every filter runs.
+func newFromConfig(entry enricher.EnricherConfig) (core.Enricher, error) {
+	var cfg Config
+	if err := entry.Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	if len(entry.Filters) > 0 {
+		if len(cfg.Filters) > 0 {
+			return nil, fmt.Errorf("filters set both on the entry and in config")
+		}
+		cfg.Filters = entry.Filters
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return NewLocalEnricher(&cfg), nil
+}
+
+func (c *Config) validate() error {
+	known := make([]string, 0, len(allFilters))
+	for _, f := range allFilters {
+		known = append(known, f.Name())
+	}
+
+	for _, name := range c.Filters {
+		found := false
+		for _, k := range known {
+			if strings.TrimSpace(name) == k {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("unknown filter %q (want one of %s)", name, strings.Join(known, ", "))
+		}
+	}
+
+	return nil
+}
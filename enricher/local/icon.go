@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mrizkifadil26/medix/utils/ico"
 	"github.com/mrizkifadil26/medix/utils/jsonpath"
 )
 
@@ -70,6 +71,14 @@ func (f IconFilter) Apply(
 
 	// Only set if we actually found one
 	if icon != nil {
+		if icon.Path != "" {
+			info, err := ico.File(icon.Path)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("read icon %s: %w", icon.Path, err))
+			}
+			icon.ICO = info
+		}
+
 		_ = jsonpath.Set(item, "icon", icon)
 	}
 }
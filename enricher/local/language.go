@@ -0,0 +1,91 @@
+package local
+
+import "strings"
+
+// Language is one entry of the table below. Code2 is the ISO 639-2/B code
+// that Matroska and MP4 use; Code1 is empty where ISO 639-1 has none.
+type Language struct {
+	Code1   string
+	Code2   string
+	Name    string
+	aliases []string // ISO 639-2/T or 639-3 codes and other names
+}
+
+var languages = []Language{
+	{"en", "eng", "English", nil},
+	{"id", "ind", "Indonesian", []string{"indonesia", "bahasa", "indo"}},
+	{"ms", "may", "Malay", []string{"msa", "zsm", "melayu", "malaysian"}},
+	{"jv", "jav", "Javanese", nil},
+	{"su", "sun", "Sundanese", nil},
+	{"tl", "tgl", "Tagalog", []string{"fil", "filipino"}},
+	{"th", "tha", "Thai", nil},
+	{"vi", "vie", "Vietnamese", nil},
+	{"zh", "chi", "Chinese", []string{"zho", "cmn", "mandarin", "chs", "cht"}},
+	{"", "yue", "Cantonese", nil},
+	{"ja", "jpn", "Japanese", nil},
+	{"ko", "kor", "Korean", nil},
+	{"hi", "hin", "Hindi", nil},
+	{"bn", "ben", "Bengali", nil},
+	{"ta", "tam", "Tamil", nil},
+	{"te", "tel", "Telugu", nil},
+	{"ur", "urd", "Urdu", nil},
+	{"ar", "ara", "Arabic", nil},
+	{"fa", "per", "Persian", []string{"fas", "farsi"}},
+	{"he", "heb", "Hebrew", []string{"iw"}},
+	{"tr", "tur", "Turkish", nil},
+	{"ru", "rus", "Russian", nil},
+	{"uk", "ukr", "Ukrainian", nil},
+	{"pl", "pol", "Polish", nil},
+	{"cs", "cze", "Czech", []string{"ces"}},
+	{"sk", "slo", "Slovak", []string{"slk"}},
+	{"hu", "hun", "Hungarian", nil},
+	{"ro", "rum", "Romanian", []string{"ron"}},
+	{"bg", "bul", "Bulgarian", nil},
+	{"hr", "hrv", "Croatian", nil},
+	{"sr", "srp", "Serbian", nil},
+	{"sl", "slv", "Slovenian", nil},
+	{"el", "gre", "Greek", []string{"ell"}},
+	{"de", "ger", "German", []string{"deu", "deutsch"}},
+	{"nl", "dut", "Dutch", []string{"nld", "nederlands"}},
+	{"fr", "fre", "French", []string{"fra", "francais", "français"}},
+	{"es", "spa", "Spanish", []string{"espanol", "español", "castellano"}},
+	{"pt", "por", "Portuguese", []string{"portugues", "português", "pob", "brazilian"}},
+	{"it", "ita", "Italian", []string{"italiano"}},
+	{"sv", "swe", "Swedish", nil},
+	{"no", "nor", "Norwegian", []string{"nob", "nno"}},
+	{"da", "dan", "Danish", nil},
+	{"fi", "fin", "Finnish", nil},
+	{"is", "ice", "Icelandic", []string{"isl"}},
+	{"et", "est", "Estonian", nil},
+	{"lv", "lav", "Latvian", nil},
+	{"lt", "lit", "Lithuanian", nil},
+}
+
+var languageIndex = func() map[string]*Language {
+	idx := make(map[string]*Language)
+	for i := range languages {
+		l := &languages[i]
+		for _, key := range append([]string{l.Code1, l.Code2, l.Name}, l.aliases...) {
+			if key != "" {
+				idx[strings.ToLower(key)] = l
+			}
+		}
+	}
+	return idx
+}()
+
+// LookupLanguage accepts an ISO 639-1, 639-2 or 639-3 code or an English
+// (or native) language name, in any case.
+func LookupLanguage(s string) (*Language, bool) {
+	l, ok := languageIndex[strings.ToLower(strings.TrimSpace(s))]
+	return l, ok
+}
+
+// Key is the short form used as the subtitles key: the ISO 639-1 code,
+// or the 639-2 code where there is none.
+func (l *Language) Key() string {
+	if l.Code1 != "" {
+		return l.Code1
+	}
+	return l.Code2
+}
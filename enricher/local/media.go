@@ -3,9 +3,11 @@ package local
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/mrizkifadil26/medix/utils/jsonpath"
+	"github.com/mrizkifadil26/medix/utils/probe"
 )
 
 type MediaFilter struct{}
@@ -35,8 +37,7 @@ func (f MediaFilter) Apply(item any, errs *[]error) {
 		sources = []any{item}
 	}
 
-	var mainMedia *MediaSource
-	var extras []*MediaSource
+	var videos []*MediaSource
 
 	for _, node := range sources {
 		nameVal, _ := jsonpath.Get(node, "name")
@@ -59,31 +60,33 @@ func (f MediaFilter) Apply(item any, errs *[]error) {
 			continue
 		}
 
-		ms := &MediaSource{
+		if !videoExts[strings.ToLower(ext)] {
+			continue
+		}
+
+		videos = append(videos, &MediaSource{
 			Name:      name,
 			Extension: ext,
 			Path:      path,
 			Size:      int64(size),
-		}
+			Type:      extraType(name, path),
+		})
+	}
 
-		if !strings.EqualFold(ext, ".mkv") {
-			continue
-		}
+	mainMedia := pickMain(videos)
 
-		// Decide main vs extras
-		if mainMedia == nil {
-			mainMedia = ms
-		} else {
-			lname := strings.ToUpper(name)
-			if strings.Contains(lname, "IMAX") || strings.Contains(lname, "OPEN MATTE") {
-				extras = append(extras, ms)
-			} else {
-				extras = append(extras, ms)
-			}
+	var extras []*MediaSource
+	for _, v := range videos {
+		if v != mainMedia {
+			extras = append(extras, v)
 		}
 	}
 
 	if mainMedia != nil {
+		// an alternate cut keeps its type; a bonus name was a false match
+		if isBonus(mainMedia.Type) {
+			mainMedia.Type = ""
+		}
 		_ = jsonpath.Set(item, "media", mainMedia)
 	}
 
@@ -91,3 +94,130 @@ func (f MediaFilter) Apply(item any, errs *[]error) {
 		_ = jsonpath.Set(item, "extras", extras)
 	}
 }
+
+var videoExts = map[string]bool{
+	".mkv": true, ".mp4": true, ".m4v": true, ".avi": true, ".mov": true,
+	".wmv": true, ".ts": true, ".m2ts": true, ".webm": true, ".mpg": true,
+	".mpeg": true, ".flv": true, ".divx": true, ".ogv": true,
+}
+
+// Extra types. Bonus material is never the main file; the alternate cuts
+// are only when no plain version is present.
+const (
+	ExtraSample       = "sample"
+	ExtraTrailer      = "trailer"
+	ExtraFeaturette   = "featurette"
+	ExtraIMAX         = "IMAX"
+	ExtraOpenMatte    = "Open Matte"
+	ExtraExtended     = "Extended"
+	ExtraDirectorsCut = "Director's Cut"
+)
+
+var extraPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{ExtraSample, regexp.MustCompile(`\bsample\b`)},
+	{ExtraTrailer, regexp.MustCompile(`\b(trailer|teaser)s?\b`)},
+	{ExtraFeaturette, regexp.MustCompile(`\b(featurettes?|behind the scenes|making of|deleted scenes?|interviews?|bonus)\b`)},
+	{ExtraIMAX, regexp.MustCompile(`\bimax\b`)},
+	{ExtraOpenMatte, regexp.MustCompile(`\bopen ?matte\b`)},
+	{ExtraExtended, regexp.MustCompile(`\bextended\b`)},
+	{ExtraDirectorsCut, regexp.MustCompile(`\bdirector'?s? cut\b`)},
+}
+
+// bonusDirs are the Plex/Jellyfin folder names for extras.
+var bonusDirs = map[string]string{
+	"samples":           ExtraSample,
+	"sample":            ExtraSample,
+	"trailers":          ExtraTrailer,
+	"featurettes":       ExtraFeaturette,
+	"extras":            ExtraFeaturette,
+	"behind the scenes": ExtraFeaturette,
+	"deleted scenes":    ExtraFeaturette,
+	"interviews":        ExtraFeaturette,
+}
+
+var separators = strings.NewReplacer(".", " ", "_", " ", "-", " ", "[", " ", "]", " ", "(", " ", ")", " ")
+
+// extraType classifies a video by its name, or by the folder it sits in;
+// "" is a plain copy of the movie.
+func extraType(name, path string) string {
+	if path != "" {
+		dir := strings.ToLower(filepath.Base(filepath.Dir(path)))
+		if kind, ok := bonusDirs[dir]; ok {
+			return kind
+		}
+	}
+
+	clean := separators.Replace(strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name))))
+	for _, p := range extraPatterns {
+		if p.pattern.MatchString(clean) {
+			return p.kind
+		}
+	}
+
+	return ""
+}
+
+func isBonus(kind string) bool {
+	return kind == ExtraSample || kind == ExtraTrailer || kind == ExtraFeaturette
+}
+
+// pickMain chooses the movie among the videos: bonus material only when
+// there is nothing else, a plain copy over an alternate cut, then the
+// longest and the largest.
+// Durations are read from the headers only when there is a choice.
+func pickMain(videos []*MediaSource) *MediaSource {
+	var candidates []*MediaSource
+	for _, v := range videos {
+		if !isBonus(v.Type) {
+			candidates = append(candidates, v)
+		}
+	}
+
+	// only "bonus" names, e.g. Trailer Park Boys: trust the sizes
+	if len(candidates) == 0 {
+		candidates = videos
+	}
+
+	var plain []*MediaSource
+	for _, c := range candidates {
+		if c.Type == "" {
+			plain = append(plain, c)
+		}
+	}
+	if len(plain) > 0 {
+		candidates = plain
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil
+	case 1:
+		return candidates[0]
+	}
+
+	durations := make(map[*MediaSource]float64, len(candidates))
+	for _, c := range candidates {
+		if info, err := probe.File(c.Path); err == nil {
+			durations[c] = info.Duration
+		}
+	}
+
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if longer(durations[c], durations[best]) ||
+			(!longer(durations[best], durations[c]) && c.Size > best.Size) {
+			best = c
+		}
+	}
+
+	return best
+}
+
+// longer reports whether a is clearly longer than b: both are known and
+// differ by more than a minute, so re-encodes of one cut count as equal.
+func longer(a, b float64) bool {
+	return a > 0 && b > 0 && a-b > 60
+}
@@ -0,0 +1,84 @@
+package local_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/enricher/local"
+)
+
+func video(dir, name string, size float64) map[string]any {
+	return map[string]any{
+		"name": name,
+		"ext":  filepath.Ext(name),
+		"path": filepath.Join(dir, name),
+		"size": size,
+		"type": "file",
+	}
+}
+
+func TestMediaFilter(t *testing.T) {
+	dir := "/movies/13 Hours (2016)"
+
+	item := map[string]any{
+		"type": "directory",
+		"path": dir,
+		"children": []any{
+			video(dir, "13.Hours.2016.sample.mkv", 50e6),
+			video(dir, "13.Hours.2016.IMAX.mkv", 1.45e9),
+			video(dir, "13.Hours.2016.mp4", 1.39e9),
+			video(dir, "13.Hours.2016.Open-Matte.avi", 1.2e9),
+			video(filepath.Join(dir, "Featurettes"), "Inside the Compound.mkv", 300e6),
+			video(dir, "13.Hours.2016.eng.srt", 80e3),
+		},
+	}
+
+	var errs []error
+	local.MediaFilter{}.Apply(item, &errs)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	media, ok := item["media"].(*local.MediaSource)
+	if !ok || media.Name != "13.Hours.2016.mp4" || media.Type != "" {
+		t.Fatalf("media = %+v, want the plain mp4", item["media"])
+	}
+
+	extras, _ := item["extras"].([]*local.MediaSource)
+	want := map[string]string{
+		"13.Hours.2016.sample.mkv":     local.ExtraSample,
+		"13.Hours.2016.IMAX.mkv":       local.ExtraIMAX,
+		"13.Hours.2016.Open-Matte.avi": local.ExtraOpenMatte,
+		"Inside the Compound.mkv":      local.ExtraFeaturette,
+	}
+	if len(extras) != len(want) {
+		t.Fatalf("got %d extras, want %d", len(extras), len(want))
+	}
+	for _, e := range extras {
+		if e.Type != want[e.Name] {
+			t.Errorf("%s: type %q, want %q", e.Name, e.Type, want[e.Name])
+		}
+	}
+}
+
+func TestMediaFilter_LargestCut(t *testing.T) {
+	dir := "/movies/Dune (2021)"
+
+	item := map[string]any{
+		"type": "directory",
+		"path": dir,
+		"children": []any{
+			video(dir, "Dune.2021.Extended.mkv", 2e9),
+			video(dir, "Dune.2021.IMAX.mkv", 3e9),
+			video(dir, "Dune.2021.trailer.mkv", 4e9),
+		},
+	}
+
+	var errs []error
+	local.MediaFilter{}.Apply(item, &errs)
+
+	media, _ := item["media"].(*local.MediaSource)
+	if media == nil || media.Name != "Dune.2021.IMAX.mkv" || media.Type != local.ExtraIMAX {
+		t.Fatalf("media = %+v, want the larger IMAX cut", media)
+	}
+}
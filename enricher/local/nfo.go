@@ -0,0 +1,313 @@
+package local
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+// NFOFilter reads the Kodi/Jellyfin metadata kept next to the media:
+// a movie.nfo, tvshow.nfo or <video>.nfo, and artwork such as poster.jpg
+// and fanart.jpg. The IDs it finds let the tmdb enricher skip searching.
+type NFOFilter struct{}
+
+func (f NFOFilter) Name() string { return "nfo" }
+
+func (f NFOFilter) Apply(item any, errs *[]error) {
+	var sources []any
+
+	// Determine if item is a directory
+	typeVal, _ := jsonpath.Get(item, "type")
+	if t, ok := typeVal.(string); ok && t == "directory" {
+		childrenNode, err := jsonpath.Get(item, "children")
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("directory has no children"))
+			return
+		}
+
+		if childrenArr, ok := childrenNode.([]any); ok {
+			sources = childrenArr
+		} else {
+			*errs = append(*errs, fmt.Errorf("children is not an array"))
+			return
+		}
+	} else {
+		// Single file: its sidecars are not in the scan, look beside it
+		sources = siblings(item)
+	}
+
+	var (
+		nfoFiles []MediaSource
+		videos   []string
+		artwork  = make(Artwork)
+	)
+
+	for _, node := range sources {
+		nameVal, _ := jsonpath.Get(node, "name")
+		extVal, _ := jsonpath.Get(node, "ext")
+		pathVal, _ := jsonpath.Get(node, "path")
+		sizeVal, _ := jsonpath.Get(node, "size")
+
+		name, _ := nameVal.(string)
+		ext, _ := extVal.(string)
+		path, _ := pathVal.(string)
+		size, _ := sizeVal.(float64)
+
+		// Fallback: extract extension from name if ext is empty
+		if ext == "" && name != "" {
+			ext = filepath.Ext(name)
+		}
+
+		if name == "" || ext == "" {
+			continue
+		}
+
+		file := MediaSource{
+			Name:      name,
+			Extension: ext,
+			Path:      path,
+			Size:      int64(size),
+		}
+
+		switch lower := strings.ToLower(ext); {
+		case lower == ".nfo":
+			nfoFiles = append(nfoFiles, file)
+		case videoExts[lower]:
+			videos = append(videos, strings.TrimSuffix(name, ext))
+		case lower == ".jpg" || lower == ".jpeg" || lower == ".png":
+			if kind := artworkKind(name); kind != "" {
+				if _, seen := artwork[kind]; !seen {
+					artwork[kind] = file
+				}
+			}
+		}
+	}
+
+	if src := pickNFO(nfoFiles, videos); src != nil {
+		nfo, err := ReadNFO(src.Path)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", src.Name, err))
+		} else {
+			_ = jsonpath.Set(item, "nfo", nfo)
+		}
+	}
+
+	if len(artwork) > 0 {
+		_ = jsonpath.Set(item, "artwork", artwork)
+	}
+}
+
+// pickNFO prefers movie.nfo and tvshow.nfo, then one named after a video,
+// then the only .nfo present.
+func pickNFO(files []MediaSource, videos []string) *MediaSource {
+	for _, want := range []string{"movie", "tvshow"} {
+		for i, f := range files {
+			if strings.EqualFold(strings.TrimSuffix(f.Name, f.Extension), want) {
+				return &files[i]
+			}
+		}
+	}
+
+	for i, f := range files {
+		base := strings.TrimSuffix(f.Name, f.Extension)
+		for _, v := range videos {
+			if strings.EqualFold(base, v) {
+				return &files[i]
+			}
+		}
+	}
+
+	if len(files) == 1 {
+		return &files[0]
+	}
+
+	return nil
+}
+
+// artworkKind maps an image name to its Kodi artwork type: poster.jpg,
+// <movie>-poster.jpg and folder.jpg are all posters.
+func artworkKind(name string) string {
+	base := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+	if i := strings.LastIndexAny(base, "-."); i >= 0 {
+		base = base[i+1:]
+	}
+
+	switch base {
+	case "poster", "folder", "cover":
+		return "poster"
+	case "fanart", "backdrop", "background":
+		return "fanart"
+	case "banner", "logo", "clearlogo", "clearart", "landscape", "thumb", "disc", "discart":
+		return base
+	}
+
+	return ""
+}
+
+// siblings lists the files in the directory of a single-file item.
+func siblings(item any) []any {
+	pathVal, _ := jsonpath.Get(item, "path")
+	path, _ := pathVal.(string)
+	if path == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	var out []any
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		// only this movie's own sidecars, e.g. Alien (1979)-poster.jpg
+		name := e.Name()
+		if !strings.HasPrefix(strings.ToLower(name), strings.ToLower(stem)) {
+			continue
+		}
+
+		var size int64
+		if info, err := e.Info(); err == nil {
+			size = info.Size()
+		}
+
+		out = append(out, map[string]any{
+			"name": name,
+			"ext":  filepath.Ext(name),
+			"path": filepath.Join(filepath.Dir(path), name),
+			"size": float64(size),
+		})
+	}
+
+	return out
+}
+
+// nfoDoc covers the fields of Kodi's <movie> and <tvshow> documents that
+// we use. Older files carry the IDs in <id>, <tmdbid> and <imdbid>.
+type nfoDoc struct {
+	XMLName       xml.Name
+	Title         string   `xml:"title"`
+	OriginalTitle string   `xml:"originaltitle"`
+	Year          string   `xml:"year"`
+	Premiered     string   `xml:"premiered"`
+	Plot          string   `xml:"plot"`
+	Genres        []string `xml:"genre"`
+	UniqueIDs     []struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"uniqueid"`
+	ID     string `xml:"id"`
+	TMDbID string `xml:"tmdbid"`
+	IMDbID string `xml:"imdbid"`
+}
+
+var (
+	imdbIDPattern = regexp.MustCompile(`\btt[0-9]{7,}\b`)
+	tmdbURL       = regexp.MustCompile(`themoviedb\.org/(?:movie|tv)/([0-9]+)`)
+	yearPattern   = regexp.MustCompile(`^[0-9]{4}`)
+)
+
+// ReadNFO parses a movie or tvshow NFO. A file that is not XML but holds
+// an IMDb or TMDb URL, which Kodi also accepts, yields just the IDs.
+func ReadNFO(path string) (*NFO, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	nfo := &NFO{Path: path}
+
+	var doc nfoDoc
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	// files declaring latin1 and the like are read as they are; the
+	// fields we keep are nearly always ASCII
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(&doc); err != nil {
+		if !urlNFO(data, nfo) {
+			return nil, fmt.Errorf("invalid nfo: %w", err)
+		}
+		return nfo, nil
+	}
+
+	switch kind := doc.XMLName.Local; kind {
+	case "movie", "tvshow":
+		nfo.Kind = kind
+	default:
+		return nil, fmt.Errorf("unsupported nfo root <%s>", kind)
+	}
+
+	nfo.Title = strings.TrimSpace(doc.Title)
+	nfo.OriginalTitle = strings.TrimSpace(doc.OriginalTitle)
+	nfo.Plot = strings.TrimSpace(doc.Plot)
+
+	nfo.Year = yearPattern.FindString(strings.TrimSpace(doc.Year))
+	if nfo.Year == "" {
+		nfo.Year = yearPattern.FindString(strings.TrimSpace(doc.Premiered))
+	}
+
+	for _, g := range doc.Genres {
+		// some scrapers write "Action / Drama" into one element
+		for _, part := range strings.Split(g, "/") {
+			if part = strings.TrimSpace(part); part != "" {
+				nfo.Genres = append(nfo.Genres, part)
+			}
+		}
+	}
+
+	for _, id := range doc.UniqueIDs {
+		nfo.setID(id.Type, id.Value)
+	}
+	nfo.setID("tmdb", doc.TMDbID)
+	nfo.setID("imdb", doc.IMDbID)
+	if strings.HasPrefix(strings.TrimSpace(doc.ID), "tt") {
+		nfo.setID("imdb", doc.ID)
+	}
+
+	return nfo, nil
+}
+
+// setID keeps the first value seen for each provider.
+func (n *NFO) setID(provider, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	switch strings.ToLower(provider) {
+	case "tmdb":
+		if id, err := strconv.Atoi(value); err == nil && id > 0 && n.TMDbID == 0 {
+			n.TMDbID = id
+		}
+	case "imdb":
+		if imdbIDPattern.MatchString(value) && n.IMDbID == "" {
+			n.IMDbID = imdbIDPattern.FindString(value)
+		}
+	}
+}
+
+func urlNFO(data []byte, nfo *NFO) bool {
+	if m := tmdbURL.FindSubmatch(data); m != nil {
+		nfo.setID("tmdb", string(m[1]))
+	}
+	if id := imdbIDPattern.Find(data); id != nil {
+		nfo.setID("imdb", string(id))
+	}
+
+	return nfo.TMDbID != 0 || nfo.IMDbID != ""
+}
@@ -0,0 +1,116 @@
+package local_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/enricher/local"
+)
+
+const movieNFO = `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<movie>
+  <title>Alien</title>
+  <originaltitle>Alien</originaltitle>
+  <year>1979</year>
+  <plot>The crew of a commercial spacecraft encounters a deadly lifeform.</plot>
+  <genre>Horror</genre>
+  <genre>Science Fiction</genre>
+  <uniqueid type="imdb">tt0078748</uniqueid>
+  <uniqueid type="tmdb" default="true">348</uniqueid>
+</movie>
+`
+
+func child(dir, name string) map[string]any {
+	return map[string]any{
+		"name": name,
+		"ext":  filepath.Ext(name),
+		"path": filepath.Join(dir, name),
+		"type": "file",
+	}
+}
+
+func TestNFOFilter(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"movie.nfo":               movieNFO,
+		"Alien (1979).mkv":        "",
+		"poster.jpg":              "",
+		"Alien (1979)-fanart.jpg": "",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	item := map[string]any{
+		"type": "directory",
+		"path": dir,
+		"children": []any{
+			child(dir, "Alien (1979).mkv"),
+			child(dir, "movie.nfo"),
+			child(dir, "poster.jpg"),
+			child(dir, "Alien (1979)-fanart.jpg"),
+		},
+	}
+
+	var errs []error
+	local.NFOFilter{}.Apply(item, &errs)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	nfo, ok := item["nfo"].(*local.NFO)
+	if !ok {
+		t.Fatalf("nfo not set: %#v", item["nfo"])
+	}
+
+	want := &local.NFO{
+		Kind:          "movie",
+		Title:         "Alien",
+		OriginalTitle: "Alien",
+		Year:          "1979",
+		TMDbID:        348,
+		IMDbID:        "tt0078748",
+		Genres:        []string{"Horror", "Science Fiction"},
+		Plot:          "The crew of a commercial spacecraft encounters a deadly lifeform.",
+		Path:          filepath.Join(dir, "movie.nfo"),
+	}
+	if !reflect.DeepEqual(nfo, want) {
+		t.Errorf("nfo = %+v, want %+v", nfo, want)
+	}
+
+	artwork, _ := item["artwork"].(local.Artwork)
+	if artwork["poster"].Name != "poster.jpg" || artwork["fanart"].Name != "Alien (1979)-fanart.jpg" {
+		t.Errorf("artwork = %+v", artwork)
+	}
+}
+
+func TestReadNFO_URLOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "movie.nfo")
+	body := "https://www.themoviedb.org/movie/348-alien\nhttps://www.imdb.com/title/tt0078748/\n"
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	nfo, err := local.ReadNFO(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nfo.TMDbID != 348 || nfo.IMDbID != "tt0078748" || nfo.Kind != "" {
+		t.Errorf("nfo = %+v", nfo)
+	}
+}
+
+func TestReadNFO_Episode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "S01E01.nfo")
+	if err := os.WriteFile(path, []byte("<episodedetails><title>Pilot</title></episodedetails>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := local.ReadNFO(path); err == nil {
+		t.Error("expected an error for an episode NFO")
+	}
+}
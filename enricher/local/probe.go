@@ -0,0 +1,50 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+	"github.com/mrizkifadil26/medix/utils/probe"
+)
+
+// ProbeFilter reads the container headers of the file the media filter
+// picked and stores codecs, resolution, duration and tracks under
+// media.probe. Items without media are left alone.
+type ProbeFilter struct{}
+
+func (f ProbeFilter) Name() string { return "probe" }
+
+func (f ProbeFilter) Apply(item any, errs *[]error) {
+	mediaVal, err := jsonpath.Get(item, "media")
+	if err != nil || mediaVal == nil {
+		return
+	}
+
+	// a struct when the media filter just ran, a map when read from a file
+	var path string
+	set := func(info *probe.Info) { _ = jsonpath.Set(item, "media.probe", info) }
+
+	if ms, ok := mediaVal.(*MediaSource); ok {
+		path = ms.Path
+		set = func(info *probe.Info) { ms.Probe = info }
+	} else {
+		pathVal, _ := jsonpath.Get(mediaVal, "path")
+		path, _ = pathVal.(string)
+	}
+
+	if path == "" {
+		return
+	}
+
+	info, err := probe.File(path)
+	if errors.Is(err, probe.ErrUnsupported) {
+		return
+	}
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("probe %s: %w", path, err))
+		return
+	}
+
+	set(info)
+}
@@ -1,8 +1,12 @@
 package local
 
 import (
+	"bufio"
 	"fmt"
+	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/mrizkifadil26/medix/utils/jsonpath"
@@ -14,6 +18,29 @@ func (f SubtitlesFilter) Name() string {
 	return "subtitle"
 }
 
+// subtitleFormats maps the extensions we pick up to their format. A .sub
+// next to an .idx of the same name is VobSub data, otherwise MicroDVD.
+var subtitleFormats = map[string]string{
+	".srt": "subrip",
+	".ass": "ass",
+	".ssa": "ssa",
+	".vtt": "webvtt",
+	".sub": "microdvd",
+	".idx": "vobsub",
+	".sup": "pgs",
+}
+
+// textFormats can be read for language detection.
+var textFormats = map[string]bool{
+	"subrip": true, "ass": true, "ssa": true, "webvtt": true, "microdvd": true,
+}
+
+// timedFormats get the cue checks in subtitle_checks.go.
+var timedFormats = map[string]bool{"subrip": true, "webvtt": true}
+
+// releaseTags are stripped from names before looking for a language.
+var releaseTags = []string{"Pahe.in"}
+
 func (f SubtitlesFilter) Apply(
 	item any,
 	errs *[]error,
@@ -40,21 +67,27 @@ func (f SubtitlesFilter) Apply(
 		sources = []any{item}
 	}
 
-	subtitles := make(Subtitles)
+	type candidate struct {
+		name, path, ext, format string
+	}
+
+	var (
+		candidates []candidate
+		idxStems   = map[string]bool{}
+	)
+
 	for _, child := range sources {
 		nameVal, _ := jsonpath.Get(child, "name")
 		extVal, _ := jsonpath.Get(child, "ext")
 		pathVal, _ := jsonpath.Get(child, "path")
-		// sizeVal, _ := jsonpath.Get(child, "size")
 
 		name, _ := nameVal.(string)
 		ext, _ := extVal.(string)
 		path, _ := pathVal.(string)
-		// size, _ := sizeVal.(float64)
 
 		// Fallback: extract extension from name if ext is empty
 		if ext == "" && name != "" {
-			ext = strings.ToLower(filepath.Ext(name))
+			ext = filepath.Ext(name)
 		}
 
 		if name == "" || ext == "" {
@@ -62,21 +95,76 @@ func (f SubtitlesFilter) Apply(
 			continue
 		}
 
-		// only accept .srt
-		if ext != ".srt" {
+		format, ok := subtitleFormats[strings.ToLower(ext)]
+		if !ok {
 			continue
 		}
 
-		// detect language
-		lang := detectLangWithFilter(name, []string{"Pahe.in"})
-		if lang == "" {
-			lang = "id" // default
+		if format == "vobsub" {
+			idxStems[strings.ToLower(strings.TrimSuffix(name, ext))] = true
 		}
+		candidates = append(candidates, candidate{name, path, ext, format})
+	}
+
+	duration := mediaDuration(item)
 
-		subtitles[lang] = Subtitle{
-			Name: name,
-			Path: path,
-			Ext:  ext,
+	subtitles := make(Subtitles)
+	for _, c := range candidates {
+		// the .sub half of a VobSub pair is described by its .idx
+		if c.format == "microdvd" && idxStems[strings.ToLower(strings.TrimSuffix(c.name, c.ext))] {
+			continue
+		}
+
+		base := Subtitle{Name: c.name, Path: c.path, Ext: c.ext, Format: c.format}
+
+		if timedFormats[c.format] {
+			checks, err := checkSubtitle(c.path, duration)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %w", c.name, err))
+			}
+			base.Checks = checks
+		}
+
+		lang, flags := parseSubtitleName(c.name)
+		base.Forced, base.SDH, base.CC = flags.forced, flags.sdh, flags.cc
+
+		var tracks []Subtitle
+		switch {
+		case lang != nil:
+			base.setLanguage(lang, "filename")
+			tracks = append(tracks, base)
+
+		case c.format == "vobsub":
+			// one .idx can hold several languages
+			langs, err := idxLanguages(c.path)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %w", c.name, err))
+			}
+			for i, l := range langs {
+				track := base
+				track.Track = &langs[i].index
+				track.setLanguage(l.lang, "idx")
+				tracks = append(tracks, track)
+			}
+
+		case textFormats[c.format]:
+			text, err := readSubtitleText(c.path, detectLimit)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %w", c.name, err))
+			} else if l := detectLanguage(dialogue(text)); l != nil {
+				base.setLanguage(l, "content")
+			}
+		}
+
+		if len(tracks) == 0 {
+			if base.Language == "" {
+				base.Language = "und"
+			}
+			tracks = append(tracks, base)
+		}
+
+		for _, t := range tracks {
+			subtitles[subtitleKey(subtitles, t)] = t
 		}
 	}
 
@@ -85,30 +173,135 @@ func (f SubtitlesFilter) Apply(
 	}
 }
 
-func detectLangWithFilter(filename string, excludeFilters []string) string {
-	lower := strings.ToLower(filename)
+func (s *Subtitle) setLanguage(l *Language, source string) {
+	if l == nil {
+		s.Language = "und"
+		return
+	}
 
-	// Strip .srt extension
-	lower = strings.TrimSuffix(lower, ".srt")
+	s.Language = l.Code2
+	s.LanguageName = l.Name
+	s.LanguageSource = source
+}
 
-	// Remove any exclude substrings
-	for _, excl := range excludeFilters {
-		lower = strings.ReplaceAll(lower, strings.ToLower(excl), "")
+// subtitleKey is the language, a flag suffix for forced, SDH and CC
+// tracks, and a number when that is taken: "en", "en-forced", "en-2".
+func subtitleKey(taken Subtitles, s Subtitle) string {
+	key := s.Language
+	if l, ok := LookupLanguage(s.Language); ok {
+		key = l.Key()
 	}
 
-	// Clean leftover separators
-	lower = strings.Trim(lower, ".-_ ")
+	switch {
+	case s.Forced:
+		key += "-forced"
+	case s.SDH:
+		key += "-sdh"
+	case s.CC:
+		key += "-cc"
+	}
 
-	// Look for language code after last dot or dash
-	for _, sep := range []string{".", "-"} {
-		if idx := strings.LastIndex(lower, sep); idx != -1 {
-			part := lower[idx+1:]
-			if len(part) == 2 { // simple ISO code check
-				return part
+	if _, ok := taken[key]; !ok {
+		return key
+	}
+
+	for n := 2; ; n++ {
+		k := key + "-" + strconv.Itoa(n)
+		if _, ok := taken[k]; !ok {
+			return k
+		}
+	}
+}
+
+type subtitleFlags struct {
+	forced, sdh, cc bool
+}
+
+var nameSeparators = func(r rune) bool {
+	switch r {
+	case '.', '_', '-', ' ', '[', ']', '(', ')':
+		return true
+	}
+	return false
+}
+
+// parseSubtitleName reads the language and flags from the tail of a name,
+// e.g. Movie.2016.eng.forced.srt or Movie (2016) - English SDH.srt. It
+// stops at the first word that is neither, so titles such as "The English
+// Patient" are not mistaken for a language, and never takes the whole name.
+func parseSubtitleName(name string) (*Language, subtitleFlags) {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	for _, tag := range releaseTags {
+		base = strings.ReplaceAll(strings.ToLower(base), strings.ToLower(tag), " ")
+	}
+
+	tokens := strings.FieldsFunc(strings.ToLower(base), nameSeparators)
+
+	var (
+		lang  *Language
+		flags subtitleFlags
+	)
+
+	for i := len(tokens) - 1; i > 0; i-- {
+		t := tokens[i]
+
+		// "hi" after a language is hearing impaired, not Hindi
+		if t == "hi" && i == len(tokens)-1 {
+			if _, ok := LookupLanguage(tokens[i-1]); ok && i > 1 {
+				flags.sdh = true
+				continue
 			}
 		}
+
+		switch t {
+		case "forced", "foreign":
+			flags.forced = true
+			continue
+		case "sdh", "hearing", "impaired":
+			flags.sdh = true
+			continue
+		case "cc":
+			flags.cc = true
+			continue
+		}
+
+		l, ok := LookupLanguage(t)
+		if !ok || lang != nil {
+			break
+		}
+		lang = l
+	}
+
+	return lang, flags
+}
+
+type idxLanguage struct {
+	lang  *Language
+	index int
+}
+
+var idxID = regexp.MustCompile(`^id:\s*([A-Za-z]{2,3})\s*,\s*index:\s*(\d+)`)
+
+// idxLanguages lists the "id: en, index: 0" lines of a VobSub index.
+func idxLanguages(path string) ([]idxLanguage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []idxLanguage
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := idxID.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+
+		l, _ := LookupLanguage(m[1])
+		index, _ := strconv.Atoi(m[2])
+		out = append(out, idxLanguage{lang: l, index: index})
 	}
 
-	// If nothing found, fallback
-	return "id"
+	return out, scanner.Err()
 }
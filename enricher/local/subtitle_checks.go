@@ -0,0 +1,220 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+// Issues reported in SubtitleChecks.Issues.
+const (
+	IssueBOM        = "bom"              // UTF-8 with a byte order mark
+	IssueNotUTF8    = "not_utf8"         // UTF-16 or a legacy code page
+	IssueMalformed  = "malformed_timing" // a timing line that does not parse, or ends before it starts
+	IssueOverlap    = "overlapping_cues"
+	IssueOutOfOrder = "out_of_order"
+	IssuePastEnd    = "past_end"   // the last cue is after the end of the video
+	IssueEndsEarly  = "ends_early" // the last cue is far before the end of the video
+	IssueNoCues     = "no_cues"
+)
+
+// Values of SubtitleChecks.Status.
+const (
+	CheckOK       = "ok"
+	CheckWarning  = "warning"  // readable, but worth fixing
+	CheckMismatch = "mismatch" // probably made for a different cut
+)
+
+// pastEndSlack is how far beyond the video the last cue may end, for
+// rounding and a credits line; endsEarlyRatio is how much of the video
+// may follow the last cue, which covers long end credits.
+const (
+	pastEndSlack     = 30 * time.Second
+	endsEarlyRatio   = 0.2
+	overlapTolerance = 10 * time.Millisecond
+)
+
+// SubtitleChecks is the result of checking an SRT or VTT file against
+// itself and the probed length of the main video.
+type SubtitleChecks struct {
+	Status        string   `json:"status"`   // "ok", "warning" or "mismatch"
+	Encoding      string   `json:"encoding"` // "utf-8", "utf-16le", "utf-16be" or "other"
+	Cues          int      `json:"cues"`
+	LastCue       float64  `json:"last_cue,omitempty"`       // end of the last cue, in seconds
+	VideoDuration float64  `json:"video_duration,omitempty"` // from media.probe, when known
+	Overlaps      int      `json:"overlaps,omitempty"`
+	OutOfOrder    int      `json:"out_of_order,omitempty"`
+	Malformed     int      `json:"malformed,omitempty"`
+	Issues        []string `json:"issues,omitempty"`
+}
+
+// Cue is one timed entry of a subtitle file.
+type Cue struct {
+	Start, End time.Duration
+}
+
+var cueTiming = regexp.MustCompile(
+	`^\s*(?:(\d+):)?(\d{1,2}):(\d{1,2})[,.](\d{1,3})\s*-->\s*(?:(\d+):)?(\d{1,2}):(\d{1,2})[,.](\d{1,3})`)
+
+// ParseCues reads the timing lines of SRT or WebVTT text. Lines with an
+// arrow that do not parse are counted as malformed.
+func ParseCues(text string) (cues []Cue, malformed int) {
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.Contains(line, "-->") {
+			continue
+		}
+
+		m := cueTiming.FindStringSubmatch(line)
+		if m == nil {
+			malformed++
+			continue
+		}
+
+		cue := Cue{Start: timestamp(m[1:5]), End: timestamp(m[5:9])}
+		if cue.End < cue.Start {
+			malformed++
+			continue
+		}
+
+		cues = append(cues, cue)
+	}
+
+	return cues, malformed
+}
+
+// timestamp converts hours (optional), minutes, seconds and a fraction
+// of 1 to 3 digits.
+func timestamp(parts []string) time.Duration {
+	h, _ := strconv.Atoi(parts[0])
+	m, _ := strconv.Atoi(parts[1])
+	s, _ := strconv.Atoi(parts[2])
+
+	frac := parts[3]
+	ms, _ := strconv.Atoi(frac)
+	for i := len(frac); i < 3; i++ {
+		ms *= 10
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(s)*time.Second + time.Duration(ms)*time.Millisecond
+}
+
+// checkSubtitle reads the whole file; videoDuration is 0 when unknown.
+func checkSubtitle(path string, videoDuration float64) (*SubtitleChecks, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := &SubtitleChecks{Encoding: "utf-8", VideoDuration: videoDuration}
+
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		checks.Issues = append(checks.Issues, IssueBOM)
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		checks.Encoding = "utf-16le"
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		checks.Encoding = "utf-16be"
+	case !utf8.Valid(data):
+		checks.Encoding = "other"
+	}
+	if checks.Encoding != "utf-8" {
+		checks.Issues = append(checks.Issues, IssueNotUTF8)
+	}
+
+	cues, malformed := ParseCues(strings.ReplaceAll(decodeText(data), "\r", ""))
+	checks.Cues = len(cues)
+	checks.Malformed = malformed
+	if malformed > 0 {
+		checks.Issues = append(checks.Issues, IssueMalformed)
+	}
+
+	var last time.Duration
+	for i, cue := range cues {
+		last = max(last, cue.End)
+		if i == 0 {
+			continue
+		}
+
+		prev := cues[i-1]
+		switch {
+		case cue.Start < prev.Start:
+			checks.OutOfOrder++
+		case cue.Start+overlapTolerance < prev.End:
+			checks.Overlaps++
+		}
+	}
+
+	if checks.OutOfOrder > 0 {
+		checks.Issues = append(checks.Issues, IssueOutOfOrder)
+	}
+	if checks.Overlaps > 0 {
+		checks.Issues = append(checks.Issues, IssueOverlap)
+	}
+
+	if len(cues) == 0 {
+		checks.Issues = append(checks.Issues, IssueNoCues)
+	} else {
+		checks.LastCue = math.Round(last.Seconds()*1e3) / 1e3
+	}
+
+	mismatch := false
+	if videoDuration > 0 && len(cues) > 0 {
+		video := time.Duration(videoDuration * float64(time.Second))
+		switch {
+		case last > video+pastEndSlack:
+			checks.Issues = append(checks.Issues, IssuePastEnd)
+			mismatch = true
+		case float64(video-last) > float64(video)*endsEarlyRatio:
+			checks.Issues = append(checks.Issues, IssueEndsEarly)
+			mismatch = true
+		}
+	}
+
+	switch {
+	case mismatch:
+		checks.Status = CheckMismatch
+	case len(checks.Issues) > 0:
+		checks.Status = CheckWarning
+	default:
+		checks.Status = CheckOK
+	}
+
+	return checks, nil
+}
+
+// mediaDuration is the probed length of the item's main video in seconds,
+// or 0 when the probe filter has not run.
+func mediaDuration(item any) float64 {
+	mediaVal, err := jsonpath.Get(item, "media")
+	if err != nil || mediaVal == nil {
+		return 0
+	}
+
+	if ms, ok := mediaVal.(*MediaSource); ok {
+		if ms.Probe == nil {
+			return 0
+		}
+		return ms.Probe.Duration
+	}
+
+	// read back from a file: a map, or a struct round-tripped through JSON
+	var media struct {
+		Probe struct {
+			Duration float64 `json:"duration"`
+		} `json:"probe"`
+	}
+	if data, err := json.Marshal(mediaVal); err == nil {
+		_ = json.Unmarshal(data, &media)
+	}
+
+	return media.Probe.Duration
+}
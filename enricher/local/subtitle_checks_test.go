@@ -0,0 +1,84 @@
+package local_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mrizkifadil26/medix/enricher/local"
+	"github.com/mrizkifadil26/medix/utils/probe"
+)
+
+func TestParseCues(t *testing.T) {
+	text := "WEBVTT\n\n00:01.500 --> 00:03.000 align:start\nHi\n\n01:00:00.25 --> 01:00:02,000\nBye\n\n00:05 --> 00:06\n"
+
+	cues, malformed := local.ParseCues(text)
+
+	want := []local.Cue{
+		{Start: 1500 * time.Millisecond, End: 3 * time.Second},
+		{Start: time.Hour + 250*time.Millisecond, End: time.Hour + 2*time.Second},
+	}
+	if !reflect.DeepEqual(cues, want) || malformed != 1 {
+		t.Errorf("got %v (%d malformed), want %v (1 malformed)", cues, malformed, want)
+	}
+}
+
+func TestSubtitleChecks(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		// clean, ends a few minutes before the video
+		"Movie.en.srt": "1\r\n00:00:01,000 --> 00:00:02,000\r\nHello\r\n\r\n2\r\n00:00:03,000 --> 01:55:00,000\r\nBye\r\n",
+		// BOM, an overlap and cues past the end: a longer cut
+		"Movie.fr.srt": "\xEF\xBB\xBF1\n00:00:01,000 --> 00:00:05,000\nA\n\n2\n00:00:04,000 --> 02:10:00,000\nB\n",
+		// Latin-1 and out of order, but ending where the video does
+		"Movie.es.srt": "1\n00:00:10,000 --> 00:00:11,000\nMa\xf1ana\n\n2\n00:00:05,000 --> 01:58:00,000\nS\xed\n",
+	}
+
+	dirItem := map[string]any{
+		"type":  "directory",
+		"path":  dir,
+		"media": &local.MediaSource{Name: "Movie.mkv", Probe: &probe.Info{Container: "matroska", Duration: 2 * 3600}},
+	}
+
+	var children []any
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		children = append(children, map[string]any{"name": name, "ext": ".srt", "path": path})
+	}
+	dirItem["children"] = children
+
+	var errs []error
+	local.SubtitlesFilter{}.Apply(dirItem, &errs)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	subs := dirItem["subtitles"].(local.Subtitles)
+
+	want := map[string]struct {
+		status   string
+		encoding string
+		issues   []string
+	}{
+		"en": {local.CheckOK, "utf-8", nil},
+		"fr": {local.CheckMismatch, "utf-8", []string{local.IssueBOM, local.IssueOverlap, local.IssuePastEnd}},
+		"es": {local.CheckWarning, "other", []string{local.IssueNotUTF8, local.IssueOutOfOrder}},
+	}
+
+	for key, w := range want {
+		c := subs[key].Checks
+		if c == nil {
+			t.Errorf("%s: no checks", key)
+			continue
+		}
+		if c.Status != w.status || c.Encoding != w.encoding || !reflect.DeepEqual(c.Issues, w.issues) {
+			t.Errorf("%s: got %s/%s %v, want %s/%s %v", key, c.Status, c.Encoding, c.Issues, w.status, w.encoding, w.issues)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package local_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/enricher/local"
+)
+
+const indonesianSRT = `1
+00:00:01,000 --> 00:00:03,000
+Aku tidak tahu apa yang kau bicarakan.
+
+2
+00:00:03,500 --> 00:00:06,000
+<i>Ini bukan salahku, kita harus pergi sekarang.</i>
+
+3
+00:00:06,500 --> 00:00:09,000
+Jangan bilang itu pada mereka, aku sudah janji.
+
+4
+00:00:09,500 --> 00:00:12,000
+Kamu bisa ikut dengan kami kalau kau mau.
+`
+
+func TestSubtitlesFilter(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Movie.2016.eng.srt":           "",
+		"Movie.2016.English.SDH.srt":   "",
+		"Movie.2016.en.forced.ass":     "",
+		"Movie.2016.en.srt":            "",
+		"The.English.Patient.1996.srt": indonesianSRT,
+		"Movie.2016.idx":               "# VobSub index file\nid: en, index: 0\ntimestamp: 00:00:01:000, filepos: 000000000\nid: es, index: 1\n",
+		"Movie.2016.sub":               "",
+		"Movie.2016.por.sup":           "",
+		"Movie.2016.mkv":               "",
+	}
+
+	var children []any
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		children = append(children, map[string]any{"name": name, "ext": filepath.Ext(name), "path": path})
+	}
+
+	item := map[string]any{"type": "directory", "path": dir, "children": children}
+
+	var errs []error
+	local.SubtitlesFilter{}.Apply(item, &errs)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	subs, _ := item["subtitles"].(local.Subtitles)
+
+	got := map[string]string{}
+	for key, s := range subs {
+		got[key] = s.Name + " " + s.Language + " " + s.LanguageSource
+	}
+
+	want := map[string]string{
+		"en-sdh":    "Movie.2016.English.SDH.srt eng filename",
+		"en-forced": "Movie.2016.en.forced.ass eng filename",
+		"id":        "The.English.Patient.1996.srt ind content",
+		"es":        "Movie.2016.idx spa idx",
+		"pt":        "Movie.2016.por.sup por filename",
+	}
+	for key, w := range want {
+		if got[key] != w {
+			t.Errorf("subtitles[%q] = %q, want %q", key, got[key], w)
+		}
+	}
+
+	// two plain English tracks, in either order, plus the English idx track
+	var plain []string
+	for _, key := range []string{"en", "en-2", "en-3"} {
+		plain = append(plain, strings.Fields(got[key])[0])
+	}
+	if len(subs) != len(want)+3 {
+		t.Errorf("got %d subtitles, want %d: %v", len(subs), len(want)+3, got)
+	}
+	for _, name := range []string{"Movie.2016.eng.srt", "Movie.2016.en.srt", "Movie.2016.idx"} {
+		if !strings.Contains(strings.Join(plain, " "), name) {
+			t.Errorf("%s missing from the English tracks %v", name, plain)
+		}
+	}
+
+	if subs["en-sdh"].SDH != true || subs["en-forced"].Forced != true {
+		t.Errorf("flags not set: %+v %+v", subs["en-sdh"], subs["en-forced"])
+	}
+}
@@ -1,27 +1,47 @@
 package local
 
+import (
+	"github.com/mrizkifadil26/medix/utils/ico"
+	"github.com/mrizkifadil26/medix/utils/probe"
+)
+
 type MediaSource struct {
-	Name      string `json:"name"`
-	Path      string `json:"path"`
-	Extension string `json:"ext"`
-	Size      int64  `json:"size"`
+	Name      string      `json:"name"`
+	Path      string      `json:"path"`
+	Extension string      `json:"ext"`
+	Size      int64       `json:"size"`
+	Type      string      `json:"type,omitempty"`  // e.g. "IMAX" or "trailer"; see extraType
+	Probe     *probe.Info `json:"probe,omitempty"` // set by the probe filter
 }
 
 type Media map[string]MediaSource
 
 type IconSource struct {
-	Name      string
-	Path      string
-	Extension string
-	Size      int64
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Extension string    `json:"ext"`
+	Size      int64     `json:"size"`
+	ICO       *ico.Info `json:"ico,omitempty"` // embedded images and quality issues
 }
 
 type Subtitle struct {
-	Name string `json:"name"`
-	Path string `json:"path"`
-	Ext  string `json:"ext"`
+	Name           string `json:"name"`
+	Path           string `json:"path"`
+	Ext            string `json:"ext"`
+	Format         string `json:"format"`                    // "subrip", "ass", "webvtt", "vobsub", "pgs", ...
+	Language       string `json:"language"`                  // ISO 639-2, "und" when unknown
+	LanguageName   string `json:"language_name,omitempty"`   // e.g. "English"
+	LanguageSource string `json:"language_source,omitempty"` // "filename", "idx" or "content"
+	Forced         bool   `json:"forced,omitempty"`
+	SDH            bool   `json:"sdh,omitempty"`
+	CC             bool   `json:"cc,omitempty"`
+	Track          *int   `json:"track,omitempty"` // index within a VobSub .idx
+
+	Checks *SubtitleChecks `json:"checks,omitempty"` // SRT and VTT only
 }
 
+// Subtitles are keyed by language with a flag or number suffix when
+// needed, e.g. "en", "en-sdh", "en-2"; see subtitleKey.
 type Subtitles map[string]Subtitle
 
 type Group struct {
@@ -33,3 +53,19 @@ type Collection struct {
 	Name  string  `json:"name"`
 	Group []Group `json:"group"`
 }
+
+// NFO is what the nfo filter read from a movie or tvshow NFO file.
+type NFO struct {
+	Kind          string   `json:"kind,omitempty"` // "movie" or "tvshow"; empty for a URL-only file
+	Title         string   `json:"title,omitempty"`
+	OriginalTitle string   `json:"original_title,omitempty"`
+	Year          string   `json:"year,omitempty"`
+	TMDbID        int      `json:"tmdb_id,omitempty"`
+	IMDbID        string   `json:"imdb_id,omitempty"`
+	Genres        []string `json:"genres,omitempty"`
+	Plot          string   `json:"plot,omitempty"`
+	Path          string   `json:"path"`
+}
+
+// Artwork maps a Kodi artwork type ("poster", "fanart", ...) to its file.
+type Artwork map[string]MediaSource
@@ -1,20 +1,78 @@
 package enricher
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/mrizkifadil26/medix/enricher/core"
 	"github.com/mrizkifadil26/medix/utils"
 )
 
-var enricherRegistry = utils.NewRegistry[core.Enricher]()
+// Factory builds an enricher from its entry in options.enrichers. Enricher
+// packages register one from init; import them for their side effect, as
+// with the normalizer actions.
+type Factory func(cfg EnricherConfig) (core.Enricher, error)
+
+var enricherRegistry = utils.NewRegistry[Factory]()
 
-func Register(e core.Enricher) {
-	enricherRegistry.Register(e.Name(), e)
+// Register makes an enricher available under name.
+func Register(name string, factory Factory) {
+	enricherRegistry.Register(name, factory)
 }
 
-func Get(name string) (core.Enricher, bool) {
+func Get(name string) (Factory, bool) {
 	return enricherRegistry.Get(name)
 }
 
-func All() map[string]core.Enricher {
+func All() map[string]Factory {
 	return enricherRegistry.All()
 }
+
+func buildEnricher(eCfg EnricherConfig) (core.Enricher, error) {
+	factory, ok := Get(eCfg.Name)
+	if !ok {
+		return nil, fmt.Errorf("unknown enricher %q (registered: %s)", eCfg.Name, registeredNames())
+	}
+
+	e, err := factory(eCfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s enricher: %w", eCfg.Name, err)
+	}
+
+	return e, nil
+}
+
+// Decode reads the entry's config object into v, which should hold the
+// defaults. Unknown keys are errors so typos do not silently disable an
+// option. A missing config leaves v unchanged.
+func (c EnricherConfig) Decode(v any) error {
+	raw := bytes.TrimSpace(c.Config)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	return nil
+}
+
+func registeredNames() string {
+	names := make([]string, 0, len(All()))
+	for name := range All() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,39 @@
+package enricher_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/enricher"
+	_ "github.com/mrizkifadil26/medix/enricher/local"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+func TestEnricherConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries string
+		want    string
+	}{
+		{"unknown enricher", `[{"name": "imdb"}]`, `unknown enricher "imdb"`},
+		{"unknown config key", `[{"name": "local", "config": {"filter": ["media"]}}]`, `unknown field "filter"`},
+		{"unknown entry key", `[{"name": "local", "confg": {}}]`, `unknown field "confg"`},
+		{"unknown filter", `[{"name": "local", "filters": ["poster"]}]`, `unknown filter "poster"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config enricher.Config
+			err := json.Unmarshal([]byte(`{"options": {"enrichers": `+tt.entries+`}}`), &config)
+			if err == nil {
+				_, err = enricher.Enrich(context.Background(), utils.NewOrderedMap[string, any](), &config)
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/mrizkifadil26/medix/enricher/core"
+)
+
+// job is one configured enricher and the jobs it waits for.
+type job struct {
+	enricher core.Enricher
+	opts     core.Options
+	deps     []int // indexes into the job list
+}
+
+func (j *job) name() string { return j.enricher.Name() }
+
+// buildJobs creates the enrichers of config and resolves their
+// dependencies: depends_on from the config, which must name configured
+// enrichers, plus core.Dependent, whose unconfigured names are ignored.
+// The jobs come back in dependency order.
+func buildJobs(config *Config, locks *core.Locks) ([]*job, error) {
+	var (
+		jobs   []*job
+		byName = map[string]int{}
+	)
+
+	for _, entry := range config.Options.Enrichers {
+		if _, dup := byName[entry.Name]; dup {
+			return nil, fmt.Errorf("enricher %q is configured twice", entry.Name)
+		}
+
+		e, err := buildEnricher(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		concurrency := entry.Concurrency
+		if concurrency == 0 {
+			concurrency = config.Options.Concurrency
+		}
+
+		byName[entry.Name] = len(jobs)
+		jobs = append(jobs, &job{
+			enricher: e,
+			opts:     core.Options{Concurrency: concurrency, Locks: locks},
+		})
+	}
+
+	for i, entry := range config.Options.Enrichers {
+		for _, dep := range entry.DependsOn {
+			d, ok := byName[dep]
+			if !ok {
+				return nil, fmt.Errorf("%s enricher depends on %q, which is not configured", entry.Name, dep)
+			}
+			jobs[i].addDep(d)
+		}
+
+		if dependent, ok := jobs[i].enricher.(core.Dependent); ok {
+			for _, dep := range dependent.DependsOn() {
+				if d, ok := byName[dep]; ok {
+					jobs[i].addDep(d)
+				}
+			}
+		}
+
+		if slices.Contains(jobs[i].deps, i) {
+			return nil, fmt.Errorf("%s enricher depends on itself", entry.Name)
+		}
+	}
+
+	return sortJobs(jobs)
+}
+
+func (j *job) addDep(d int) {
+	if !slices.Contains(j.deps, d) {
+		j.deps = append(j.deps, d)
+	}
+}
+
+// sortJobs orders jobs so dependencies come first, keeping the configured
+// order otherwise, and remaps deps to the new positions.
+func sortJobs(jobs []*job) ([]*job, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		state = make([]int, len(jobs))
+		order []int
+		visit func(i int) error
+	)
+
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("enricher dependency cycle through %s", jobs[i].name())
+		case visited:
+			return nil
+		}
+
+		state[i] = visiting
+		for _, d := range jobs[i].deps {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		order = append(order, i)
+
+		return nil
+	}
+
+	for i := range jobs {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	position := make([]int, len(jobs))
+	for pos, i := range order {
+		position[i] = pos
+	}
+
+	sorted := make([]*job, len(jobs))
+	for pos, i := range order {
+		j := jobs[i]
+		for k, d := range j.deps {
+			j.deps[k] = position[d]
+		}
+		sorted[pos] = j
+	}
+
+	return sorted, nil
+}
+
+// runJobs starts every job as soon as its dependencies have finished, so
+// independent enrichers run at the same time. A failed job skips the jobs
+// that depend on it; the others still run.
+func runJobs(ctx context.Context, jobs []*job, run func(ctx context.Context, j *job) error) error {
+	var (
+		done = make([]chan struct{}, len(jobs))
+		errs = make([]error, len(jobs))
+	)
+
+	for i := range jobs {
+		done[i] = make(chan struct{})
+	}
+
+	for i, j := range jobs {
+		go func() {
+			defer close(done[i])
+
+			for _, d := range j.deps {
+				<-done[d]
+				if errs[d] != nil {
+					errs[i] = fmt.Errorf("%s enricher skipped: %s failed", j.name(), jobs[d].name())
+					return
+				}
+			}
+
+			if err := run(ctx, j); err != nil {
+				errs[i] = fmt.Errorf("%s enricher failed: %w", j.name(), err)
+			}
+		}()
+	}
+
+	for _, ch := range done {
+		<-ch
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,172 @@
+package enricher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrizkifadil26/medix/enricher"
+	"github.com/mrizkifadil26/medix/enricher/core"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+// fakeEnricher sets its name on every item and records when it ran.
+type fakeEnricher struct {
+	name    string
+	started chan struct{}
+	wait    chan struct{} // closed by the test or by a sibling
+	fail    bool
+	log     *runLog
+}
+
+type runLog struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (l *runLog) add(s string) {
+	l.mu.Lock()
+	l.order = append(l.order, s)
+	l.mu.Unlock()
+}
+
+func (f *fakeEnricher) Name() string { return f.name }
+
+func (f *fakeEnricher) Enrich(ctx context.Context, data any, opts core.Options) error {
+	close(f.started)
+	if f.wait != nil {
+		select {
+		case <-f.wait:
+		case <-time.After(2 * time.Second):
+			return fmt.Errorf("sibling never started")
+		}
+	}
+
+	if f.fail {
+		return errors.New("boom")
+	}
+
+	unlock := opts.Locks.Item(core.Root)
+	items, _ := jsonpath.Query(data, "items.#")
+	unlock()
+
+	for i, item := range items {
+		unlock := opts.Locks.Item(i)
+		_ = jsonpath.Set(item, f.name, opts.Concurrency)
+		unlock()
+	}
+
+	f.log.add(f.name)
+	return nil
+}
+
+func register(log *runLog, names ...string) map[string]*fakeEnricher {
+	fakes := map[string]*fakeEnricher{}
+	for _, name := range names {
+		f := &fakeEnricher{name: name, started: make(chan struct{}), log: log}
+		fakes[name] = f
+		enricher.Register(name, func(enricher.EnricherConfig) (core.Enricher, error) { return f, nil })
+	}
+
+	return fakes
+}
+
+func document(n int) *utils.OrderedMap[string, any] {
+	doc := utils.NewOrderedMap[string, any]()
+	items := make([]any, n)
+	for i := range items {
+		item := utils.NewOrderedMap[string, any]()
+		item.Set("path", fmt.Sprintf("/movies/%d", i))
+		item.Set("name", fmt.Sprint(i))
+		items[i] = item
+	}
+	doc.Set("items", items)
+
+	return doc
+}
+
+func parseConfig(t *testing.T, enrichers string) *enricher.Config {
+	t.Helper()
+
+	var config enricher.Config
+	if err := json.Unmarshal([]byte(`{"options": {"concurrency": 2, "enrichers": `+enrichers+`}}`), &config); err != nil {
+		t.Fatal(err)
+	}
+
+	return &config
+}
+
+func TestIndependentEnrichersRunConcurrently(t *testing.T) {
+	log := &runLog{}
+	fakes := register(log, "test-fs", "test-remote", "test-merge")
+
+	// each of the two independent enrichers waits until the other started
+	fakes["test-fs"].wait = fakes["test-remote"].started
+	fakes["test-remote"].wait = fakes["test-fs"].started
+
+	config := parseConfig(t, `[
+		{"name": "test-merge", "depends_on": ["test-fs", "test-remote"]},
+		{"name": "test-fs", "concurrency": 8},
+		{"name": "test-remote"}
+	]`)
+
+	doc := document(20)
+	if _, err := enricher.Enrich(context.Background(), doc, config); err != nil {
+		t.Fatal(err)
+	}
+
+	if last := log.order[len(log.order)-1]; last != "test-merge" {
+		t.Errorf("dependent enricher ran before its dependencies: %v", log.order)
+	}
+
+	got, _ := jsonpath.Get(doc, "items.3.test-fs")
+	if got != 8 {
+		t.Errorf("test-fs concurrency = %v, want its own 8", got)
+	}
+
+	got, _ = jsonpath.Get(doc, "items.3.test-remote")
+	if got != 2 {
+		t.Errorf("test-remote concurrency = %v, want options.concurrency 2", got)
+	}
+}
+
+func TestFailedDependencySkipsDependents(t *testing.T) {
+	log := &runLog{}
+	fakes := register(log, "test-bad", "test-after", "test-other")
+	fakes["test-bad"].fail = true
+
+	config := parseConfig(t, `[
+		{"name": "test-bad"},
+		{"name": "test-after", "depends_on": ["test-bad"]},
+		{"name": "test-other"}
+	]`)
+
+	_, err := enricher.Enrich(context.Background(), document(2), config)
+	if err == nil || !strings.Contains(err.Error(), "test-after enricher skipped") {
+		t.Errorf("expected the dependent to be skipped, got %v", err)
+	}
+
+	if len(log.order) != 1 || log.order[0] != "test-other" {
+		t.Errorf("only the independent enricher should have finished, got %v", log.order)
+	}
+}
+
+func TestDependencyCycle(t *testing.T) {
+	register(&runLog{}, "test-a", "test-b")
+
+	config := parseConfig(t, `[
+		{"name": "test-a", "depends_on": ["test-b"]},
+		{"name": "test-b", "depends_on": ["test-a"]}
+	]`)
+
+	_, err := enricher.Enrich(context.Background(), document(1), config)
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+}
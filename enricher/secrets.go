@@ -0,0 +1,108 @@
+package enricher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfig reads an enrich config (JSON or YAML), expanding ${VAR}
+// references. It refuses files that carry a literal API token: secrets
+// belong in the environment or in a file named by api_key_file.
+func LoadConfig(path string) (Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("open config file: %w", err)
+	}
+
+	if err := checkSecrets(path, raw); err != nil {
+		return Config{}, err
+	}
+
+	return utils.LoadConfig[Config](path)
+}
+
+var (
+	// jwtPattern matches bearer tokens such as TMDb v4 read access tokens.
+	jwtPattern = regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`)
+
+	// envOnly is a value made only of ${VAR} references.
+	envOnly = regexp.MustCompile(`^(\$\{[A-Za-z_][A-Za-z0-9_]*(:-[^}]*)?\})+$`)
+)
+
+// secretKey reports whether a config key normally holds a credential.
+func secretKey(key string) bool {
+	key = strings.ToLower(key)
+
+	switch key {
+	case "token", "secret", "password", "bearer":
+		return true
+	}
+
+	return strings.HasSuffix(key, "_key") || strings.HasSuffix(key, "_token") ||
+		strings.HasSuffix(key, "_secret") || strings.HasSuffix(key, "apikey")
+}
+
+// checkSecrets looks at the file as written, before interpolation, so a
+// ${TMDB_API_KEY} reference passes and a pasted token does not.
+func checkSecrets(path string, raw []byte) error {
+	var doc any
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(raw, &doc); err != nil {
+			return fmt.Errorf("decode YAML: %w", err)
+		}
+	default:
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return fmt.Errorf("decode JSON: %w", err)
+		}
+	}
+
+	var found []string
+	var walk func(v any, at, key string)
+	walk = func(v any, at, key string) {
+		switch val := v.(type) {
+		case map[string]any:
+			for k, child := range val {
+				walk(child, join(at, k), k)
+			}
+		case []any:
+			for i, child := range val {
+				walk(child, join(at, strconv.Itoa(i)), "")
+			}
+		case string:
+			if envOnly.MatchString(val) {
+				return
+			}
+			if jwtPattern.MatchString(val) || (secretKey(key) && val != "") {
+				found = append(found, at)
+			}
+		}
+	}
+	walk(doc, "", "")
+
+	if len(found) > 0 {
+		sort.Strings(found)
+		return fmt.Errorf("%s: %s looks like a committed API token; use a ${VAR} reference or api_key_file instead",
+			path, strings.Join(found, ", "))
+	}
+
+	return nil
+}
+
+func join(base, key string) string {
+	if base == "" {
+		return key
+	}
+
+	return base + "." + key
+}
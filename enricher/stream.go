@@ -0,0 +1,132 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mrizkifadil26/medix/enricher/core"
+	"github.com/mrizkifadil26/medix/schema"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+// EnrichFile enriches inPath into outPath one item at a time. Every enricher
+// runs on an item, in dependency order, before the item is written, and up
+// to Options.Concurrency items are in flight; an enricher with its own
+// concurrency never works on more items than that at once. Item errors do
+// not stop the stream; they are returned together once the output has been
+// written. Schema violations do: the run aborts and outPath is left
+// untouched.
+//
+// Enricher caches are saved every config.Checkpoint. Once ctx is cancelled
+// the remaining items are copied through unchanged, so the output is
+// complete but partly enriched and a run with config.Resume can pick up
+// from it.
+func EnrichFile(ctx context.Context, inPath, outPath string, config *Config) error {
+	interval, err := config.checkpointInterval()
+	if err != nil {
+		return err
+	}
+
+	// items are not shared between goroutines here, so no locks
+	jobs, err := buildJobs(config, nil)
+	if err != nil {
+		return err
+	}
+
+	var previous map[string]any
+	if config.Resume {
+		if previous, err = previousResults(outPath); err != nil {
+			return err
+		}
+		fmt.Printf("⏩ Resuming with %d results from %s\n", len(previous), outPath)
+	}
+
+	workers := config.Options.Concurrency
+	enrichers := make([]core.ItemEnricher, len(jobs))
+	slots := make([]chan struct{}, len(jobs))
+
+	for i, j := range jobs {
+		j.opts.Resume = config.Resume
+
+		ie, ok := j.enricher.(core.ItemEnricher)
+		if !ok {
+			return fmt.Errorf("%s enricher does not support streaming", j.name())
+		}
+		enrichers[i] = ie
+
+		if c := j.opts.Concurrency; c > 0 {
+			slots[i] = make(chan struct{}, c)
+			workers = max(workers, c)
+		}
+	}
+
+	var (
+		errsMu sync.Mutex
+		errs   []error
+	)
+
+	opts := utils.StreamOptions{
+		Workers: workers,
+		Trailer: func() (*utils.OrderedMap[string, any], error) {
+			extra := utils.NewOrderedMap[string, any]()
+			for _, e := range enrichers {
+				keys, err := e.Finish()
+				if err != nil {
+					return nil, fmt.Errorf("%s enricher failed: %w", e.Name(), err)
+				}
+
+				for k, v := range keys {
+					extra.Set(k, v)
+				}
+			}
+
+			return extra, nil
+		},
+	}
+
+	stop := startCheckpoints(interval, func() { checkpointEnrichers(jobs) })
+	defer stop()
+
+	err = utils.StreamFile(inPath, outPath, opts, func(idx int, item any) (any, error) {
+		restoreItem(item, previous)
+
+		for i, e := range enrichers {
+			if ctx.Err() != nil {
+				return item, nil
+			}
+
+			if slots[i] != nil {
+				slots[i] <- struct{}{}
+			}
+			err := e.EnrichItem(ctx, item, jobs[i].opts)
+			if slots[i] != nil {
+				<-slots[i]
+			}
+
+			if err != nil && ctx.Err() == nil {
+				errsMu.Lock()
+				errs = append(errs, utils.NewItemError(idx, item, fmt.Errorf("%s enricher: %w", e.Name(), err)))
+				errsMu.Unlock()
+			}
+		}
+
+		if config.Validate {
+			if err := schema.ValidateItem(schema.Enriched, idx, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return item, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("interrupted, partial output saved to %s (rerun with --resume): %w", outPath, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -1,21 +1,49 @@
 package tmdb
 
-import "github.com/mrizkifadil26/medix/utils/cache"
+import (
+	"sync"
+
+	"github.com/mrizkifadil26/medix/utils/cache"
+)
 
 // internal cache managers, not exported
 type dataCache = cache.Manager[*EnrichedItem]
 type genreCache = cache.Manager[map[int]string]
 type langCache = cache.Manager[map[string]string]
+type creditsCache = cache.Manager[TMDbCredits]
 
-// create all caches internally
-func newCaches() (*dataCache, *genreCache, *langCache) {
-	return cache.NewManager[*EnrichedItem]("tmdb.data.cache.json"),
-		cache.NewManager[map[int]string]("tmdb.genres.cache.json"),
-		cache.NewManager[map[string]string]("tmdb.languages.cache.json")
+// caches are shared by every enricher in the process: the pipeline runs
+// labels in parallel, and managers of their own on the same files would
+// each save only their own entries, the last one dropping the others'.
+var (
+	cachesOnce    sync.Once
+	sharedData    *dataCache
+	sharedGenres  *genreCache
+	sharedLangs   *langCache
+	sharedCredits *creditsCache
+)
+
+// loadCaches creates and loads the caches on first use (best effort).
+func loadCaches() {
+	cachesOnce.Do(func() {
+		sharedData = cache.NewManager[*EnrichedItem]("tmdb.data.cache.json")
+		sharedGenres = cache.NewManager[map[int]string]("tmdb.genres.cache.json")
+		sharedLangs = cache.NewManager[map[string]string]("tmdb.languages.cache.json")
+		sharedCredits = cache.NewManager[TMDbCredits]("tmdb.credits.cache.json")
+
+		_ = sharedData.Load()
+		_ = sharedGenres.Load()
+		_ = sharedLangs.Load()
+		_ = sharedCredits.Load()
+	})
 }
 
-type creditsCache = cache.Manager[TMDbCredits]
+func newCaches() (*dataCache, *genreCache, *langCache) {
+	loadCaches()
+	return sharedData, sharedGenres, sharedLangs
+}
 
 func newCreditsCache() *creditsCache {
-	return cache.NewManager[TMDbCredits]("tmdb.credits.cache.json")
+	loadCaches()
+	return sharedCredits
 }
@@ -0,0 +1,216 @@
+package tmdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mrizkifadil26/medix/enricher/tmdb/scorer"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+// CollectionDetails is /collection/{id}: the collection and its films.
+type CollectionDetails struct {
+	ID    int          `json:"id"`
+	Name  string       `json:"name"`
+	Parts []SearchItem `json:"parts"`
+}
+
+// GetCollection fetches a collection with all its parts.
+func (c *Client) GetCollection(collectionID int) (*CollectionDetails, error) {
+	endpoint := fmt.Sprintf("%s/collection/%d", c.BaseURL, collectionID)
+
+	var result CollectionDetails
+	if err := c.doRequest(endpoint, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch collection %d: %w", collectionID, err)
+	}
+
+	return &result, nil
+}
+
+// CollectionReport is one TMDb collection the library has a film of. It
+// goes under "collections" at the root of the output when the config sets
+// check_collections.
+type CollectionReport struct {
+	TMDbID int    `json:"tmdb_id"`
+	Name   string `json:"name"`
+	Folder string `json:"folder,omitempty"` // the collection folder on disk, if there is one
+
+	Owned    []CollectionPart `json:"owned"`
+	Missing  []CollectionPart `json:"missing,omitempty"`  // released parts not in the library
+	Misfiled []CollectionPart `json:"misfiled,omitempty"` // owned parts outside Folder
+	Error    string           `json:"error,omitempty"`    // the parts could not be fetched
+}
+
+type CollectionPart struct {
+	TMDbID      int    `json:"tmdb_id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date,omitempty"`
+	Path        string `json:"path,omitempty"`   // owned parts only
+	Folder      string `json:"folder,omitempty"` // the collection folder the part is in, if any
+}
+
+// collectionEntry is what the check needs to know about one item.
+type collectionEntry struct {
+	part       CollectionPart
+	collection *TMDbCollection
+}
+
+// collectionEntryOf reads an enriched item. The folder is the second
+// level of a two-level group_label, e.g. ["Action", "Alien"].
+func collectionEntryOf(item any) (collectionEntry, bool) {
+	v, err := jsonpath.Get(item, "enriched")
+	if err != nil || v == nil {
+		return collectionEntry{}, false
+	}
+
+	// a struct when just enriched, a map when read back from a file
+	var enriched EnrichedData
+	if data, err := json.Marshal(v); err != nil || json.Unmarshal(data, &enriched) != nil {
+		return collectionEntry{}, false
+	}
+	if enriched.TMDbID == 0 {
+		return collectionEntry{}, false
+	}
+
+	entry := collectionEntry{part: CollectionPart{
+		TMDbID:      enriched.TMDbID,
+		Title:       enriched.Title,
+		ReleaseDate: enriched.ReleaseDate,
+	}}
+	if enriched.Details != nil {
+		entry.collection = enriched.Details.Collection
+	}
+
+	for _, key := range []string{"rel_path", "path"} {
+		if p, err := jsonpath.Get(item, key); err == nil {
+			if s, ok := p.(string); ok && s != "" {
+				entry.part.Path = s
+				break
+			}
+		}
+	}
+
+	if g, err := jsonpath.Get(item, "group_label"); err == nil {
+		if labels, ok := g.([]any); ok && len(labels) == 2 {
+			entry.part.Folder, _ = labels[1].(string)
+		}
+	}
+
+	return entry, true
+}
+
+// checkCollections groups the entries by TMDb collection and compares each
+// with its parts and with the folders on disk. The collection folder is
+// the one most of its films are in, or else a folder named like it.
+func (e *TMDbEnricher) checkCollections(entries []collectionEntry) []CollectionReport {
+	folders := map[string]bool{}
+	byCollection := map[int][]collectionEntry{}
+	var ids []int
+
+	for _, entry := range entries {
+		if entry.part.Folder != "" {
+			folders[entry.part.Folder] = true
+		}
+		if entry.collection == nil || entry.collection.ID == 0 {
+			continue
+		}
+
+		id := entry.collection.ID
+		if _, ok := byCollection[id]; !ok {
+			ids = append(ids, id)
+		}
+		byCollection[id] = append(byCollection[id], entry)
+	}
+	sort.Ints(ids)
+
+	today := time.Now().Format("2006-01-02")
+	reports := make([]CollectionReport, 0, len(ids))
+
+	for _, id := range ids {
+		owned := byCollection[id]
+		sort.Slice(owned, func(i, j int) bool {
+			return owned[i].part.ReleaseDate < owned[j].part.ReleaseDate
+		})
+
+		report := CollectionReport{TMDbID: id, Name: owned[0].collection.Name}
+		report.Folder = collectionFolder(report.Name, owned, folders)
+
+		have := map[int]bool{}
+		for _, entry := range owned {
+			have[entry.part.TMDbID] = true
+			report.Owned = append(report.Owned, entry.part)
+
+			if report.Folder != "" && entry.part.Folder != report.Folder {
+				report.Misfiled = append(report.Misfiled, entry.part)
+			}
+		}
+
+		collection, err := e.client.GetCollection(id)
+		if err != nil {
+			report.Error = err.Error()
+			reports = append(reports, report)
+			continue
+		}
+
+		sort.Slice(collection.Parts, func(i, j int) bool {
+			return collection.Parts[i].ReleaseDate < collection.Parts[j].ReleaseDate
+		})
+		for _, p := range collection.Parts {
+			// unreleased and undated parts cannot be missing yet
+			if have[p.ID] || p.ReleaseDate == "" || p.ReleaseDate > today {
+				continue
+			}
+
+			report.Missing = append(report.Missing, CollectionPart{
+				TMDbID:      p.ID,
+				Title:       p.Title,
+				ReleaseDate: p.ReleaseDate,
+			})
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+// collectionFolder picks the folder most of the owned films are in; on a
+// tie, or when none is in a folder, the folder whose name is closest to
+// the collection's ("Alien" for "Alien Collection").
+func collectionFolder(name string, owned []collectionEntry, folders map[string]bool) string {
+	name = strings.TrimSuffix(name, " Collection")
+
+	votes := map[string]int{}
+	for _, entry := range owned {
+		if entry.part.Folder != "" {
+			votes[entry.part.Folder]++
+		}
+	}
+
+	best, bestVotes, bestSim := "", 0, 0.0
+	consider := func(folder string, n int) {
+		sim := scorer.Similarity(folder, name)
+		if n > bestVotes || n == bestVotes && (sim > bestSim || sim == bestSim && folder < best) {
+			best, bestVotes, bestSim = folder, n, sim
+		}
+	}
+
+	for folder, n := range votes {
+		consider(folder, n)
+	}
+	if best != "" {
+		return best
+	}
+
+	for folder := range folders {
+		consider(folder, 0)
+	}
+	if bestSim < 0.9 {
+		return ""
+	}
+
+	return best
+}
@@ -0,0 +1,306 @@
+package tmdb_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/enricher/core"
+	"github.com/mrizkifadil26/medix/enricher/tmdb"
+)
+
+// the caches are files in the working directory, so the tests run in a
+// directory of their own
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "tmdb-test")
+	if err != nil {
+		panic(err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	os.Chdir(wd)
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+const alienCollection = `{
+  "id": 8091,
+  "name": "Alien Collection",
+  "parts": [
+    {"id": 126889, "title": "Alien: Covenant", "release_date": "2017-05-09"},
+    {"id": 348, "title": "Alien", "release_date": "1979-05-25"},
+    {"id": 679, "title": "Aliens", "release_date": "1986-07-18"},
+    {"id": 8077, "title": "Alien³", "release_date": "1992-05-22"},
+    {"id": 945961, "title": "Alien: Someday", "release_date": "2999-08-16"},
+    {"id": 1000001, "title": "Alien: Untitled", "release_date": ""}
+  ]
+}`
+
+const alienMovie = `{
+  "id": 348,
+  "title": "Alien",
+  "original_title": "Alien",
+  "release_date": "1979-05-25",
+  "original_language": "en",
+  "genres": [{"id": 27, "name": "Horror"}],
+  "runtime": 117,
+  "tagline": "In space no one can hear you scream.",
+  "imdb_id": "tt0078748",
+  "production_countries": [{"iso_3166_1": "US", "name": "United States of America"}, {"iso_3166_1": "GB", "name": "United Kingdom"}],
+  "belongs_to_collection": {"id": 8091, "name": "Alien Collection"}
+}`
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	responses := map[string]string{
+		"/collection/8091":         alienCollection,
+		"/movie/348":               alienMovie,
+		"/configuration/languages": `[{"iso_639_1": "en", "english_name": "English"}]`,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// enrichAll streams items through a new enricher and returns the
+// collections reported by Finish.
+func enrichAll(t *testing.T, cfg *tmdb.Config, items []any, opts core.Options) []tmdb.CollectionReport {
+	t.Helper()
+
+	e := tmdb.NewTMDbEnricher(cfg)
+	for _, item := range items {
+		if err := e.EnrichItem(context.Background(), item, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := e.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, _ := keys["collections"].([]tmdb.CollectionReport)
+	return reports
+}
+
+// owned is an item already enriched as a part of the given collection (0
+// for none), in the folders of group.
+func owned(slug string, tmdbID int, title, date string, collection int, group ...string) map[string]any {
+	labels := make([]any, len(group))
+	for i, g := range group {
+		labels[i] = g
+	}
+
+	enriched := map[string]any{"tmdb_id": float64(tmdbID), "title": title, "release_date": date}
+	if collection != 0 {
+		enriched["details"] = map[string]any{
+			"collection": map[string]any{"id": float64(collection), "name": "Alien Collection"},
+		}
+	}
+
+	return map[string]any{
+		"slug":        slug,
+		"rel_path":    title,
+		"group_label": labels,
+		"enriched":    enriched,
+	}
+}
+
+func TestCheckCollections(t *testing.T) {
+	srv := newServer(t)
+	cfg := &tmdb.Config{APIKey: "test", BaseURL: srv.URL, CheckCollections: true}
+
+	// as read back from a file
+	var alien map[string]any
+	if err := json.Unmarshal([]byte(`{
+	  "slug": "alien-1979",
+	  "rel_path": "Horror/Alien/Alien (1979)",
+	  "group_label": ["Horror", "Alien"],
+	  "enriched": {"tmdb_id": 348, "title": "Alien", "release_date": "1979-05-25",
+	    "details": {"collection": {"id": 8091, "name": "Alien Collection"}}}
+	}`), &alien); err != nil {
+		t.Fatal(err)
+	}
+
+	// as just set by the enricher
+	aliens := map[string]any{
+		"slug":        "aliens-1986",
+		"rel_path":    "Horror/Alien/Aliens (1986)",
+		"group_label": []any{"Horror", "Alien"},
+		"enriched": &tmdb.EnrichedData{
+			TMDbID:      679,
+			Title:       "Aliens",
+			ReleaseDate: "1986-07-18",
+			Details:     &tmdb.TMDbDetails{Collection: &tmdb.TMDbCollection{ID: 8091, Name: "Alien Collection"}},
+		},
+	}
+
+	items := []any{
+		owned("alien-3-1992", 8077, "Alien³", "1992-05-22", 8091, "Horror", "Misc"),
+		aliens,
+		alien,
+		owned("broken-2001", 5, "Broken", "2001-01-01", 404, "Drama"),
+		owned("heat-1995", 949, "Heat", "1995-12-15", 0, "Crime"),
+	}
+
+	reports := enrichAll(t, cfg, items, core.Options{Resume: true})
+	if len(reports) != 2 {
+		t.Fatalf("reports = %+v, want the Alien collection and the broken one", reports)
+	}
+
+	broken := reports[0]
+	if broken.TMDbID != 404 || broken.Error == "" || len(broken.Owned) != 1 {
+		t.Errorf("failed fetch = %+v, want its owned part and an error", broken)
+	}
+
+	report := reports[1]
+	if report.Name != "Alien Collection" || report.Folder != "Alien" {
+		t.Errorf("report = %q in %q, want Alien Collection in Alien", report.Name, report.Folder)
+	}
+
+	ids := func(parts []tmdb.CollectionPart) []int {
+		var out []int
+		for _, p := range parts {
+			out = append(out, p.TMDbID)
+		}
+		return out
+	}
+
+	// owned by release date; undated and future parts are not missing
+	if got := ids(report.Owned); !reflect.DeepEqual(got, []int{348, 679, 8077}) {
+		t.Errorf("owned = %v", got)
+	}
+	if got := ids(report.Missing); !reflect.DeepEqual(got, []int{126889}) {
+		t.Errorf("missing = %v, want only Alien: Covenant", got)
+	}
+	if got := ids(report.Misfiled); !reflect.DeepEqual(got, []int{8077}) {
+		t.Errorf("misfiled = %v, want Alien³ in Misc", got)
+	}
+	if len(report.Misfiled) == 1 && (report.Misfiled[0].Folder != "Misc" || report.Misfiled[0].Path != "Alien³") {
+		t.Errorf("misfiled part = %+v", report.Misfiled[0])
+	}
+}
+
+func TestCheckCollections_Folder(t *testing.T) {
+	srv := newServer(t)
+	cfg := &tmdb.Config{APIKey: "test", BaseURL: srv.URL, CheckCollections: true}
+
+	tests := []struct {
+		name   string
+		parts  [][]string // group_label of each owned part
+		others [][]string // group_label of items outside the collection
+		want   string
+	}{
+		{
+			name:  "most films, whatever the name",
+			parts: [][]string{{"Horror", "Alien"}, {"Horror", "Ridley Scott"}, {"Horror", "Ridley Scott"}},
+			want:  "Ridley Scott",
+		},
+		{
+			name:  "tie goes to the closest name",
+			parts: [][]string{{"Horror", "Sci-Fi"}, {"Horror", "Alien Films"}},
+			want:  "Alien Films",
+		},
+		{
+			name:   "none in a folder, one named like the collection",
+			parts:  [][]string{{"Horror"}, {"Horror"}},
+			others: [][]string{{"Action", "Predator"}, {"Horror", "Alien"}},
+			want:   "Alien",
+		},
+		{
+			name:   "none in a folder, none alike",
+			parts:  [][]string{{"Horror"}},
+			others: [][]string{{"Action", "Predator"}},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []any
+			for i, group := range tt.parts {
+				items = append(items, owned(tt.name+"-part", 348+i, "Part", "1979-05-25", 8091, group...))
+			}
+			for i, group := range tt.others {
+				items = append(items, owned(tt.name+"-other", 2000+i, "Other", "1990-01-01", 0, group...))
+			}
+
+			reports := enrichAll(t, cfg, items, core.Options{Resume: true})
+			if len(reports) != 1 {
+				t.Fatalf("reports = %+v", reports)
+			}
+			if reports[0].Folder != tt.want {
+				t.Errorf("folder = %q, want %q", reports[0].Folder, tt.want)
+			}
+			if tt.want == "" && len(reports[0].Misfiled) != 0 {
+				t.Errorf("misfiled without a folder: %+v", reports[0].Misfiled)
+			}
+		})
+	}
+}
+
+func TestCheckCollections_FetchesDetails(t *testing.T) {
+	srv := newServer(t)
+
+	item := func(slug string) map[string]any {
+		return map[string]any{
+			"slug":        slug,
+			"rel_path":    "Horror/Alien/Alien (1979)",
+			"group_label": []any{"Horror", "Alien"},
+			"metadata":    map[string]any{"title": "Alien", "year": "1979"},
+			"nfo":         map[string]any{"tmdb_id": float64(348)},
+		}
+	}
+
+	// check_collections needs the collection, so it implies fetch_details
+	cfg := &tmdb.Config{APIKey: "test", BaseURL: srv.URL, UseNFO: true, CheckCollections: true}
+	alien := item("alien-1979-details")
+	reports := enrichAll(t, cfg, []any{alien}, core.Options{})
+
+	data, _ := alien["enriched"].(*tmdb.EnrichedData)
+	if data == nil || data.Details == nil {
+		t.Fatalf("enriched = %+v, want details", alien["enriched"])
+	}
+	want := tmdb.TMDbDetails{
+		Runtime:    117,
+		Tagline:    "In space no one can hear you scream.",
+		IMDbID:     "tt0078748",
+		Countries:  []string{"US", "GB"},
+		Collection: &tmdb.TMDbCollection{ID: 8091, Name: "Alien Collection"},
+	}
+	if !reflect.DeepEqual(*data.Details, want) {
+		t.Errorf("details = %+v, want %+v", *data.Details, want)
+	}
+	if len(reports) != 1 || reports[0].TMDbID != 8091 || len(reports[0].Owned) != 1 {
+		t.Errorf("reports = %+v", reports)
+	}
+
+	// without either option the details are left out
+	cfg = &tmdb.Config{APIKey: "test", BaseURL: srv.URL, UseNFO: true}
+	alien = item("alien-1979-plain")
+	enrichAll(t, cfg, []any{alien}, core.Options{})
+
+	data, _ = alien["enriched"].(*tmdb.EnrichedData)
+	if data == nil || data.TMDbID != 348 || data.Details != nil {
+		t.Errorf("enriched = %+v, want the movie without details", data)
+	}
+}
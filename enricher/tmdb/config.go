@@ -1,8 +1,62 @@
 package tmdb
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// APIKeyEnv is read when the config names neither api_key nor api_key_file.
+const APIKeyEnv = "TMDB_API_KEY"
+
+// Config is the "config" object of a tmdb entry in options.enrichers.
+// Unknown keys are rejected.
 type Config struct {
-	APIKey               string `json:"api_key"`
+	APIKey               string `json:"api_key,omitempty"`               // usually "${TMDB_API_KEY}"
+	APIKeyFile           string `json:"api_key_file,omitempty"`          // file holding the token, e.g. ~/.config/medix/tmdb.token
 	FetchCredits         bool   `json:"fetch_credits,omitempty"`         // optional, default false
 	FetchRecommendations bool   `json:"fetch_recommendations,omitempty"` // optional, default false
 	FetchSimilar         bool   `json:"fetch_similar,omitempty"`         // optional, default false
+	UseNFO               bool   `json:"use_nfo,omitempty"`               // look up the IDs found by the local nfo filter
+	FetchDetails         bool   `json:"fetch_details,omitempty"`         // runtime, tagline, IMDb ID, countries, collection
+	CheckCollections     bool   `json:"check_collections,omitempty"`     // report collections against the folders; implies fetch_details
+	BaseURL              string `json:"base_url,omitempty"`              // API root, default https://api.themoviedb.org/3
+}
+
+// ResolveAPIKey fills APIKey from, in order, the config itself, APIKeyFile
+// and the TMDB_API_KEY environment variable.
+func (c *Config) ResolveAPIKey() error {
+	if c.APIKey != "" && c.APIKeyFile != "" {
+		return fmt.Errorf("tmdb: set api_key or api_key_file, not both")
+	}
+
+	if c.APIKeyFile != "" {
+		path := c.APIKeyFile
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("tmdb: read api_key_file: %w", err)
+		}
+
+		c.APIKey = strings.TrimSpace(string(data))
+		if c.APIKey == "" {
+			return fmt.Errorf("tmdb: api_key_file %s is empty", c.APIKeyFile)
+		}
+	}
+
+	if c.APIKey == "" {
+		c.APIKey = os.Getenv(APIKeyEnv)
+	}
+
+	if c.APIKey == "" {
+		return fmt.Errorf("tmdb: no API key; set %s, api_key or api_key_file", APIKeyEnv)
+	}
+
+	return nil
 }
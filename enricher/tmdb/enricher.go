@@ -1,11 +1,16 @@
 package tmdb
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 
+	"github.com/mrizkifadil26/medix/enricher/core"
 	"github.com/mrizkifadil26/medix/enricher/tmdb/scorer"
+	"github.com/mrizkifadil26/medix/utils/concurrency"
 	"github.com/mrizkifadil26/medix/utils/jsonpath"
 )
 
@@ -17,19 +22,22 @@ type TMDbEnricher struct {
 	langService   *LanguageService
 	creditService *CreditService
 	config        *Config
+
+	// streaming state, see EnrichItem
+	streamMu     sync.Mutex
+	streamErrors map[string]string
+	progress     *Progress
+	collections  []collectionEntry // for check_collections
 }
 
 func NewTMDbEnricher(cfg *Config) *TMDbEnricher {
 	client := NewClient(cfg.APIKey)
+	if cfg.BaseURL != "" {
+		client.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	}
 	dataCache, genreCache, langCache := newCaches()
 	creditsCache := newCreditsCache()
 
-	// Load caches at startup (best effort)
-	_ = dataCache.Load()
-	_ = genreCache.Load()
-	_ = langCache.Load()
-	_ = creditsCache.Load()
-
 	return &TMDbEnricher{
 		client:        client,
 		dataCache:     dataCache,
@@ -42,50 +50,71 @@ func NewTMDbEnricher(cfg *Config) *TMDbEnricher {
 
 func (t *TMDbEnricher) Name() string { return "tmdb" }
 
+// DependsOn makes tmdb wait for the local enricher when it should use the
+// IDs from NFO files.
+func (t *TMDbEnricher) DependsOn() []string {
+	if t.config.UseNFO {
+		return []string{"local"}
+	}
+
+	return nil
+}
+
+// Enrich looks up every item with a title on TMDb, opts.Concurrency at a
+// time (default 5). On cancellation no new lookups start; the results so
+// far are still written and cached.
 func (t *TMDbEnricher) Enrich(
+	ctx context.Context,
 	data any,
-	options map[string]string,
-) (any, error) {
-	queries, err := extractQueries(data)
+	opts core.Options,
+) error {
+	unlock := opts.Locks.Item(core.Root)
+	items, err := jsonpath.Query(data, "items.#")
 	if err != nil {
-		return nil, err
+		unlock()
+		return fmt.Errorf("failed to get items: %w", err)
 	}
-
 	itemCount, err := jsonpath.Get(data, "item_count")
+	unlock()
+	if err != nil {
+		return fmt.Errorf("item count not found: %w", err)
+	}
+
+	queries, skipped, err := extractQueries(items, opts)
 	if err != nil {
-		return nil, fmt.Errorf("item count not found: %w", err)
+		return err
 	}
 
 	total := int32(asInt(itemCount))
+	if opts.Resume {
+		fmt.Printf("⏩ Resuming: %d items already enriched\n", skipped)
+		total = int32(len(queries))
+	}
 	progress := &Progress{total: total}
 
-	concurrency := parseConcurrency(options, 5)
-
 	var (
 		wg       sync.WaitGroup
-		sem      = make(chan struct{}, concurrency)
 		errorsMu sync.Mutex
-		jsonMu   sync.RWMutex
 		errors   = make(map[string]string)
+		exec     = concurrency.GoroutineExecutor(opts.ConcurrencyOr(5))
 	)
 
-	for idx := range queries {
+	for _, query := range queries {
 		wg.Add(1)
 
-		go func(idx int) {
+		err := exec(ctx, func(ctx context.Context) error {
 			defer wg.Done()
-			sem <- struct{}{} // acquire slot
-			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return nil
+			}
 
-			result := t.enrichItem(queries[idx], idx)
+			result := t.enrichItem(query, query.Index)
 
-			// Set JSON for enriched data
 			// Only set enriched if not nil
 			if result.Enriched != nil {
-				jsonMu.Lock()
-				// _ = jsonpath.Set(data, fmt.Sprintf("items.%d.enriched", idx), result.Enriched)
-				_ = jsonpath.Set(data, fmt.Sprintf("items.%d.enriched", idx), result.Enriched)
-				jsonMu.Unlock()
+				unlock := opts.Locks.Item(query.Index)
+				_ = jsonpath.Set(items[query.Index], "enriched", result.Enriched)
+				unlock()
 			}
 
 			// Collect errors
@@ -95,23 +124,138 @@ func (t *TMDbEnricher) Enrich(
 				errorsMu.Unlock()
 			}
 
-			display := result.Title
-			if result.Year != "" {
-				display = fmt.Sprintf("%s (%s)", result.Title, result.Year)
-			}
-
-			progress.Inc(display, result.Error, result.Source)
-		}(idx)
+			progress.Inc(result.display(), result.Error, result.Source)
+			return nil
+		})
+		if err != nil {
+			// cancelled before the lookup could start
+			wg.Done()
+			break
+		}
 	}
 
 	wg.Wait()
 
+	unlock = opts.Locks.Item(core.Root)
 	_ = jsonpath.Set(data, "errors", errors)
+	unlock()
+
+	if t.config.CheckCollections && ctx.Err() == nil {
+		var entries []collectionEntry
+		for i, item := range items {
+			unlock := opts.Locks.Item(i)
+			entry, ok := collectionEntryOf(item)
+			unlock()
+			if ok {
+				entries = append(entries, entry)
+			}
+		}
+
+		reports := t.checkCollections(entries)
+		unlock := opts.Locks.Item(core.Root)
+		_ = jsonpath.Set(data, "collections", reports)
+		unlock()
+	}
+
+	// Save data cache (best-effort)
+	_ = t.dataCache.Save()
+
+	return ctx.Err()
+}
+
+// EnrichItem enriches a single item in place. Errors are collected and
+// written by Finish, as Enrich does for the whole document.
+func (t *TMDbEnricher) EnrichItem(
+	ctx context.Context,
+	item any,
+	opts core.Options,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if opts.Resume && hasEnriched(item) {
+		t.recordCollection(item)
+		return nil
+	}
+
+	query, err := queryFromItem(item, -1)
+	if err != nil {
+		return err
+	}
+
+	// same as Enrich: items without a title are left alone
+	if query.Title == "" {
+		return nil
+	}
+
+	t.streamMu.Lock()
+	if t.progress == nil {
+		t.progress = &Progress{}
+	}
+	progress := t.progress
+	t.streamMu.Unlock()
+
+	result := t.enrichItem(query, query.Index)
+	if result.Enriched != nil {
+		if err := jsonpath.Set(item, "enriched", result.Enriched); err != nil {
+			return err
+		}
+	}
+
+	if result.Error != "" {
+		t.streamMu.Lock()
+		if t.streamErrors == nil {
+			t.streamErrors = make(map[string]string)
+		}
+		t.streamErrors[result.Title] = result.Error
+		t.streamMu.Unlock()
+	}
+
+	t.recordCollection(item)
+
+	progress.Inc(result.display(), result.Error, result.Source)
+	return nil
+}
+
+// recordCollection keeps what Finish needs to check the item's collection.
+func (t *TMDbEnricher) recordCollection(item any) {
+	if !t.config.CheckCollections {
+		return
+	}
+
+	if entry, ok := collectionEntryOf(item); ok {
+		t.streamMu.Lock()
+		t.collections = append(t.collections, entry)
+		t.streamMu.Unlock()
+	}
+}
+
+// Checkpoint saves the data cache, so lookups done so far survive a crash.
+func (t *TMDbEnricher) Checkpoint() error {
+	return t.dataCache.Save()
+}
 
+// Finish saves the data cache and reports the errors collected by
+// EnrichItem and, with check_collections, the collections.
+func (t *TMDbEnricher) Finish() (map[string]any, error) {
 	// Save data cache (best-effort)
 	_ = t.dataCache.Save()
 
-	return data, nil
+	t.streamMu.Lock()
+	defer t.streamMu.Unlock()
+
+	errors := t.streamErrors
+	if errors == nil {
+		errors = map[string]string{}
+	}
+
+	keys := map[string]any{"errors": errors}
+	if t.config.CheckCollections {
+		keys["collections"] = t.checkCollections(t.collections)
+	}
+
+	return keys, nil
 }
 
 func (e *TMDbEnricher) enrichItem(
@@ -136,10 +280,35 @@ func (e *TMDbEnricher) enrichItem(
 		return item
 	}
 
+	useNFO := e.config.UseNFO && query.Kind != "tvshow"
+
 	// --- Try cache first ---
-	if cached, ok := e.dataCache.Get("movie", slug); ok && cached != nil {
-		cached.Source = "cache"
-		return cached
+	// (unless the NFO names a different movie than the cached match)
+	if cached, ok := e.dataCache.Get("movie", slug); ok && cached != nil &&
+		(!useNFO || query.TMDbID == 0 || cached.Enriched != nil && cached.Enriched.TMDbID == query.TMDbID) {
+		// the cached item is shared with Checkpoint's saves; change a copy
+		hit := *cached
+		hit.Source = "cache"
+		if cached.Enriched != nil {
+			enriched := *cached.Enriched
+			hit.Enriched = &enriched
+		}
+		if e.addExtras(hit.Enriched) {
+			e.dataCache.Put("movie", slug, &hit)
+		}
+		return &hit
+	}
+
+	// --- Known IDs skip the search; a stale one falls back to it ---
+	if useNFO {
+		if data, err := e.lookupByID(query); err == nil && data != nil {
+			item.Enriched = data
+			e.addExtras(item.Enriched)
+
+			item.Source = "nfo"
+			e.dataCache.Put("movie", slug, item)
+			return item
+		}
 	}
 
 	search := SearchQuery{Query: title}
@@ -190,6 +359,8 @@ func (e *TMDbEnricher) enrichItem(
 		return item
 	}
 
+	e.addAlternativeTitles(results, title)
+
 	best := scorer.PickBestMatch(results, title, yearInt, genreMap)
 	if best == nil {
 		item.Error = "no good match found"
@@ -221,11 +392,7 @@ func (e *TMDbEnricher) enrichItem(
 		Overview:      best.Overview,
 	}
 
-	if e.config.FetchCredits {
-		if credits, err := e.creditService.FetchCredits(best.ID); err == nil {
-			item.Enriched.Credits = &credits
-		}
-	}
+	e.addExtras(item.Enriched)
 
 	item.Source = "remote" // mark it came from remote fetch
 	if slug != "" {
@@ -235,59 +402,96 @@ func (e *TMDbEnricher) enrichItem(
 	return item
 }
 
-func extractQueries(data any) ([]QueryInput, error) {
-	items, err := jsonpath.Get(data, "items.#")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get items: %w", err)
-	}
+// extractQueries returns the lookups to run and how many items were
+// skipped because they are already enriched (resume only).
+func extractQueries(items []any, opts core.Options) ([]QueryInput, int, error) {
+	var (
+		queries []QueryInput
+		skipped int
+	)
 
-	arr, ok := items.([]any)
-	if !ok {
-		return nil, fmt.Errorf("expected array of items, got %T", items)
+	for i, item := range items {
+		unlock := opts.Locks.Item(i)
+		done := opts.Resume && hasEnriched(item)
+		q, err := queryFromItem(item, i)
+		unlock()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if done {
+			skipped++
+			continue
+		}
+
+		// only append if title exists
+		if q.Title != "" {
+			queries = append(queries, q)
+		}
 	}
 
-	var queries []QueryInput
-	for i, item := range arr {
-		q := QueryInput{}
+	return queries, skipped, nil
+}
 
-		// slug (error if missing)
-		if v, err := jsonpath.Get(item, "slug"); err == nil {
-			if s, ok := v.(string); ok && s != "" {
-				q.Slug = s
-			}
+func hasEnriched(item any) bool {
+	v, err := jsonpath.Get(item, "enriched")
+	return err == nil && v != nil
+}
+
+// queryFromItem reads the search input of the item at index i; the slug is
+// required.
+func queryFromItem(item any, i int) (QueryInput, error) {
+	q := QueryInput{Index: i}
+
+	// slug (error if missing)
+	if v, err := jsonpath.Get(item, "slug"); err == nil {
+		if s, ok := v.(string); ok && s != "" {
+			q.Slug = s
 		}
-		if q.Slug == "" {
-			return nil, fmt.Errorf("missing slug for item at index %d", i)
+	}
+	if q.Slug == "" {
+		if i < 0 {
+			return q, fmt.Errorf("missing slug")
 		}
+		return q, fmt.Errorf("missing slug for item at index %d", i)
+	}
 
-		// title
-		if v, err := jsonpath.Get(item, "metadata.title"); err == nil {
-			if s, ok := v.(string); ok {
-				q.Title = s
-			}
+	// title
+	if v, err := jsonpath.Get(item, "metadata.title"); err == nil {
+		if s, ok := v.(string); ok {
+			q.Title = s
 		}
+	}
 
-		// year
-		if v, err := jsonpath.Get(item, "metadata.year"); err == nil {
-			if s, ok := v.(string); ok {
-				q.Year = s
-			}
+	// year
+	if v, err := jsonpath.Get(item, "metadata.year"); err == nil {
+		if s, ok := v.(string); ok {
+			q.Year = s
 		}
+	}
 
-		// alternate title
-		if v, err := jsonpath.Get(item, "metadata.alternate_title"); err == nil {
-			if s, ok := v.(string); ok {
-				q.AlternateTitle = s
-			}
+	// alternate title
+	if v, err := jsonpath.Get(item, "metadata.alternate_title"); err == nil {
+		if s, ok := v.(string); ok {
+			q.AlternateTitle = s
 		}
+	}
 
-		// only append if title exists
-		if q.Title != "" {
-			queries = append(queries, q)
+	// IDs recorded by the local nfo filter
+	if v, err := jsonpath.Get(item, "nfo"); err == nil && v != nil {
+		var ids struct {
+			Kind   string `json:"kind"`
+			TMDbID int    `json:"tmdb_id"`
+			IMDbID string `json:"imdb_id"`
+		}
+
+		// a map when read from a file, a struct when local just set it
+		if data, err := json.Marshal(v); err == nil && json.Unmarshal(data, &ids) == nil {
+			q.Kind, q.TMDbID, q.IMDbID = ids.Kind, ids.TMDbID, ids.IMDbID
 		}
 	}
 
-	return queries, nil
+	return q, nil
 }
 
 func asInt(v any) int {
@@ -300,13 +504,3 @@ func asInt(v any) int {
 		return 0
 	}
 }
-
-func parseConcurrency(options map[string]string, fallback int) int {
-	if c, ok := options["concurrency"]; ok {
-		if v, _ := strconv.Atoi(c); v > 0 {
-			return v
-		}
-	}
-
-	return fallback
-}
@@ -0,0 +1,29 @@
+package tmdb
+
+import (
+	"fmt"
+
+	"github.com/mrizkifadil26/medix/enricher"
+	"github.com/mrizkifadil26/medix/enricher/core"
+)
+
+func init() {
+	enricher.Register("tmdb", newFromConfig)
+}
+
+func newFromConfig(entry enricher.EnricherConfig) (core.Enricher, error) {
+	if len(entry.Filters) > 0 {
+		return nil, fmt.Errorf("filters are not supported")
+	}
+
+	var cfg Config
+	if err := entry.Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.ResolveAPIKey(); err != nil {
+		return nil, err
+	}
+
+	return NewTMDbEnricher(&cfg), nil
+}
@@ -0,0 +1,176 @@
+package tmdb
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/mrizkifadil26/medix/enricher/tmdb/scorer"
+)
+
+// MovieDetails is the part of /movie/{id} we use. Unlike search results it
+// carries the genre names.
+type MovieDetails struct {
+	ID               int         `json:"id"`
+	Title            string      `json:"title"`
+	OriginalTitle    string      `json:"original_title"`
+	Overview         string      `json:"overview"`
+	PosterPath       string      `json:"poster_path"`
+	ReleaseDate      string      `json:"release_date"`
+	OriginalLanguage string      `json:"original_language"`
+	Genres           []GenreItem `json:"genres"`
+
+	Runtime             int             `json:"runtime"` // minutes
+	Tagline             string          `json:"tagline"`
+	IMDbID              string          `json:"imdb_id"`
+	ProductionCountries []Country       `json:"production_countries"`
+	BelongsToCollection *TMDbCollection `json:"belongs_to_collection"`
+}
+
+type Country struct {
+	Code string `json:"iso_3166_1"`
+	Name string `json:"name"`
+}
+
+type alternativeTitles struct {
+	Titles []struct {
+		Country string `json:"iso_3166_1"`
+		Title   string `json:"title"`
+	} `json:"titles"`
+}
+
+type findResult struct {
+	MovieResults []SearchItem `json:"movie_results"`
+}
+
+// GetMovie fetches a movie by its TMDb ID.
+func (c *Client) GetMovie(tmdbID int) (*MovieDetails, error) {
+	if tmdbID == 0 {
+		return nil, fmt.Errorf("invalid TMDbID")
+	}
+
+	endpoint := fmt.Sprintf("%s/movie/%d", c.BaseURL, tmdbID)
+
+	var result MovieDetails
+	if err := c.doRequest(endpoint, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch movie %d: %w", tmdbID, err)
+	}
+
+	return &result, nil
+}
+
+// GetAlternativeTitles lists the titles a movie was released under in
+// other countries.
+func (c *Client) GetAlternativeTitles(tmdbID int) ([]string, error) {
+	endpoint := fmt.Sprintf("%s/movie/%d/alternative_titles", c.BaseURL, tmdbID)
+
+	var result alternativeTitles
+	if err := c.doRequest(endpoint, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch alternative titles of %d: %w", tmdbID, err)
+	}
+
+	titles := make([]string, 0, len(result.Titles))
+	for _, t := range result.Titles {
+		titles = append(titles, t.Title)
+	}
+
+	return titles, nil
+}
+
+// FindByIMDb resolves an IMDb ID such as tt0078748 to the TMDb movie.
+func (c *Client) FindByIMDb(imdbID string) (int, error) {
+	endpoint := fmt.Sprintf("%s/find/%s", c.BaseURL, url.PathEscape(imdbID))
+	params := url.Values{"external_source": {"imdb_id"}}
+
+	var result findResult
+	if err := c.doRequest(endpoint, params, &result); err != nil {
+		return 0, fmt.Errorf("failed to find %s: %w", imdbID, err)
+	}
+
+	if len(result.MovieResults) == 0 {
+		return 0, fmt.Errorf("no movie for %s", imdbID)
+	}
+
+	return result.MovieResults[0].ID, nil
+}
+
+// lookupByID fetches the movie named by the item's NFO instead of
+// searching by title. It returns nil without error when there is no ID.
+func (e *TMDbEnricher) lookupByID(query QueryInput) (*EnrichedData, error) {
+	id := query.TMDbID
+	if id == 0 {
+		if query.IMDbID == "" {
+			return nil, nil
+		}
+
+		found, err := e.client.FindByIMDb(query.IMDbID)
+		if err != nil {
+			return nil, err
+		}
+		id = found
+	}
+
+	movie, err := e.client.GetMovie(id)
+	if err != nil {
+		return nil, err
+	}
+
+	genres := make([]string, 0, len(movie.Genres))
+	for _, g := range movie.Genres {
+		genres = append(genres, g.Name)
+	}
+
+	langName, err := e.langService.Resolve(movie.OriginalLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve language: %w", err)
+	}
+
+	data := &EnrichedData{
+		TMDbID:        movie.ID,
+		Title:         movie.Title,
+		OriginalTitle: movie.OriginalTitle,
+		ReleaseDate:   movie.ReleaseDate,
+		Genres:        genres,
+		Language:      langName,
+		PosterPath:    movie.PosterPath,
+		Overview:      movie.Overview,
+	}
+
+	// the details come with the lookup, no need to fetch them again
+	if e.wantDetails() {
+		data.Details = detailsOf(movie)
+	}
+
+	return data, nil
+}
+
+// altTitleCandidates is how many foreign search results get their
+// alternative titles fetched when no result matches the title outright.
+const altTitleCandidates = 3
+
+// addAlternativeTitles fetches the alternative titles of the first few
+// non-English results, so a film searched under its local or English name
+// can score against it. It does nothing when a result already matches.
+func (e *TMDbEnricher) addAlternativeTitles(results []SearchItem, title string) {
+	for _, r := range results {
+		if scorer.TitleMatch(r, title) == 1 {
+			return
+		}
+	}
+
+	fetched := 0
+	for i := range results {
+		if fetched == altTitleCandidates {
+			break
+		}
+		if results[i].OriginalLanguage == "en" {
+			continue
+		}
+
+		fetched++
+		titles, err := e.client.GetAlternativeTitles(results[i].ID)
+		if err != nil {
+			continue // scored without them
+		}
+		results[i].AlternativeTitles = titles
+	}
+}
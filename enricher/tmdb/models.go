@@ -1,10 +1,18 @@
 package tmdb
 
+import "fmt"
+
 type QueryInput struct {
+	Index          int // position in items
 	Slug           string
 	Title          string
 	Year           string
 	AlternateTitle string
+
+	// from the item's NFO, used when the config sets use_nfo
+	Kind   string // "movie" or "tvshow"
+	TMDbID int
+	IMDbID string
 }
 
 type EnrichedItem struct {
@@ -30,6 +38,7 @@ type EnrichedData struct {
 	PosterPath    string   `json:"poster_path,omitempty"`
 	Overview      string   `json:"overview,omitempty"`
 
+	Details         *TMDbDetails `json:"details,omitempty"`
 	Credits         *TMDbCredits `json:"credits,omitempty"`
 	Recommendations []TMDbMain   `json:"recommendations,omitempty"`
 	Similar         []TMDbMain   `json:"similar,omitempty"`
@@ -46,6 +55,21 @@ type TMDbMain struct {
 	Overview      string   `json:"overview,omitempty"`
 }
 
+// TMDbDetails is the part of /movie/{id} a search result does not have.
+type TMDbDetails struct {
+	Runtime    int             `json:"runtime,omitempty"` // minutes
+	Tagline    string          `json:"tagline,omitempty"`
+	IMDbID     string          `json:"imdb_id,omitempty"`
+	Countries  []string        `json:"countries,omitempty"` // ISO 3166-1 codes
+	Collection *TMDbCollection `json:"collection,omitempty"`
+}
+
+type TMDbCollection struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	PosterPath string `json:"poster_path,omitempty"`
+}
+
 type TMDbCredits struct {
 	Actors    []Person `json:"actors,omitempty"`
 	Directors []Person `json:"directors,omitempty"`
@@ -57,3 +81,12 @@ type Person struct {
 	Name string `json:"name"` // human-readable
 	Role string `json:"role"` // optional: "actor", "director", "producer", etc.
 }
+
+// display is the progress label for an item, e.g. "Alien (1979)".
+func (e *EnrichedItem) display() string {
+	if e.Year != "" {
+		return fmt.Sprintf("%s (%s)", e.Title, e.Year)
+	}
+
+	return e.Title
+}
@@ -26,6 +26,12 @@ func (p *Progress) Inc(title, errMessage, source string) {
 	// always include source
 	displayTitle = fmt.Sprintf("%s [%s]", displayTitle, source)
 
+	// streaming does not know the total up front
+	if p.total <= 0 {
+		fmt.Printf("[%d] %s %s\n", newVal, status, displayTitle)
+		return
+	}
+
 	percent := float64(newVal) / float64(p.total) * 100
 
 	fmt.Printf("[%d/%d %.1f%%] %s %s\n",
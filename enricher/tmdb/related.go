@@ -0,0 +1,109 @@
+package tmdb
+
+import "fmt"
+
+// GetMovieList fetches one of the per-movie lists, "recommendations" or
+// "similar". Only the first page is used.
+func (c *Client) GetMovieList(tmdbID int, list string) ([]SearchItem, error) {
+	if tmdbID == 0 {
+		return nil, fmt.Errorf("invalid TMDbID")
+	}
+
+	endpoint := fmt.Sprintf("%s/movie/%d/%s", c.BaseURL, tmdbID, list)
+
+	var result SearchResult
+	if err := c.doRequest(endpoint, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", list, err)
+	}
+
+	return result.Results, nil
+}
+
+// addExtras fetches the optional details, credits, recommendations and
+// similar titles the config asks for and that data does not have yet, so items
+// served from the cache pick them up too. Failures leave the field empty,
+// as they only decorate a match. It reports whether anything was added.
+func (e *TMDbEnricher) addExtras(data *EnrichedData) bool {
+	if data == nil || data.TMDbID == 0 {
+		return false
+	}
+
+	added := false
+
+	if e.wantDetails() && data.Details == nil {
+		if movie, err := e.client.GetMovie(data.TMDbID); err == nil {
+			data.Details = detailsOf(movie)
+			added = true
+		}
+	}
+
+	if e.config.FetchCredits && data.Credits == nil {
+		if credits, err := e.creditService.FetchCredits(data.TMDbID); err == nil {
+			data.Credits = &credits
+			added = true
+		}
+	}
+
+	if e.config.FetchRecommendations && data.Recommendations == nil {
+		if titles, err := e.relatedTitles(data.TMDbID, "recommendations"); err == nil {
+			data.Recommendations = titles
+			added = true
+		}
+	}
+
+	if e.config.FetchSimilar && data.Similar == nil {
+		if titles, err := e.relatedTitles(data.TMDbID, "similar"); err == nil {
+			data.Similar = titles
+			added = true
+		}
+	}
+
+	return added
+}
+
+func (e *TMDbEnricher) relatedTitles(tmdbID int, list string) ([]TMDbMain, error) {
+	results, err := e.client.GetMovieList(tmdbID, list)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make([]TMDbMain, 0, len(results))
+	for _, r := range results {
+		genres, _ := e.genreService.Resolve("movie", r.GenreIDs)
+		language, _ := e.langService.Resolve(r.OriginalLanguage)
+
+		titles = append(titles, TMDbMain{
+			TMDbID:        r.ID,
+			Title:         r.Title,
+			OriginalTitle: r.OriginalTitle,
+			ReleaseDate:   r.ReleaseDate,
+			Genres:        genres,
+			Language:      language,
+			PosterPath:    r.PosterPath,
+			Overview:      r.Overview,
+		})
+	}
+
+	return titles, nil
+}
+
+// wantDetails reports whether items get the /movie/{id} details, which the
+// collection check needs.
+func (e *TMDbEnricher) wantDetails() bool {
+	return e.config.FetchDetails || e.config.CheckCollections
+}
+
+func detailsOf(movie *MovieDetails) *TMDbDetails {
+	details := &TMDbDetails{
+		Runtime:    movie.Runtime,
+		Tagline:    movie.Tagline,
+		IMDbID:     movie.IMDbID,
+		Collection: movie.BelongsToCollection,
+	}
+
+	for _, c := range movie.ProductionCountries {
+		details.Countries = append(details.Countries, c.Code)
+	}
+
+	return details
+}
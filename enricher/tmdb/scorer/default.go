@@ -6,6 +6,7 @@ var DefaultConfig = &ScoreConfig{
 		OriginalMatch: 90,
 		PartialMatch:  70,
 		PartialOrig:   60,
+		MinSimilarity: DefaultMinSimilarity,
 	},
 	YearWeights: YearWeights{
 		Exact:   50,
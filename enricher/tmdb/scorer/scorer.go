@@ -1,9 +1,5 @@
 package scorer
 
-import (
-	"strings"
-)
-
 /*
 func scoreMedia(
 	item MediaItem,
@@ -24,22 +20,8 @@ func scoreMediaWithConfig(
 ) int {
 	score := 0
 
-	// Normalize title comparisons
-	titleLower := strings.ToLower(item.GetTitle())
-	originalLower := strings.ToLower(item.GetOriginalTitle())
-	expectedLower := strings.ToLower(expectedTitle)
-
-	// Title matching
-	switch {
-	case titleLower == expectedLower:
-		score += cfg.TitleWeights.ExactMatch
-	case originalLower == expectedLower:
-		score += cfg.TitleWeights.OriginalMatch
-	case strings.Contains(titleLower, expectedLower):
-		score += cfg.TitleWeights.PartialMatch
-	case strings.Contains(originalLower, expectedLower):
-		score += cfg.TitleWeights.PartialOrig
-	}
+	// Title matching, see similarity.go
+	score += titleScore(item, expectedTitle, cfg.TitleWeights)
 
 	// Year handling
 	if item.GetReleaseYear() != 0 && expectedYear != 0 {
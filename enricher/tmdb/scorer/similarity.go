@@ -0,0 +1,232 @@
+package scorer
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mrizkifadil26/medix/normalizer/actions/transformer"
+)
+
+// AlternativeTitled is implemented by items that carry TMDb's alternative
+// titles. They count like the original title, which is how foreign films
+// released under a local name are found.
+type AlternativeTitled interface {
+	GetAlternativeTitles() []string
+}
+
+var leadingArticles = map[string]bool{"the": true, "a": true, "an": true}
+
+// NormalizeTitle lowercases a title, runs it through the normalizer's
+// "unicode" and "sanitize" transformers (accents, fancy quotes, "&" to
+// "and"), turns punctuation into spaces and drops a leading article.
+func NormalizeTitle(title string) string {
+	s, _ := transformer.UnicodeNormalizer(title)
+	s, _ = transformer.SanitizeSymbols(s)
+
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 1 && leadingArticles[words[0]] {
+		words = words[1:]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Similarity compares two titles after NormalizeTitle, from 0 to 1. It is
+// 1 when they only differ in spacing ("Spiderman" and "Spider-Man"), and
+// otherwise the better of a token-set ratio and Jaro-Winkler.
+func Similarity(a, b string) float64 {
+	na, nb := NormalizeTitle(a), NormalizeTitle(b)
+	if na == "" || nb == "" {
+		return 0
+	}
+
+	ca, cb := strings.ReplaceAll(na, " ", ""), strings.ReplaceAll(nb, " ", "")
+	if ca == cb {
+		return 1
+	}
+
+	return max(tokenSetRatio(na, nb), jaroWinkler(ca, cb))
+}
+
+// DefaultMinSimilarity is used when TitleWeights.MinSimilarity is unset.
+const DefaultMinSimilarity = 0.8
+
+// titleScore is the best title score of item: an exact match is worth
+// ExactMatch or OriginalMatch, anything at least MinSimilarity alike is
+// PartialMatch or PartialOrig scaled by the similarity.
+func titleScore(item MediaItem, expected string, w TitleWeights) int {
+	minSim := w.MinSimilarity
+	if minSim <= 0 {
+		minSim = DefaultMinSimilarity
+	}
+
+	best := 0
+	score := func(title string, exact, partial int) {
+		if title == "" {
+			return
+		}
+
+		sim := Similarity(title, expected)
+		switch {
+		case sim == 1:
+			best = max(best, exact)
+		case sim >= minSim:
+			best = max(best, int(sim*float64(partial)+0.5))
+		}
+	}
+
+	score(item.GetTitle(), w.ExactMatch, w.PartialMatch)
+	score(item.GetOriginalTitle(), w.OriginalMatch, w.PartialOrig)
+	if alt, ok := item.(AlternativeTitled); ok {
+		for _, t := range alt.GetAlternativeTitles() {
+			score(t, w.OriginalMatch, w.PartialOrig)
+		}
+	}
+
+	return best
+}
+
+// TitleMatch is the best Similarity between expected and the item's title,
+// original title or alternative titles.
+func TitleMatch(item MediaItem, expected string) float64 {
+	titles := []string{item.GetTitle(), item.GetOriginalTitle()}
+	if alt, ok := item.(AlternativeTitled); ok {
+		titles = append(titles, alt.GetAlternativeTitles()...)
+	}
+
+	best := 0.0
+	for _, t := range titles {
+		best = max(best, Similarity(t, expected))
+	}
+
+	return best
+}
+
+// tokenSetRatio compares the sorted shared words plus each side's rest,
+// so word order and extra words weigh less than in a plain comparison.
+func tokenSetRatio(a, b string) float64 {
+	ta, tb := tokenSet(a), tokenSet(b)
+
+	var common, onlyA, onlyB []string
+	for t := range ta {
+		if tb[t] {
+			common = append(common, t)
+		} else {
+			onlyA = append(onlyA, t)
+		}
+	}
+	for t := range tb {
+		if !ta[t] {
+			onlyB = append(onlyB, t)
+		}
+	}
+	if len(common) == 0 {
+		return 0
+	}
+
+	sort.Strings(common)
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+
+	base := strings.Join(common, " ")
+	withA := strings.TrimSpace(base + " " + strings.Join(onlyA, " "))
+	withB := strings.TrimSpace(base + " " + strings.Join(onlyB, " "))
+
+	return max(ratio(withA, withB), ratio(base, withA)*ratio(base, withB))
+}
+
+func tokenSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, t := range strings.Fields(s) {
+		set[t] = true
+	}
+	return set
+}
+
+// ratio is 1 minus the Levenshtein distance over the longer length.
+func ratio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	n := max(len(ra), len(rb))
+	if n == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(n)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+// jaroWinkler is the Jaro similarity boosted by a common prefix of up to
+// four characters.
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(max(len(ra), len(rb))/2-1, 0)
+	matchA := make([]bool, len(ra))
+	matchB := make([]bool, len(rb))
+
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchB[j] && ra[i] == rb[j] {
+				matchA[i], matchB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchA[i] {
+			continue
+		}
+		for !matchB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,83 @@
+package scorer_test
+
+import (
+	"testing"
+
+	"github.com/mrizkifadil26/medix/enricher/tmdb/scorer"
+)
+
+type movie struct {
+	title, original string
+	year            int
+	alternatives    []string
+}
+
+func (m movie) GetTitle() string               { return m.title }
+func (m movie) GetOriginalTitle() string       { return m.original }
+func (m movie) GetReleaseYear() int            { return m.year }
+func (m movie) GetPopularity() float64         { return 0 }
+func (m movie) GetVoteAverage() float64        { return 0 }
+func (m movie) GetGenreIDs() []int             { return nil }
+func (m movie) GetAlternativeTitles() []string { return m.alternatives }
+
+func TestSimilarity(t *testing.T) {
+	same := [][2]string{
+		{"Spiderman Homecoming", "Spider-Man: Homecoming"},
+		{"Amelie", "Amélie"},
+		{"Fast and Furious", "Fast & Furious"},
+		{"Matrix", "The Matrix"},
+		{"Harry Potter and the Sorcerer's Stone", "Harry Potter and the Sorcerers Stone"},
+	}
+	for _, p := range same {
+		if got := scorer.Similarity(p[0], p[1]); got != 1 {
+			t.Errorf("Similarity(%q, %q) = %v, want 1", p[0], p[1], got)
+		}
+	}
+
+	if got := scorer.Similarity("Guardians of Galaxy", "Guardians of the Galaxy"); got < 0.8 || got == 1 {
+		t.Errorf("close titles: got %v", got)
+	}
+	if got := scorer.Similarity("Alien", "Heat"); got >= 0.8 {
+		t.Errorf("unrelated titles: got %v", got)
+	}
+}
+
+func TestPickBestMatch(t *testing.T) {
+	items := []movie{
+		{title: "Spider-Man", year: 2017},
+		{title: "Spider-Man: Homecoming", year: 2017},
+	}
+
+	best := scorer.PickBestMatch(items, "Spiderman Homecoming", 2017, nil)
+	if best == nil || best.title != "Spider-Man: Homecoming" {
+		t.Fatalf("best = %+v", best)
+	}
+
+	// a foreign film searched under its English release title
+	items = []movie{
+		{title: "Untouchable", original: "Untouchable", year: 2011},
+		{title: "Intouchables", original: "Intouchables", year: 2011, alternatives: []string{"The Intouchables"}},
+	}
+
+	best = scorer.PickBestMatch(items, "The Intouchables", 2011, nil)
+	if best == nil || best.title != "Intouchables" {
+		t.Fatalf("best = %+v", best)
+	}
+}
+
+func TestPickBestMatch_UnsetMinSimilarity(t *testing.T) {
+	items := []movie{
+		{title: "Galaxy Quest", year: 2014},
+		{title: "Guardians of the Galaxy", year: 2016},
+	}
+
+	// a config that leaves MinSimilarity out gets the default, so an
+	// unrelated title with the right year earns no partial score
+	cfg := *scorer.DefaultConfig
+	cfg.TitleWeights.MinSimilarity = 0
+
+	best := scorer.PickBestMatchWithConfig(items, "Guardians of Galaxy", 2014, nil, &cfg)
+	if best == nil || best.title != "Guardians of the Galaxy" {
+		t.Fatalf("best = %+v", best)
+	}
+}
@@ -5,6 +5,11 @@ type TitleWeights struct {
 	OriginalMatch int
 	PartialMatch  int
 	PartialOrig   int
+
+	// MinSimilarity is the least Similarity that still earns a partial
+	// score; PartialMatch and PartialOrig are scaled by the similarity.
+	// 0 means DefaultMinSimilarity.
+	MinSimilarity float64
 }
 
 type YearWeights struct {
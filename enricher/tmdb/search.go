@@ -27,6 +27,8 @@ type SearchItem struct {
 	VoteCount        int     `json:"vote_count"`        // For credibility
 	OriginalLanguage string  `json:"original_language"` // Optional filter
 	Popularity       float64 `json:"popularity"`
+
+	AlternativeTitles []string `json:"-"` // filled by addAlternativeTitles for scoring
 }
 
 func (m SearchItem) GetTitle() string {
@@ -60,6 +62,10 @@ func (m SearchItem) GetGenreIDs() []int {
 	return m.GenreIDs
 }
 
+func (m SearchItem) GetAlternativeTitles() []string {
+	return m.AlternativeTitles
+}
+
 type SearchQuery struct {
 	Query       string `param:"query" validate:"required"`
 	Language    string `param:"language"`
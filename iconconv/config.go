@@ -0,0 +1,94 @@
+// Package iconconv turns the PNG folder of an icon pack into
+// multi-resolution .ico files in the ICO folder, keeping the genre
+// subfolders, so the PNG → ICO step that progress counts needs no
+// outside tool.
+package iconconv
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultSizes are the images put in every icon.
+var DefaultSizes = []int{16, 24, 32, 48, 64, 128, 256}
+
+// Square modes for PNGs that are not square.
+const (
+	SquarePad     = "pad"     // center on a transparent square
+	SquareStretch = "stretch" // scale each side on its own
+)
+
+// Config of a conversion run.
+type Config struct {
+	Input    string `json:"input"`              // PNG folder, e.g. <pack>/PNG
+	Output   string `json:"output"`             // ICO folder, e.g. <pack>/ICO
+	Sizes    []int  `json:"sizes,omitempty"`    // default DefaultSizes
+	Resample string `json:"resample,omitempty"` // box, bilinear, catmullrom or lanczos (default)
+	Square   string `json:"square,omitempty"`   // pad (default) or stretch
+	Force    bool   `json:"force,omitempty"`    // convert even when up to date
+	Workers  int    `json:"workers,omitempty"`  // default 4
+}
+
+// normalize fills in defaults and checks the values.
+func (c *Config) normalize() error {
+	if c.Input == "" || c.Output == "" {
+		return fmt.Errorf("input and output folders are required")
+	}
+	if len(c.Sizes) == 0 {
+		c.Sizes = append([]int(nil), DefaultSizes...)
+	}
+	for _, s := range c.Sizes {
+		if s < 1 || s > 256 {
+			return fmt.Errorf("icon size %d is outside 1-256", s)
+		}
+	}
+	sort.Ints(c.Sizes)
+
+	if c.Resample == "" {
+		c.Resample = "lanczos"
+	}
+	if _, err := lookupFilter(c.Resample); err != nil {
+		return err
+	}
+
+	switch c.Square {
+	case "":
+		c.Square = SquarePad
+	case SquarePad, SquareStretch:
+	default:
+		return fmt.Errorf("unknown square mode %q, use pad or stretch", c.Square)
+	}
+
+	if c.Workers <= 0 {
+		c.Workers = 4
+	}
+	return nil
+}
+
+// options is what an icon was built with; a change makes it stale.
+func (c *Config) options() string {
+	sizes := make([]string, len(c.Sizes))
+	for i, s := range c.Sizes {
+		sizes[i] = strconv.Itoa(s)
+	}
+	return fmt.Sprintf("%s %s %s", strings.Join(sizes, ","), c.Resample, c.Square)
+}
+
+// ParseSizes reads a list such as "16,32,48,256".
+func ParseSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("icon size %q: %w", part, err)
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes, nil
+}
@@ -0,0 +1,214 @@
+package iconconv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/concurrency"
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+	"github.com/mrizkifadil26/medix/utils/ico"
+)
+
+// ManifestName is kept in the output folder. It records the checksum of
+// the PNG and the options each icon was built from.
+const ManifestName = ".iconconv.json"
+
+type manifestEntry struct {
+	Source  string `json:"source"` // fsjournal.QuickChecksum of the PNG
+	Options string `json:"options"`
+}
+
+// Result of a conversion run.
+type Result struct {
+	Converted []string  `json:"converted"` // icons written
+	UpToDate  int       `json:"upToDate"`
+	Failed    []Failure `json:"failed,omitempty"`
+}
+
+type Failure struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Run converts every PNG under cfg.Input to an .ico at the same place
+// under cfg.Output. An icon is up to date, and left alone, when it is
+// newer than its PNG or the PNG's checksum has not changed, and it was
+// built with the same sizes, filter and square mode.
+func Run(cfg Config) (*Result, error) {
+	if err := cfg.normalize(); err != nil {
+		return nil, err
+	}
+	filter, _ := lookupFilter(cfg.Resample)
+	opts := cfg.options()
+
+	manifestPath := filepath.Join(cfg.Output, ManifestName)
+	manifest := map[string]manifestEntry{}
+	if utils.FileExists(manifestPath) {
+		if err := utils.LoadJSON(manifestPath, &manifest); err != nil {
+			return nil, fmt.Errorf("load %s: %w", manifestPath, err)
+		}
+	}
+
+	var rels []string
+	err := filepath.WalkDir(cfg.Input, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(d.Name()), ".png") {
+			return nil
+		}
+		rel, err := filepath.Rel(cfg.Input, path)
+		if err != nil {
+			return err
+		}
+		rels = append(rels, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu     sync.Mutex
+		result = &Result{}
+		tasks  []concurrency.TaskFunc
+	)
+
+	for _, rel := range rels {
+		in := filepath.Join(cfg.Input, filepath.FromSlash(rel))
+		out := filepath.Join(cfg.Output, filepath.FromSlash(strings.TrimSuffix(rel, filepath.Ext(rel))+".ico"))
+
+		tasks = append(tasks, func(ctx context.Context) error {
+			mu.Lock()
+			entry, known := manifest[rel]
+			mu.Unlock()
+
+			sum, fresh, err := upToDate(in, out, entry, known, opts, cfg.Force)
+			if err == nil && !fresh {
+				err = convert(in, out, cfg.Sizes, filter, cfg.Square)
+				if err == nil && sum == "" {
+					sum, err = fsjournal.QuickChecksum(in)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				result.Failed = append(result.Failed, Failure{Path: in, Error: err.Error()})
+			case fresh:
+				result.UpToDate++
+			default:
+				result.Converted = append(result.Converted, out)
+				manifest[rel] = manifestEntry{Source: sum, Options: opts}
+			}
+			return nil
+		})
+	}
+
+	exec := concurrency.FromTaskExecutor(concurrency.MustExecutor(concurrency.Config{
+		Mode:  concurrency.ModeGoroutine,
+		Limit: cfg.Workers,
+	}))
+	if err := exec(context.Background(), tasks); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(result.Converted)
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].Path < result.Failed[j].Path })
+
+	if len(result.Converted) > 0 {
+		if err := utils.WriteJSONAtomic(manifestPath, manifest); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// upToDate decides whether out can stay. The PNG's checksum is returned
+// when it had to be computed, so it is not read twice.
+func upToDate(in, out string, entry manifestEntry, known bool, opts string, force bool) (sum string, fresh bool, err error) {
+	if force {
+		return "", false, nil
+	}
+
+	outInfo, err := os.Stat(out)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if known && entry.Options != opts {
+		return "", false, nil
+	}
+
+	inInfo, err := os.Stat(in)
+	if err != nil {
+		return "", false, err
+	}
+	if !outInfo.ModTime().Before(inInfo.ModTime()) {
+		return "", true, nil
+	}
+	if !known {
+		return "", false, nil
+	}
+
+	// the PNG was touched; rebuild only if its content changed
+	sum, err = fsjournal.QuickChecksum(in)
+	if err != nil {
+		return "", false, err
+	}
+	return sum, sum == entry.Source, nil
+}
+
+// convert builds the icon and writes it next to its final name first, so
+// a failed run never leaves half an icon behind.
+func convert(in, out string, sizes []int, filter Filter, square string) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	src, err := png.Decode(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	if square == SquarePad {
+		src = Pad(src)
+	}
+
+	images := make([]image.Image, len(sizes))
+	for i, s := range sizes {
+		images[i] = Resize(src, s, s, filter)
+	}
+
+	var buf bytes.Buffer
+	if err := ico.Encode(&buf, images); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return err
+	}
+	tmp := out + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package iconconv_test
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrizkifadil26/medix/iconconv"
+	"github.com/mrizkifadil26/medix/utils/ico"
+)
+
+func writePNG(t *testing.T, path string, w, h int) {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: 200, G: 30, B: 30, A: 255})
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	pack := t.TempDir()
+	in, out := filepath.Join(pack, "PNG"), filepath.Join(pack, "ICO")
+	src := filepath.Join(in, "Horror", "Alien (1979).png")
+	writePNG(t, src, 300, 200)
+
+	cfg := iconconv.Config{Input: in, Output: out}
+	res, err := iconconv.Run(cfg)
+	if err != nil || len(res.Converted) != 1 || len(res.Failed) != 0 {
+		t.Fatalf("Run = %+v, %v", res, err)
+	}
+
+	icon := filepath.Join(out, "Horror", "Alien (1979).ico")
+	info, err := ico.File(icon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != ico.StatusOK || len(info.Images) != len(iconconv.DefaultSizes) || info.Largest != 256 {
+		t.Errorf("icon = %+v", info)
+	}
+
+	// touched but unchanged: still up to date
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(src, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := iconconv.Run(cfg); err != nil || res.UpToDate != 1 || len(res.Converted) != 0 {
+		t.Errorf("second Run = %+v, %v", res, err)
+	}
+
+	// other options rebuild it
+	cfg.Sizes = []int{32, 256}
+	if res, err := iconconv.Run(cfg); err != nil || len(res.Converted) != 1 {
+		t.Errorf("Run with new sizes = %+v, %v", res, err)
+	}
+}
+
+func TestResize(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 300, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 300; x++ {
+			src.Set(x, y, color.NRGBA{R: 200, G: 30, B: 30, A: 255})
+		}
+	}
+
+	for _, name := range iconconv.FilterNames() {
+		img := iconconv.Resize(iconconv.Pad(src), 32, 32, iconconv.Filters[name])
+		if c := img.NRGBAAt(16, 16); c.R != 200 || c.G != 30 || c.A != 255 {
+			t.Errorf("%s: center = %v", name, c)
+		}
+		if c := img.NRGBAAt(16, 0); c.A != 0 {
+			t.Errorf("%s: padding = %v, want transparent", name, c)
+		}
+	}
+}
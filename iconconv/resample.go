@@ -0,0 +1,179 @@
+package iconconv
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+)
+
+// Filter is a resampling kernel and how far it reaches, in source pixels
+// when enlarging.
+type Filter struct {
+	Support float64
+	Kernel  func(x float64) float64
+}
+
+// Filters by the name used in Config.Resample.
+var Filters = map[string]Filter{
+	"box":        {0.5, func(x float64) float64 { return 1 }},
+	"bilinear":   {1, func(x float64) float64 { return 1 - math.Abs(x) }},
+	"catmullrom": {2, catmullRom},
+	"lanczos":    {3, lanczos3},
+}
+
+// FilterNames lists the Filters keys, sorted.
+func FilterNames() []string {
+	names := make([]string, 0, len(Filters))
+	for name := range Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupFilter(name string) (Filter, error) {
+	f, ok := Filters[name]
+	if !ok {
+		return Filter{}, fmt.Errorf("unknown resample filter %q, use one of %v", name, FilterNames())
+	}
+	return f, nil
+}
+
+func catmullRom(x float64) float64 {
+	x = math.Abs(x)
+	if x < 1 {
+		return (3*x*x*x - 5*x*x + 2) / 2
+	}
+	return (-x*x*x + 5*x*x - 8*x + 4) / 2
+}
+
+func lanczos3(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	px := math.Pi * x
+	return 3 * math.Sin(px) * math.Sin(px/3) / (px * px)
+}
+
+// Pad centers img on a transparent square as wide as its longer side.
+func Pad(img image.Image) image.Image {
+	b := img.Bounds()
+	if b.Dx() == b.Dy() {
+		return img
+	}
+
+	side := max(b.Dx(), b.Dy())
+	dst := image.NewNRGBA(image.Rect(0, 0, side, side))
+	at := image.Pt((side-b.Dx())/2, (side-b.Dy())/2)
+	draw.Draw(dst, b.Sub(b.Min).Add(at), img, b.Min, draw.Src)
+	return dst
+}
+
+// Resize scales img to w×h with the filter, one axis at a time. Colors
+// are weighted by alpha so transparent edges do not turn dark.
+func Resize(img image.Image, w, h int, f Filter) *image.NRGBA {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	// premultiplied RGBA, 0-1
+	src := make([]float64, sw*sh*4)
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			i := (y*sw + x) * 4
+			src[i], src[i+1], src[i+2], src[i+3] = float64(r)/0xffff, float64(g)/0xffff, float64(bl)/0xffff, float64(a)/0xffff
+		}
+	}
+
+	// columns first: sw×sh → w×sh, then rows: w×sh → w×h
+	tmp := make([]float64, w*sh*4)
+	cols := weights(sw, w, f)
+	for y := 0; y < sh; y++ {
+		for x, ws := range cols {
+			for _, wt := range ws {
+				si, di := (y*sw+wt.index)*4, (y*w+x)*4
+				for c := 0; c < 4; c++ {
+					tmp[di+c] += src[si+c] * wt.weight
+				}
+			}
+		}
+	}
+
+	out := make([]float64, w*h*4)
+	rows := weights(sh, h, f)
+	for y, ws := range rows {
+		for _, wt := range ws {
+			for x := 0; x < w; x++ {
+				si, di := (wt.index*w+x)*4, (y*w+x)*4
+				for c := 0; c < 4; c++ {
+					out[di+c] += tmp[si+c] * wt.weight
+				}
+			}
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < w*h; i++ {
+		a := clamp(out[i*4+3])
+		if a == 0 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			dst.Pix[i*4+c] = uint8(clamp(out[i*4+c]/a)*255 + 0.5)
+		}
+		dst.Pix[i*4+3] = uint8(a*255 + 0.5)
+	}
+	return dst
+}
+
+type weight struct {
+	index  int
+	weight float64
+}
+
+// weights lists, for every destination pixel along an axis, the source
+// pixels it is made of. When shrinking the kernel is stretched to cover
+// all of them.
+func weights(from, to int, f Filter) [][]weight {
+	scale := float64(from) / float64(to)
+	stretch := math.Max(scale, 1)
+	support := f.Support * stretch
+
+	out := make([][]weight, to)
+	for i := range out {
+		center := (float64(i)+0.5)*scale - 0.5
+		lo := int(math.Floor(center - support))
+		hi := int(math.Ceil(center + support))
+
+		var ws []weight
+		sum := 0.0
+		for j := lo; j <= hi; j++ {
+			d := (float64(j) - center) / stretch
+			if math.Abs(d) > f.Support {
+				continue
+			}
+			k := f.Kernel(d)
+			if k == 0 {
+				continue
+			}
+			ws = append(ws, weight{min(max(j, 0), from-1), k})
+			sum += k
+		}
+		if sum == 0 {
+			// narrower than a pixel: take the nearest
+			ws = []weight{{min(max(int(math.Round(center)), 0), from-1), 1}}
+			sum = 1
+		}
+		for k := range ws {
+			ws[k].weight /= sum
+		}
+		out[i] = ws
+	}
+	return out
+}
+
+func clamp(v float64) float64 {
+	return math.Min(math.Max(v, 0), 1)
+}
@@ -0,0 +1,37 @@
+// Package iconsync indexes icon packs and matches their .ico files to the
+// scanned movie and TV folders: by slug first, then by a fuzzy title and
+// year. The synced outputs link each media entry to its icon and each icon
+// back to the media using it.
+package iconsync
+
+import "github.com/mrizkifadil26/medix/model"
+
+// IndexConfig is config/iconmap-<label>.json.
+type IndexConfig struct {
+	Type        model.ContentType `json:"type"` // "movies" or "tv"
+	Sources     []IconSource      `json:"sources"`
+	ExcludeDirs []string          `json:"excludeDirs,omitempty"` // folder names to skip
+	Output      string            `json:"output"`
+}
+
+// IconSource is one icon pack; Name ends up as the icons' source, e.g.
+// "Personal" or "Downloaded". Earlier sources win when two have an icon
+// for the same title.
+type IconSource struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+// SyncConfig is config/sync-<label>.json.
+type SyncConfig struct {
+	Name       string `json:"name"`       // content type of the output, e.g. "movies"
+	MediaInput string `json:"mediaInput"` // model.MediaOutput
+	IconInput  string `json:"iconInput"`  // model.IconIndex written by index-icons
+	OutMedia   string `json:"outMedia"`
+	OutIcon    string `json:"outIcon"`
+	OutReport  string `json:"outReport,omitempty"` // default sync.<name>.report.json next to OutMedia
+
+	// MinSimilarity is the least title similarity (0-1) of a fuzzy match;
+	// default 0.9.
+	MinSimilarity float64 `json:"minSimilarity,omitempty"`
+}
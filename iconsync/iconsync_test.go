@@ -0,0 +1,104 @@
+package iconsync_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/iconsync"
+	"github.com/mrizkifadil26/medix/model"
+)
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("ico"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func media(name, genre string, items ...model.MediaEntry) model.MediaEntry {
+	return model.MediaEntry{
+		BaseEntry: model.BaseEntry{
+			Name:        name,
+			Path:        filepath.Join("/movies", genre, name),
+			ContentType: model.TypeMovies,
+			Group:       []string{genre},
+		},
+		Items: items,
+	}
+}
+
+func TestSync(t *testing.T) {
+	root := t.TempDir()
+	personal, downloaded := filepath.Join(root, "personal"), filepath.Join(root, "downloaded")
+
+	touch(t, filepath.Join(personal, "Horror", "Alien (1979).ico"))
+	touch(t, filepath.Join(personal, "Action", "Heat.ico"))
+	touch(t, filepath.Join(downloaded, "Horror", "Alien (1979).ico"))
+	touch(t, filepath.Join(downloaded, "Sci-Fi", "Dune.ico"))
+	touch(t, filepath.Join(downloaded, "Sci-Fi", "Amélie.ico"))
+	touch(t, filepath.Join(downloaded, "Skip", "Heat.ico"))
+
+	index, err := iconsync.BuildIndex(&iconsync.IndexConfig{
+		Type: model.TypeMovies,
+		Sources: []iconsync.IconSource{
+			{Path: personal, Name: "Personal"},
+			{Path: downloaded, Name: "Downloaded"},
+		},
+		ExcludeDirs: []string{"skip"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.TotalItems != 4 {
+		t.Fatalf("TotalItems = %d, want 4", index.TotalItems)
+	}
+
+	entries := []model.MediaEntry{
+		media("Alien (1979)", "Horror"),
+		media("Heat (1995)", "Action"),
+		media("Amelie (2001)", "Drama"),
+		media("Dune (1984)", "Sci-Fi"),
+		media("Dune (2021)", "Sci-Fi"),
+		media("Casablanca (1942)", "Drama"),
+	}
+
+	out := iconsync.Build("movies", entries, index.Items, 0)
+	r := out.Report
+
+	if r.BySlug != 1 || r.ByTitle != 2 {
+		t.Errorf("by slug %d, by title %d; want 1 and 2", r.BySlug, r.ByTitle)
+	}
+	if len(r.Unmatched) != 1 || r.Unmatched[0] != entries[5].Path {
+		t.Errorf("unmatched = %v", r.Unmatched)
+	}
+	if len(r.Ambiguous) != 2 {
+		t.Errorf("ambiguous = %+v, want both Dunes", r.Ambiguous)
+	}
+
+	var alien *model.SyncedIconEntry
+	for _, g := range out.Icons.Data {
+		for i := range g.Items {
+			if g.Items[i].ID == "alien-1979" {
+				alien = &g.Items[i]
+			}
+		}
+	}
+	if alien == nil || alien.Source != "Personal" || len(alien.Variants) != 1 {
+		t.Fatalf("alien icon = %+v", alien)
+	}
+	if alien.UsedBy == nil || alien.UsedBy.Path != entries[0].Path {
+		t.Errorf("used_by = %+v", alien.UsedBy)
+	}
+
+	for _, g := range out.Media.Data {
+		for _, item := range g.Items {
+			if item.Name == "Amelie (2001)" && (item.Source == nil || item.Source.ID != "amelie") {
+				t.Errorf("Amelie source = %+v", item.Source)
+			}
+		}
+	}
+}
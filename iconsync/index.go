@@ -0,0 +1,132 @@
+package iconsync
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mrizkifadil26/medix/model"
+	"github.com/mrizkifadil26/medix/normalizer/actions/transformer"
+	"github.com/mrizkifadil26/medix/utils/ico"
+)
+
+// IndexVersion is written to model.IconIndex.Version.
+const IndexVersion = "1.0.0"
+
+// BuildIndex walks the icon sources and lists every .ico file. Icons of
+// the same title (same slug) become one entry, the first found keeping
+// the others as variants.
+func BuildIndex(cfg *IndexConfig) (*model.IconIndex, error) {
+	start := time.Now()
+
+	exclude := map[string]bool{}
+	for _, d := range cfg.ExcludeDirs {
+		exclude[strings.ToLower(d)] = true
+	}
+
+	var (
+		entries []model.IconEntry
+		bySlug  = map[string]int{}
+		groups  = map[string]bool{}
+		sources []string
+	)
+
+	for _, src := range cfg.Sources {
+		sources = append(sources, src.Name)
+
+		err := filepath.WalkDir(src.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != src.Path && exclude[strings.ToLower(d.Name())] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.EqualFold(filepath.Ext(d.Name()), ".ico") {
+				return nil
+			}
+
+			st, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size := st.Size()
+
+			rel, _ := filepath.Rel(src.Path, filepath.Dir(path))
+			var group []string
+			if rel != "." {
+				group = strings.Split(filepath.ToSlash(rel), "/")
+				groups[group[0]] = true
+			}
+
+			slug := Slug(strings.TrimSuffix(d.Name(), filepath.Ext(d.Name())))
+			if i, ok := bySlug[slug]; ok {
+				entries[i].Variants = append(entries[i].Variants, path)
+				return nil
+			}
+
+			info, err := ico.File(path)
+			if err != nil {
+				return err
+			}
+
+			bySlug[slug] = len(entries)
+			entries = append(entries, model.IconEntry{
+				BaseEntry: model.BaseEntry{
+					Name:        d.Name(),
+					Path:        path,
+					Type:        string(model.TypeIcon),
+					ContentType: cfg.Type,
+					Group:       group,
+					Source:      src.Name,
+				},
+				Slug: slug,
+				Size: size,
+				ICO:  info,
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("index %s: %w", src.Path, err)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Slug < entries[j].Slug })
+
+	return &model.IconIndex{
+		Type:           model.Type(model.TypeIcon),
+		Version:        IndexVersion,
+		GeneratedAt:    time.Now(),
+		Sources:        sources,
+		TotalItems:     len(entries),
+		GroupCount:     len(groups),
+		ScanDurationMs: time.Since(start).Milliseconds(),
+		Items:          entries,
+	}, nil
+}
+
+// Slug is the id of a title the way the normalizer makes item slugs:
+// accents removed, lowercase words joined by dashes.
+func Slug(name string) string {
+	s, _ := transformer.UnicodeNormalizer(name)
+	s, _ = transformer.Slugify(s)
+	return s
+}
+
+var titleYear = regexp.MustCompile(`^(.*?)[\s._-]*[(\[]?((?:19|20)\d{2})[)\]]?$`)
+
+// splitTitleYear reads "Alien (1979)" or "Alien.1979" as the title and
+// year; names without a year at the end are all title.
+func splitTitleYear(name string) (title, year string) {
+	name = strings.TrimSpace(strings.TrimSuffix(name, filepath.Ext(name)))
+	if m := titleYear.FindStringSubmatch(name); m != nil && m[1] != "" {
+		return m[1], m[2]
+	}
+	return name, ""
+}
@@ -0,0 +1,214 @@
+package iconsync
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/enricher/tmdb/scorer"
+	"github.com/mrizkifadil26/medix/model"
+)
+
+// DefaultMinSimilarity is used when SyncConfig.MinSimilarity is unset.
+const DefaultMinSimilarity = 0.9
+
+// tieMargin is how close a second candidate has to score for a fuzzy
+// match to count as ambiguous.
+const tieMargin = 0.02
+
+// Match ways.
+const (
+	BySlug  = "slug"
+	ByTitle = "title"
+)
+
+// Candidate is an icon a media entry could use.
+type Candidate struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Path  string  `json:"path"`
+	Score float64 `json:"score"`
+}
+
+// Ambiguous is a media entry left without an icon because several fit
+// about equally well, or because its icon went to another entry.
+type Ambiguous struct {
+	Name       string      `json:"name"`
+	Path       string      `json:"path"`
+	Reason     string      `json:"reason"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// Report sums up a match run.
+type Report struct {
+	Media     int         `json:"media"`
+	Icons     int         `json:"icons"`
+	BySlug    int         `json:"bySlug"`
+	ByTitle   int         `json:"byTitle"`
+	Unmatched []string    `json:"unmatched"` // media paths without any candidate
+	Unused    int         `json:"unusedIcons"`
+	Ambiguous []Ambiguous `json:"ambiguous"`
+}
+
+// Result maps media paths to the icons they use.
+type Result struct {
+	Icons  map[string]*model.IconEntry // by media path
+	By     map[string]string           // by media path: BySlug or ByTitle
+	Report Report
+}
+
+type iconKey struct {
+	icon  *model.IconEntry
+	title string // normalized, without year
+	year  string
+}
+
+type claim struct {
+	media *model.MediaEntry
+	score float64
+	by    string
+}
+
+// MatchIcons gives every media entry, collections and their parts alike,
+// the icon with the same slug or else the one whose title is at least
+// minSim similar and whose year agrees (icons without a year fit any).
+// An icon goes to one entry only; when two entries want it equally, both
+// end up in the report as ambiguous.
+func MatchIcons(media []model.MediaEntry, icons []model.IconEntry, minSim float64) *Result {
+	if minSim <= 0 {
+		minSim = DefaultMinSimilarity
+	}
+
+	res := &Result{
+		Icons: map[string]*model.IconEntry{},
+		By:    map[string]string{},
+	}
+
+	bySlug := map[string]*model.IconEntry{}
+	buckets := map[string][]iconKey{}
+	for i := range icons {
+		icon := &icons[i]
+		bySlug[icon.Slug] = icon
+
+		title, year := splitTitleYear(icon.Name)
+		key := iconKey{icon: icon, title: scorer.NormalizeTitle(title), year: year}
+		b := bucket(key.title)
+		buckets[b] = append(buckets[b], key)
+	}
+
+	claims := map[*model.IconEntry][]claim{}
+	var walk func(entries []model.MediaEntry)
+	walk = func(entries []model.MediaEntry) {
+		for i := range entries {
+			m := &entries[i]
+			res.Report.Media++
+			walk(m.Items)
+
+			if icon, ok := bySlug[Slug(m.Name)]; ok {
+				claims[icon] = append(claims[icon], claim{m, 2, BySlug})
+				continue
+			}
+
+			cands := fuzzyCandidates(m.Name, buckets, minSim)
+			switch {
+			case len(cands) == 0:
+				res.Report.Unmatched = append(res.Report.Unmatched, m.Path)
+			case len(cands) > 1 && cands[0].score-cands[1].score < tieMargin:
+				amb := Ambiguous{Name: m.Name, Path: m.Path, Reason: "several icons fit"}
+				for _, c := range cands {
+					if cands[0].score-c.score >= tieMargin {
+						break
+					}
+					amb.Candidates = append(amb.Candidates, candidate(c.icon, c.score))
+				}
+				res.Report.Ambiguous = append(res.Report.Ambiguous, amb)
+			default:
+				claims[cands[0].icon] = append(claims[cands[0].icon], claim{m, cands[0].score, ByTitle})
+			}
+		}
+	}
+	walk(media)
+
+	for icon, cs := range claims {
+		sort.SliceStable(cs, func(i, j int) bool { return cs[i].score > cs[j].score })
+
+		if len(cs) > 1 && cs[0].score-cs[1].score < tieMargin {
+			for _, c := range cs {
+				res.Report.Ambiguous = append(res.Report.Ambiguous, Ambiguous{
+					Name: c.media.Name, Path: c.media.Path,
+					Reason:     "icon fits several entries",
+					Candidates: []Candidate{candidate(icon, c.score)},
+				})
+			}
+			continue
+		}
+
+		win := cs[0]
+		res.Icons[win.media.Path] = icon
+		res.By[win.media.Path] = win.by
+		if win.by == BySlug {
+			res.Report.BySlug++
+		} else {
+			res.Report.ByTitle++
+		}
+		for _, c := range cs[1:] {
+			res.Report.Ambiguous = append(res.Report.Ambiguous, Ambiguous{
+				Name: c.media.Name, Path: c.media.Path,
+				Reason:     "icon used by " + win.media.Path,
+				Candidates: []Candidate{candidate(icon, c.score)},
+			})
+		}
+	}
+
+	res.Report.Icons = len(icons)
+	res.Report.Unused = len(icons) - len(res.Icons)
+	sort.Slice(res.Report.Ambiguous, func(i, j int) bool { return res.Report.Ambiguous[i].Path < res.Report.Ambiguous[j].Path })
+	sort.Strings(res.Report.Unmatched)
+
+	return res
+}
+
+type scored struct {
+	icon  *model.IconEntry
+	score float64
+}
+
+// fuzzyCandidates lists the icons of the same bucket that fit name, best
+// first. A matching year adds a little, so that "Dune (2021)" prefers
+// "Dune 2021.ico" over a yearless "Dune.ico".
+func fuzzyCandidates(name string, buckets map[string][]iconKey, minSim float64) []scored {
+	title, year := splitTitleYear(name)
+	norm := scorer.NormalizeTitle(title)
+
+	var out []scored
+	for _, k := range buckets[bucket(norm)] {
+		if k.year != "" && year != "" && k.year != year {
+			continue
+		}
+
+		sim := scorer.Similarity(norm, k.title)
+		if sim < minSim {
+			continue
+		}
+		if k.year != "" && k.year == year {
+			sim += 0.05
+		}
+		out = append(out, scored{k.icon, sim})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].score > out[j].score })
+	return out
+}
+
+// bucket keeps fuzzy matching from comparing every title with every
+// icon: only titles starting with the same three letters are compared.
+func bucket(norm string) string {
+	r := []rune(strings.ReplaceAll(norm, " ", ""))
+	if len(r) > 3 {
+		r = r[:3]
+	}
+	return string(r)
+}
+
+func candidate(icon *model.IconEntry, score float64) Candidate {
+	return Candidate{ID: icon.Slug, Name: icon.Name, Path: icon.Path, Score: score}
+}
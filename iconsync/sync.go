@@ -0,0 +1,175 @@
+package iconsync
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mrizkifadil26/medix/model"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+// Ungrouped names the group of entries without one.
+const Ungrouped = "Ungrouped"
+
+// Synced is what a sync run writes.
+type Synced struct {
+	Media  *model.SyncedOutput    `json:"media"`
+	Icons  *model.SyncedIconIndex `json:"icons"`
+	Report *Report                `json:"report"`
+}
+
+// Sync reads the media and icon indexes named by cfg, matches them and
+// writes the synced media, the synced icon map and the report.
+func Sync(cfg *SyncConfig) (*Synced, error) {
+	var media model.MediaOutput
+	if err := utils.LoadJSON(cfg.MediaInput, &media); err != nil {
+		return nil, fmt.Errorf("load media: %w", err)
+	}
+
+	var index model.IconIndex
+	if err := utils.LoadJSON(cfg.IconInput, &index); err != nil {
+		return nil, fmt.Errorf("load icons: %w", err)
+	}
+
+	out := Build(cfg.Name, media.Items, index.Items, cfg.MinSimilarity)
+
+	if err := utils.WriteJSONAtomic(cfg.OutMedia, out.Media); err != nil {
+		return nil, err
+	}
+	if err := utils.WriteJSONAtomic(cfg.OutIcon, out.Icons); err != nil {
+		return nil, err
+	}
+	if err := utils.WriteJSONAtomic(ReportPath(cfg), out.Report); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReportPath is where Sync writes the report.
+func ReportPath(cfg *SyncConfig) string {
+	if cfg.OutReport != "" {
+		return cfg.OutReport
+	}
+	return filepath.Join(filepath.Dir(cfg.OutMedia), "sync."+cfg.Name+".report.json")
+}
+
+// Build matches icons to media and lays both out in the synced formats:
+// media grouped by genre with the icon each uses as source, icons grouped
+// by their pack folder with used_by pointing back at the media.
+func Build(name string, media []model.MediaEntry, icons []model.IconEntry, minSim float64) *Synced {
+	res := MatchIcons(media, icons, minSim)
+	now := time.Now()
+
+	users := map[*model.IconEntry]*model.MediaEntry{}
+	var collect func(entries []model.MediaEntry)
+	collect = func(entries []model.MediaEntry) {
+		for i := range entries {
+			if icon := res.Icons[entries[i].Path]; icon != nil {
+				users[icon] = &entries[i]
+			}
+			collect(entries[i].Items)
+		}
+	}
+	collect(media)
+
+	mediaOut := &model.SyncedOutput{Type: name, GeneratedAt: now}
+	genres := map[string]int{}
+	for _, m := range media {
+		g := groupOf(m.Group)
+		i, ok := genres[g]
+		if !ok {
+			i = len(mediaOut.Data)
+			genres[g] = i
+			mediaOut.Data = append(mediaOut.Data, model.SyncedGenre{Name: g})
+		}
+		mediaOut.Data[i].Items = append(mediaOut.Data[i].Items, syncedItem(m, res))
+	}
+
+	iconOut := &model.SyncedIconIndex{Type: string(model.TypeIcon), GeneratedAt: now}
+	groups := map[string]int{}
+	for i := range icons {
+		icon := &icons[i]
+		g := icon.Source
+		if len(icon.Group) > 0 {
+			g = icon.Group[0]
+		}
+		gi, ok := groups[g]
+		if !ok {
+			gi = len(iconOut.Data)
+			groups[g] = gi
+			iconOut.Data = append(iconOut.Data, model.SyncedIconGroup{Name: g})
+		}
+
+		entry := model.SyncedIconEntry{
+			ID:       icon.Slug,
+			Name:     icon.Name,
+			FullPath: icon.Path,
+			Size:     icon.Size,
+			Source:   icon.Source,
+			Type:     iconType(users[icon]),
+		}
+		if m := users[icon]; m != nil {
+			entry.UsedBy = &model.UsedBy{Name: m.Name, Path: m.Path, ContentType: string(m.ContentType)}
+		}
+		for _, v := range icon.Variants {
+			entry.Variants = append(entry.Variants, model.SyncedIconMeta{
+				ID:       icon.Slug,
+				Name:     filepath.Base(v),
+				FullPath: v,
+				Source:   icon.Source,
+				Type:     entry.Type,
+			})
+		}
+		iconOut.Data[gi].Items = append(iconOut.Data[gi].Items, entry)
+	}
+
+	sort.Slice(mediaOut.Data, func(i, j int) bool { return mediaOut.Data[i].Name < mediaOut.Data[j].Name })
+	sort.Slice(iconOut.Data, func(i, j int) bool { return iconOut.Data[i].Name < iconOut.Data[j].Name })
+
+	return &Synced{Media: mediaOut, Icons: iconOut, Report: &res.Report}
+}
+
+func syncedItem(m model.MediaEntry, res *Result) model.SyncedItem {
+	item := model.SyncedItem{
+		Type:   "single",
+		Name:   m.Name,
+		Path:   m.Path,
+		Status: m.Status,
+		Icon:   m.Icon,
+	}
+	if len(m.Items) > 0 {
+		item.Type = "collection"
+	}
+
+	if icon := res.Icons[m.Path]; icon != nil {
+		item.Source = &model.SyncedIconMeta{
+			ID:       icon.Slug,
+			Name:     icon.Name,
+			FullPath: icon.Path,
+			Size:     icon.Size,
+			Source:   icon.Source,
+			Type:     iconType(&m),
+		}
+	}
+
+	for _, child := range m.Items {
+		item.Items = append(item.Items, syncedItem(child, res))
+	}
+	return item
+}
+
+func iconType(m *model.MediaEntry) string {
+	if m != nil && len(m.Items) > 0 {
+		return "collection"
+	}
+	return string(model.TypeIcon)
+}
+
+func groupOf(group []string) string {
+	if len(group) == 0 || group[0] == "" {
+		return Ungrouped
+	}
+	return group[0]
+}
@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/mrizkifadil26/medix/utils/ico"
+)
 
 type IconIndex struct {
 	Type           Type        `json:"type"`           // Always "icon"
@@ -15,7 +19,8 @@ type IconIndex struct {
 
 type IconEntry struct {
 	BaseEntry
-	Slug     string   `json:"id"`
-	Size     int64    `json:"size"`
-	Variants []string `json:"variants,omitempty"` // List of full paths to variant .ico files
+	Slug     string    `json:"id"`
+	Size     int64     `json:"size"`
+	Variants []string  `json:"variants,omitempty"` // List of full paths to variant .ico files
+	ICO      *ico.Info `json:"ico,omitempty"`      // Embedded images and quality issues
 }
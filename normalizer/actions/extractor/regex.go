@@ -0,0 +1,162 @@
+package extractor
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/mrizkifadil26/medix/normalizer/actions/transformer"
+	"github.com/mrizkifadil26/medix/normalizer/registries"
+)
+
+// compiled patterns are cached since the same field config runs per item
+var regexCache sync.Map // pattern → *regexp.Regexp
+
+// ExtractRegex matches input against params["regex"] and then each pattern in
+// params["fallbacks"], in order. The first pattern that matches wins and every
+// named capture group becomes one entry of the result, e.g.
+//
+//	{
+//	  "pattern": "regex",
+//	  "regex": "^(?P<title>.+?) \\((?P<year>\\d{4})\\)$",
+//	  "fallbacks": ["^(?P<title>.+)$"],
+//	  "transforms": { "title": ["trim", "unicode"] }
+//	}
+//
+// Optional params["transforms"] maps a group name to transformer methods that
+// are applied to that group's value. Empty groups are omitted.
+func ExtractRegex(input string, params map[string]any) (registries.Captures, error) {
+	patterns, err := regexPatterns(params)
+	if err != nil {
+		return nil, err
+	}
+
+	transforms, err := regexTransforms(params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range patterns {
+		re, err := compileRegex(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		match := re.FindStringSubmatch(input)
+		if match == nil {
+			continue
+		}
+
+		captures := registries.Captures{}
+		for i, name := range re.SubexpNames() {
+			if i == 0 || name == "" || match[i] == "" {
+				continue
+			}
+
+			val := match[i]
+			if methods, ok := transforms[name]; ok {
+				val, err = transformer.GetRegistry().ApplyAll(val, methods)
+				if err != nil {
+					return nil, fmt.Errorf("regex group %q: %w", name, err)
+				}
+			}
+
+			if val != "" {
+				captures[name] = val
+			}
+		}
+
+		return captures, nil
+	}
+
+	return nil, fmt.Errorf("no regex pattern matched input: %q", input)
+}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+
+	if re.NumSubexp() == 0 {
+		return nil, fmt.Errorf("regex %q has no capture groups", pattern)
+	}
+
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+func regexPatterns(params map[string]any) ([]string, error) {
+	primary, ok := params["regex"].(string)
+	if !ok || primary == "" {
+		return nil, fmt.Errorf("regex: missing 'regex' parameter")
+	}
+
+	patterns := []string{primary}
+	if raw, ok := params["fallbacks"]; ok {
+		fallbacks, err := toStrings(raw)
+		if err != nil {
+			return nil, fmt.Errorf("regex: invalid 'fallbacks': %w", err)
+		}
+
+		patterns = append(patterns, fallbacks...)
+	}
+
+	return patterns, nil
+}
+
+func regexTransforms(params map[string]any) (map[string][]string, error) {
+	raw, ok := params["transforms"]
+	if !ok {
+		return nil, nil
+	}
+
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("regex: 'transforms' must be an object, got %T", raw)
+	}
+
+	out := make(map[string][]string, len(m))
+	for name, v := range m {
+		methods, err := toStrings(v)
+		if err != nil {
+			return nil, fmt.Errorf("regex: invalid transforms for %q: %w", name, err)
+		}
+
+		out[name] = methods
+	}
+
+	return out, nil
+}
+
+func toStrings(v any) ([]string, error) {
+	switch t := v.(type) {
+	case string:
+		return []string{t}, nil
+	case []string:
+		return t, nil
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, item := range t {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected string, got %T", item)
+			}
+
+			out = append(out, s)
+		}
+
+		return out, nil
+	default:
+		return nil, fmt.Errorf("expected string or list of strings, got %T", v)
+	}
+}
+
+func init() {
+	GetRegistry().
+		RegisterCapture("regex", ExtractRegex)
+}
@@ -0,0 +1,55 @@
+package extractor_test
+
+import (
+	"testing"
+
+	"github.com/mrizkifadil26/medix/normalizer/actions/extractor"
+)
+
+func TestExtractRegex_NamedGroups(t *testing.T) {
+	params := map[string]any{
+		"regex": `^(?P<title>.+?) \((?P<year>\d{4})\)$`,
+	}
+
+	got, err := extractor.ExtractRegex("Inception (2010)", params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got["title"] != "Inception" || got["year"] != "2010" {
+		t.Errorf("unexpected captures: %v", got)
+	}
+}
+
+func TestExtractRegex_FallbackAndTransforms(t *testing.T) {
+	params := map[string]any{
+		"regex":     `^(?P<title>.+?) \((?P<year>\d{4})\)$`,
+		"fallbacks": []any{`^(?P<title>.+?)\s*$`},
+		"transforms": map[string]any{
+			"title": []any{"lowercase"},
+		},
+	}
+
+	got, err := extractor.ExtractRegex("Amélie ", params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got["title"] != "amélie" {
+		t.Errorf("expected fallback title to be lowercased, got %q", got["title"])
+	}
+
+	if _, ok := got["year"]; ok {
+		t.Errorf("expected no year capture, got %v", got)
+	}
+}
+
+func TestExtractRegex_NoMatch(t *testing.T) {
+	params := map[string]any{
+		"regex": `^(?P<year>\d{4})$`,
+	}
+
+	if _, err := extractor.ExtractRegex("no year here", params); err == nil {
+		t.Error("expected error when no pattern matches")
+	}
+}
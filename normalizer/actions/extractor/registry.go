@@ -8,8 +8,14 @@ import (
 )
 
 type Extractor func(string) (string, error)
+
+// CaptureExtractor extracts several named values at once and receives the
+// full action params, so it can be configured per field.
+type CaptureExtractor func(string, map[string]any) (registries.Captures, error)
+
 type Registry struct {
 	*utils.Registry[Extractor]
+	captures *utils.Registry[CaptureExtractor]
 }
 
 var singleton *Registry
@@ -18,16 +24,22 @@ func GetRegistry() *Registry {
 	if singleton == nil {
 		singleton = &Registry{
 			Registry: utils.NewRegistry[Extractor](),
+			captures: utils.NewRegistry[CaptureExtractor](),
 		}
 	}
 
 	return singleton
 }
 
+// RegisterCapture registers an extractor that yields named captures.
+func (r *Registry) RegisterCapture(name string, fn CaptureExtractor) {
+	r.captures.Register(name, fn)
+}
+
 // ApplyByName applies a transformer by name to a value
 func (r *Registry) Apply(
 	input any, params map[string]any,
-) (string, error) {
+) (any, error) {
 	// Convert input to string at the boundary
 	strInput, ok := input.(string)
 	if !ok {
@@ -39,6 +51,10 @@ func (r *Registry) Apply(
 		return strInput, fmt.Errorf("pattern not provided")
 	}
 
+	if fn, ok := r.captures.Get(pattern); ok {
+		return fn(strInput, params)
+	}
+
 	fn, ok := r.Get(pattern)
 	if !ok {
 		return strInput, fmt.Errorf("extractor %q not found", pattern)
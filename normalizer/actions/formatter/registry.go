@@ -27,7 +27,7 @@ func GetRegistry() *Registry {
 // ApplyByName applies a transformer by name to a value
 func (r *Registry) Apply(
 	input any, params map[string]any,
-) (string, error) {
+) (any, error) {
 	template, ok := params["template"].(string)
 	if !ok || template == "" {
 		return "", fmt.Errorf("input not provided")
@@ -0,0 +1,352 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mrizkifadil26/medix/normalizer/actions/transformer"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+// Template syntax:
+//
+//	{{metadata.title}}                       value at path, "[unknown]" if missing
+//	{{metadata.year | default ""}}           fallback when missing or empty
+//	{{metadata.title | unicode | slugify}}   pipe through transformers
+//	{{if metadata.year}} ({{metadata.year}}){{else}} (n/a){{end}}
+//
+// Pipe functions other than "default" are looked up in the transformer
+// registry, so every transform method is available here as well.
+//
+// A tag holds no braces. A {{ that is never closed, and an empty {{}}, are
+// copied as they are.
+
+const unknownValue = "[unknown]"
+
+type node interface {
+	render(input any, sb *strings.Builder) error
+}
+
+type textNode string
+
+type exprNode struct {
+	path  string
+	pipes []pipe
+}
+
+type ifNode struct {
+	cond      exprNode
+	then      []node
+	otherwise []node
+}
+
+type pipe struct {
+	name string
+	args []string
+}
+
+type parsedTemplate []node
+
+// parsed templates are cached since the same template runs per item
+var templateCache sync.Map // string → parsedTemplate
+
+func parseTemplateCached(tmpl string) (parsedTemplate, error) {
+	if t, ok := templateCache.Load(tmpl); ok {
+		return t.(parsedTemplate), nil
+	}
+
+	t, err := parseTemplate(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	templateCache.Store(tmpl, t)
+	return t, nil
+}
+
+func (t parsedTemplate) execute(input any) (string, error) {
+	var sb strings.Builder
+	if err := renderNodes(t, input, &sb); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+func renderNodes(nodes []node, input any, sb *strings.Builder) error {
+	for _, n := range nodes {
+		if err := n.render(input, sb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n textNode) render(_ any, sb *strings.Builder) error {
+	sb.WriteString(string(n))
+	return nil
+}
+
+func (n exprNode) render(input any, sb *strings.Builder) error {
+	val, ok, err := n.eval(input)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		val = unknownValue
+	}
+
+	sb.WriteString(val)
+	return nil
+}
+
+func (n ifNode) render(input any, sb *strings.Builder) error {
+	val, ok, err := n.cond.eval(input)
+	if err != nil {
+		return err
+	}
+
+	if ok && val != "" {
+		return renderNodes(n.then, input, sb)
+	}
+
+	return renderNodes(n.otherwise, input, sb)
+}
+
+// eval resolves the path and runs the pipes. ok is false when the value is
+// missing (or not a primitive) and no default pipe supplied one.
+func (n exprNode) eval(input any) (string, bool, error) {
+	var (
+		val string
+		ok  bool
+	)
+
+	if raw, err := jsonpath.Get(input, n.path); err == nil && raw != nil && isPrimitive(raw) {
+		val, ok = fmt.Sprintf("%v", raw), true
+	}
+
+	for _, p := range n.pipes {
+		if p.name == "default" {
+			if !ok || val == "" {
+				val, ok = p.args[0], true
+			}
+
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		fn, found := transformer.GetRegistry().Get(p.name)
+		if !found {
+			return "", false, fmt.Errorf("template: unknown function %q", p.name)
+		}
+
+		var err error
+		if val, err = fn(val); err != nil {
+			return "", false, fmt.Errorf("template: %s: %w", p.name, err)
+		}
+	}
+
+	return val, ok, nil
+}
+
+func parseTemplate(tmpl string) (parsedTemplate, error) {
+	p := &templateParser{src: tmpl}
+
+	nodes, stop, err := p.parseUntil()
+	if err != nil {
+		return nil, err
+	}
+
+	if stop != "" {
+		return nil, fmt.Errorf("template: unexpected {{%s}}", stop)
+	}
+
+	return nodes, nil
+}
+
+type templateParser struct {
+	src string
+	pos int
+}
+
+// parseUntil parses nodes until the end of input or an else/end tag, which is
+// returned as stop so the caller can close its block.
+func (p *templateParser) parseUntil() ([]node, string, error) {
+	var nodes []node
+
+	for p.pos < len(p.src) {
+		open, close := nextTag(p.src, p.pos)
+		if open < 0 {
+			nodes = append(nodes, textNode(p.src[p.pos:]))
+			p.pos = len(p.src)
+			break
+		}
+
+		if open > p.pos {
+			nodes = append(nodes, textNode(p.src[p.pos:open]))
+		}
+
+		tag := strings.TrimSpace(p.src[open+2 : close])
+		p.pos = close + 2
+
+		if tag == "" {
+			// {{}} names no value and stays as written
+			nodes = append(nodes, textNode(p.src[open:p.pos]))
+			continue
+		}
+
+		switch {
+		case tag == "else" || tag == "end":
+			return nodes, tag, nil
+
+		case strings.HasPrefix(tag, "if "):
+			cond, err := parseExpr(strings.TrimSpace(tag[3:]))
+			if err != nil {
+				return nil, "", err
+			}
+
+			n := ifNode{cond: cond}
+
+			body, stop, err := p.parseUntil()
+			if err != nil {
+				return nil, "", err
+			}
+			n.then = body
+
+			if stop == "else" {
+				body, stop, err = p.parseUntil()
+				if err != nil {
+					return nil, "", err
+				}
+				n.otherwise = body
+			}
+
+			if stop != "end" {
+				return nil, "", fmt.Errorf("template: {{if %s}} is missing {{end}}", cond.path)
+			}
+
+			nodes = append(nodes, n)
+
+		default:
+			expr, err := parseExpr(tag)
+			if err != nil {
+				return nil, "", err
+			}
+
+			nodes = append(nodes, expr)
+		}
+	}
+
+	return nodes, "", nil
+}
+
+// nextTag finds the first {{...}} at or after from whose inside has no
+// braces, and returns the offsets of its {{ and }}, or -1 when there is
+// none. Anything else, such as a {{ that is never closed, is plain text,
+// as it was before the template syntax grew pipes and conditionals.
+func nextTag(src string, from int) (open, close int) {
+	for i := from; ; i++ {
+		start := strings.Index(src[i:], "{{")
+		if start < 0 {
+			return -1, -1
+		}
+		i += start
+
+		inner := i + 2
+		end := strings.IndexAny(src[inner:], "{}")
+		if end >= 0 && strings.HasPrefix(src[inner+end:], "}}") {
+			return i, inner + end
+		}
+	}
+}
+
+func parseExpr(src string) (exprNode, error) {
+	segments, err := splitOutsideQuotes(src, '|')
+	if err != nil {
+		return exprNode{}, err
+	}
+
+	path := strings.TrimSpace(segments[0])
+	if path == "" {
+		return exprNode{}, fmt.Errorf("template: empty expression in %q", src)
+	}
+
+	expr := exprNode{path: path}
+	for _, seg := range segments[1:] {
+		fields, err := splitOutsideQuotes(strings.TrimSpace(seg), ' ')
+		if err != nil {
+			return exprNode{}, err
+		}
+
+		var parts []string
+		for _, f := range fields {
+			if f != "" {
+				parts = append(parts, f)
+			}
+		}
+
+		if len(parts) == 0 {
+			return exprNode{}, fmt.Errorf("template: empty pipe in %q", src)
+		}
+
+		p := pipe{name: parts[0]}
+		for _, arg := range parts[1:] {
+			p.args = append(p.args, unquote(arg))
+		}
+
+		if p.name == "default" && len(p.args) != 1 {
+			return exprNode{}, fmt.Errorf("template: default expects 1 argument in %q", src)
+		}
+
+		expr.pipes = append(expr.pipes, p)
+	}
+
+	return expr, nil
+}
+
+// splitOutsideQuotes splits s on sep, ignoring separators inside '...' or "...".
+func splitOutsideQuotes(s string, sep rune) ([]string, error) {
+	var (
+		parts []string
+		cur   strings.Builder
+		quote rune
+	)
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			cur.WriteRune(r)
+		case r == sep:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("template: unterminated quote in %q", s)
+	}
+
+	return append(parts, cur.String()), nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
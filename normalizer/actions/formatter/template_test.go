@@ -0,0 +1,66 @@
+package formatter_test
+
+import (
+	"testing"
+
+	"github.com/mrizkifadil26/medix/normalizer/actions/formatter"
+)
+
+func TestDefaultFormatter(t *testing.T) {
+	item := map[string]any{
+		"metadata": map[string]any{
+			"title": "Amélie",
+			"year":  "2001",
+		},
+		"empty": map[string]any{
+			"title": "Flow",
+			"year":  "",
+		},
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{{metadata.title}} ({{metadata.year}})", "Amélie (2001)"},
+		{"{{metadata.title}}-{{metadata.missing}}", "Amélie-[unknown]"},
+		{`{{metadata.missing | default ""}}x`, "x"},
+		{`{{empty.year | default "n/a"}}`, "n/a"},
+		{"{{metadata.title | unicode | slugify}}", "amelie"},
+		{"{{empty.title}}{{if empty.year}} ({{empty.year}}){{end}}", "Flow"},
+		{"{{metadata.title}}{{if metadata.year}} ({{metadata.year}}){{end}}", "Amélie (2001)"},
+		{"{{if metadata.missing}}yes{{else}}no{{end}}", "no"},
+
+		// text that only looks like a tag is copied as before
+		{"a {{ b", "a {{ b"},
+		{"x{{title", "x{{title"},
+		{"{{}}", "{{}}"},
+		{"{{{metadata.title}}", "{Amélie"},
+		{"{{metadata.title}}}", "Amélie}"},
+	}
+
+	for _, tt := range tests {
+		got, err := formatter.DefaultFormatter(item, tt.template)
+		if err != nil {
+			t.Errorf("template %q: unexpected error: %v", tt.template, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("template %q: got %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultFormatter_Errors(t *testing.T) {
+	item := map[string]any{"title": "Flow"}
+
+	for _, template := range []string{
+		"{{if title}}unterminated",
+		"{{title | nosuchfunc}}",
+	} {
+		if _, err := formatter.DefaultFormatter(item, template); err == nil {
+			t.Errorf("template %q: expected error", template)
+		}
+	}
+}
@@ -27,7 +27,7 @@ func GetRegistry() *Registry {
 // ApplyByName applies a transformer by name to a value
 func (r *Registry) Apply(
 	input any, params map[string]any,
-) (string, error) {
+) (any, error) {
 	// Convert input to string at the boundary
 	strInput, ok := input.(string)
 	if !ok {
@@ -26,7 +26,7 @@ func GetRegistry() *Registry {
 
 func (r *Registry) Apply(
 	input any, params map[string]any,
-) (string, error) {
+) (any, error) {
 	// Convert input to string at the boundary
 	strInput, ok := input.(string)
 	if !ok {
@@ -56,10 +56,11 @@ func (r *Registry) Apply(
 		return strInput, fmt.Errorf("invalid methods type: %T", methodsVal)
 	}
 
-	return r.applyAll(strInput, methods)
+	return r.ApplyAll(strInput, methods)
 }
 
-func (r *Registry) applyAll(
+// ApplyAll runs the named transformers over input in order.
+func (r *Registry) ApplyAll(
 	input string,
 	methods []string,
 ) (string, error) {
@@ -2,27 +2,30 @@ package normalizer
 
 import (
 	"flag"
-	"fmt"
 )
 
 type CLIArgs struct {
+	Args       []string // positional arguments after the flags
 	ConfigPath *string
 	Config     Config
 }
 
-func ParseCLI() (*CLIArgs, error) {
+// ParseCLI parses the normalize subcommand flags. ConfigPath is nil when -config
+// is not given.
+func ParseCLI(argv []string) (*CLIArgs, error) {
+	fs := flag.NewFlagSet("normalize", flag.ContinueOnError)
+
 	var (
-		configPath = flag.String("config", "", "Path to config file (JSON or YAML)")
-		outputPath = flag.String("output", "", "Output result path")
-		root       = flag.String("root", "", "Root directory to scan")
-		verbose    = flag.Bool("verbose", false, "Enable verbose logging")
+		configPath = fs.String("config", "", "Path to config file (JSON or YAML)")
+		outputPath = fs.String("output", "", "Output result path")
+		root       = fs.String("root", "", "Root directory to scan")
+		verbose    = fs.Bool("verbose", false, "Enable verbose logging")
+		stream     = fs.Bool("stream", false, "Process items one at a time instead of loading the whole file")
+		validate   = fs.Bool("validate", false, "Fail when the output does not match the normalized schema")
 	)
 
-	flag.Parse()
-
-	// Ensure config file is provided
-	if configPath == nil || *configPath == "" {
-		return nil, fmt.Errorf("missing required -config argument")
+	if err := fs.Parse(argv); err != nil {
+		return nil, err
 	}
 
 	var cfg Config
@@ -38,13 +41,26 @@ func ParseCLI() (*CLIArgs, error) {
 		shouldPopulate = true
 	}
 
+	if stream != nil && *stream {
+		cfg.Stream = *stream
+		shouldPopulate = true
+	}
+
+	if validate != nil && *validate {
+		cfg.Validate = *validate
+		shouldPopulate = true
+	}
+
 	if outputPath != nil && *outputPath != "" {
 		cfg.OutputPath = *outputPath
 		shouldPopulate = true
 	}
 
-	args := &CLIArgs{
-		ConfigPath: configPath,
+	args := &CLIArgs{}
+
+	args.Args = fs.Args()
+	if *configPath != "" {
+		args.ConfigPath = configPath
 	}
 
 	if shouldPopulate {
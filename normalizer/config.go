@@ -16,6 +16,8 @@ type Config struct {
 	Options    Options `json:"options"`
 	Verbose    bool    `json:"verbose,omitempty"`
 	OutputPath string  `json:"outputPath,omitempty"`
+	Stream     bool    `json:"stream,omitempty"`   // Process items one at a time
+	Validate   bool    `json:"validate,omitempty"` // Check output against the normalized schema
 }
 
 // Each field and its actions
@@ -2,31 +2,161 @@ package normalizer_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"testing"
 
 	"github.com/mrizkifadil26/medix/normalizer"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/extractor"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/formatter"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/transformer"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
 )
 
-// Load and cache JSON once for benchmark
-var testData map[string]any
+// Real scan output, used when present. The synthetic 10k-item document is
+// always available so the numbers stay comparable between runs.
+const testSource = "../data/scanner/media/movies.final.json"
 
-func init() {
-	testSource := "../data/scanner/media/movies.final.json"
-	data, err := os.ReadFile("../data/scanner/media/movies.final.json")
+const largeItemCount = 10_000
+
+// makeScanJSON builds a scan-shaped document with n directory items.
+func makeScanJSON(n int) []byte {
+	items := make([]map[string]any, n)
+	for i := range items {
+		items[i] = map[string]any{
+			"name":        fmt.Sprintf("Movie Number %d (%d)", i, 1950+i%70),
+			"type":        "directory",
+			"path":        fmt.Sprintf("/media/Movies/Action/Movie Number %d", i),
+			"group_label": []string{"Action"},
+		}
+
+		if i%10 == 0 {
+			items[i]["type"] = "file"
+		}
+	}
+
+	data, _ := json.Marshal(map[string]any{
+		"item_count": n,
+		"items":      items,
+	})
+
+	return data
+}
+
+func loadMap(b *testing.B, raw []byte) map[string]any {
+	b.Helper()
+
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		b.Fatal(err)
+	}
+
+	return data
+}
+
+func loadOrdered(b *testing.B, raw []byte) *utils.OrderedMap[string, any] {
+	b.Helper()
+
+	data := utils.NewOrderedMap[string, any]()
+	if err := data.UnmarshalJSON(raw); err != nil {
+		b.Fatal(err)
+	}
+
+	return data
+}
+
+func BenchmarkQuery_ItemsName(b *testing.B) {
+	raw, err := os.ReadFile(testSource)
 	if err != nil {
-		panic("failed to read " + testSource + ": " + err.Error())
+		b.Skipf("%s not available: %v", testSource, err)
+	}
+
+	data := loadMap(b, raw)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := jsonpath.Query(data, "items.#.itemName"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQuery_10k_ItemsName(b *testing.B) {
+	data := loadMap(b, makeScanJSON(largeItemCount))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		vals, err := jsonpath.Query(data, "items.#.name")
+		if err != nil || len(vals) != largeItemCount {
+			b.Fatalf("unexpected result: %d values, %v", len(vals), err)
+		}
+	}
+}
+
+func BenchmarkQuery_10k_Filter(b *testing.B) {
+	data := loadMap(b, makeScanJSON(largeItemCount))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := jsonpath.Query(data, "items[?(@.type=='directory')].name"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQueryWithOptions_10k_InjectNil(b *testing.B) {
+	data := loadMap(b, makeScanJSON(largeItemCount))
+	opts := jsonpath.Options{Missing: jsonpath.MissingNil, CollectErrors: true}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		vals, _, err := jsonpath.QueryWithOptions(data, "items.#.metadata.title", opts)
+		if err != nil || len(vals) != largeItemCount {
+			b.Fatalf("unexpected result: %d values, %v", len(vals), err)
+		}
 	}
+}
+
+func BenchmarkSet_10k_OrderedMap(b *testing.B) {
+	data := loadOrdered(b, makeScanJSON(largeItemCount))
+	b.ResetTimer()
 
-	if err := json.Unmarshal(data, &testData); err != nil {
-		panic("failed to parse test JSON: " + err.Error())
+	for i := 0; i < b.N; i++ {
+		if err := jsonpath.Set(data, "items.#.metadata.title", "x"); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
 
-func BenchmarkResolvePath_ItemsName(b *testing.B) {
+func BenchmarkNormalize_10k(b *testing.B) {
+	raw := makeScanJSON(largeItemCount)
+	cfg := &normalizer.Config{
+		Fields: []normalizer.Field{
+			{
+				Name: "items.#.name",
+				Actions: []normalizer.Action{
+					{Type: "extract", Target: "items.#.metadata.title", Params: map[string]any{"pattern": "title"}},
+					{Type: "extract", Target: "items.#.metadata.year", Params: map[string]any{"pattern": "bracketYear"}},
+				},
+			},
+			{
+				Name: "items.#",
+				Actions: []normalizer.Action{
+					{Type: "format", Target: "items.#.slug", Params: map[string]any{
+						"template": "{{metadata.title | slugify}}{{if metadata.year}}-{{metadata.year}}{{end}}",
+					}},
+				},
+			},
+		},
+	}
+
 	for i := 0; i < b.N; i++ {
-		_, err := normalizer.ResolvePath(testData, "items.#.itemName")
-		if err != nil {
+		b.StopTimer()
+		data := loadOrdered(b, raw)
+		b.StartTimer()
+
+		if _, err := normalizer.New(cfg).Normalize(data); err != nil {
 			b.Fatal(err)
 		}
 	}
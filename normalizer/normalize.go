@@ -14,13 +14,17 @@ type Normalizer struct {
 	Fields  []Field
 	Meta    map[string]any
 	Targets map[string]any
+
+	perItem  bool // streaming: an empty match is normal, not worth a log line
+	validate bool // streaming: check each item against the schema
 }
 
 func New(config *Config) *Normalizer {
 	return &Normalizer{
-		Fields:  config.Fields,
-		Meta:    make(map[string]any),
-		Targets: make(map[string]any),
+		Fields:   config.Fields,
+		Meta:     make(map[string]any),
+		Targets:  make(map[string]any),
+		validate: config.Validate,
 	}
 }
 
@@ -61,22 +65,22 @@ func (n *Normalizer) setCurrent(key string, val any) {
 
 func (n *Normalizer) Normalize(data any) (any, error) {
 	for _, field := range n.Fields {
-		value, err := jsonpath.Get(data, field.Name)
+		matches, err := jsonpath.QueryMatches(data, field.Name)
 		if err != nil {
-			return nil, fmt.Errorf("field %q not found: %v", field.Name, err)
+			return nil, fmt.Errorf("field %q: %v", field.Name, err)
 		}
 
-		switch v := value.(type) {
-		case []any:
-			for i, val := range v {
-				key := strings.ReplaceAll(field.Name, "#", strconv.Itoa(i))
-				if err := n.processField(data, field, key, val, i); err != nil {
-					return nil, err
-				}
+		if len(matches) == 0 {
+			if n.perItem {
+				continue
 			}
 
-		default:
-			if err := n.processField(data, field, field.Name, v, -1); err != nil {
+			log.Printf("field %q matched nothing", field.Name)
+			continue
+		}
+
+		for _, m := range matches {
+			if err := n.processField(data, field, m.Path(), m.Value, m.Indexes()); err != nil {
 				return nil, err
 			}
 		}
@@ -91,7 +95,7 @@ func (n *Normalizer) processField(
 	field Field,
 	key string,
 	val any,
-	idx int,
+	indexes []int,
 ) error {
 	// store original once
 	orig := n.ensureOriginal(key, val)
@@ -99,7 +103,7 @@ func (n *Normalizer) processField(
 	// run actions
 	current := orig
 	for _, action := range field.Actions {
-		result, err := n.applyAction(data, action, current, idx)
+		result, err := n.applyAction(data, action, current, indexes)
 		if err != nil {
 			log.Printf("field %q action %q skipped: %v", key, action.Type, err)
 			continue
@@ -116,7 +120,7 @@ func (n *Normalizer) applyAction(
 	data any,
 	action Action,
 	input any,
-	idx int,
+	indexes []int,
 ) (any, error) {
 	registry := registries.GetRegistry()
 
@@ -125,15 +129,30 @@ func (n *Normalizer) applyAction(
 		return nil, err
 	}
 
+	// named captures fan out to one target per group
+	if captures, ok := result.(registries.Captures); ok {
+		for name, val := range captures {
+			target := resolveTarget(captureTarget(action, name), indexes)
+			if target == "" || val == "" {
+				continue
+			}
+
+			if err := jsonpath.Set(data, target, val); err != nil {
+				return nil, fmt.Errorf("set %q failed: %v", target, err)
+			}
+
+			n.Targets[target] = val
+		}
+
+		return input, nil
+	}
+
 	// update target immediately if defined
 	if action.Target != "" {
 		// omit if result is "empty"
 		if result != nil && result != "" {
 
-			target := action.Target
-			if idx >= 0 {
-				target = strings.ReplaceAll(target, "#", strconv.Itoa(idx))
-			}
+			target := resolveTarget(action.Target, indexes)
 
 			if err := jsonpath.Set(data, target, result); err != nil {
 				return nil, fmt.Errorf("set %q failed: %v", target, err)
@@ -150,3 +169,41 @@ func (n *Normalizer) applyAction(
 
 	return input, nil
 }
+
+// captureTarget resolves where a named capture is written: an explicit
+// params.targets entry wins, otherwise {name} in the action target is
+// replaced by the group name. Groups without a target are dropped.
+func captureTarget(action Action, name string) string {
+	if targets, ok := action.Params["targets"].(map[string]any); ok {
+		if t, ok := targets[name].(string); ok && t != "" {
+			return t
+		}
+	}
+
+	if strings.Contains(action.Target, "{name}") {
+		return strings.ReplaceAll(action.Target, "{name}", name)
+	}
+
+	return ""
+}
+
+// resolveTarget replaces each '#' in target with the index the source field
+// was matched at, outermost first. Extra '#' reuse the innermost index.
+func resolveTarget(target string, indexes []int) string {
+	if len(indexes) == 0 || !strings.Contains(target, "#") {
+		return target
+	}
+
+	parts := strings.Split(target, ".")
+	next := 0
+	for i, p := range parts {
+		if p != "#" {
+			continue
+		}
+
+		parts[i] = strconv.Itoa(indexes[min(next, len(indexes)-1)])
+		next++
+	}
+
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,62 @@
+package normalizer_test
+
+import (
+	"testing"
+
+	"github.com/mrizkifadil26/medix/normalizer"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+func TestNormalize_RegexCaptures(t *testing.T) {
+	data := utils.NewOrderedMap[string, any]()
+	if err := data.UnmarshalJSON([]byte(`{"items":[{"name":"Alien (1979)"},{"name":"Heat (1995)"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &normalizer.Config{
+		Fields: []normalizer.Field{
+			{
+				Name: "items.#.name",
+				Actions: []normalizer.Action{
+					{
+						Type:   "extract",
+						Target: "items.#.parsed.{name}",
+						Params: map[string]any{
+							"pattern": "regex",
+							"regex":   `^(?P<title>.+?) \((?P<year>\d{4})\)$`,
+							"targets": map[string]any{"title": "items.#.metadata.title"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	n := normalizer.New(cfg)
+	if _, err := n.Normalize(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// params.targets wins over {name}; the other group falls back to it,
+	// and '#' is the index of the item the name came from
+	want := map[string]string{
+		"items.0.metadata.title": "Alien",
+		"items.0.parsed.year":    "1979",
+		"items.1.metadata.title": "Heat",
+		"items.1.parsed.year":    "1995",
+	}
+	for path, val := range want {
+		got, err := jsonpath.Get(data, path)
+		if err != nil || got != val {
+			t.Errorf("%s = %v, %v; want %q", path, got, err, val)
+		}
+		if n.Targets[path] != val {
+			t.Errorf("Targets[%s] = %v, want %q", path, n.Targets[path], val)
+		}
+	}
+
+	if got, err := jsonpath.Get(data, "items.0.parsed.title"); err == nil {
+		t.Errorf("title also written to its {name} target: %v", got)
+	}
+}
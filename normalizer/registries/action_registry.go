@@ -5,9 +5,13 @@ import (
 )
 
 type ActionTypeRegistry interface {
-	Apply(input any, params map[string]any) (string, error)
+	Apply(input any, params map[string]any) (any, error)
 }
 
+// Captures is returned by actions that produce several named values at once
+// (e.g. regex capture groups). Each value is written to its own target.
+type Captures map[string]string
+
 type ActionRegistry struct {
 	registries map[string]ActionTypeRegistry
 }
@@ -0,0 +1,68 @@
+package normalizer
+
+import (
+	"strings"
+
+	"github.com/mrizkifadil26/medix/schema"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+// NormalizeFile normalizes inPath into outPath one item at a time instead of
+// loading the whole document. Field paths keep their usual "items.#..." form
+// and are evaluated against a document holding only the current item, so
+// selectors over other top-level keys match nothing in this mode. With
+// Config.Validate the first item that breaks the normalized schema stops
+// the stream and outPath is left untouched.
+func (n *Normalizer) NormalizeFile(inPath, outPath string) error {
+	key := n.itemsKey()
+
+	return utils.StreamFile(inPath, outPath, utils.StreamOptions{Key: key},
+		func(idx int, item any) (any, error) {
+			out, err := n.NormalizeItem(key, item)
+			if err != nil {
+				return nil, err
+			}
+
+			if n.validate {
+				if err := schema.ValidateItem(schema.Normalized, idx, out); err != nil {
+					return nil, err
+				}
+			}
+
+			return out, nil
+		})
+}
+
+// NormalizeItem runs every field on a single item. Each call uses fresh
+// Meta and Targets so items do not see each other's originals.
+func (n *Normalizer) NormalizeItem(key string, item any) (any, error) {
+	root := utils.NewOrderedMap[string, any]()
+	root.Set(key, []any{item})
+
+	single := &Normalizer{
+		Fields:  n.Fields,
+		Meta:    make(map[string]any),
+		Targets: make(map[string]any),
+		perItem: true,
+	}
+
+	if _, err := single.Normalize(root); err != nil {
+		return nil, err
+	}
+
+	items, _ := root.Get(key)
+	return items.([]any)[0], nil
+}
+
+// itemsKey is the top-level array the fields iterate over, taken from the
+// first field path and defaulting to "items".
+func (n *Normalizer) itemsKey() string {
+	for _, f := range n.Fields {
+		name := strings.TrimPrefix(strings.TrimPrefix(f.Name, "$"), ".")
+		if i := strings.IndexAny(name, ".["); i > 0 {
+			return name[:i]
+		}
+	}
+
+	return "items"
+}
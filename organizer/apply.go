@@ -0,0 +1,31 @@
+package organizer
+
+import (
+	"fmt"
+
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+)
+
+// Apply carries out the plan in order through j, which checks every step
+// and records it so `medix undo` can reverse a run that stopped half way.
+// It stops at the first failure. Moves are renames, so Source and Target
+// must be on the same volume.
+func Apply(plan *Plan, j *fsjournal.Journal) (done int, err error) {
+	for _, op := range plan.Ops {
+		switch op.Kind {
+		case OpMkdir:
+			err = j.MkdirAll(op.Dst)
+		case OpMove:
+			err = j.Move(op.Src, op.Dst)
+		default:
+			err = fmt.Errorf("unknown operation %q", op.Kind)
+		}
+		if err != nil {
+			return done, err
+		}
+
+		done++
+	}
+
+	return done, nil
+}
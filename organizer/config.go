@@ -0,0 +1,23 @@
+// Package organizer moves and renames media folders, main videos and their
+// subtitles into the layout named by a template, e.g.
+// <Genre>/<Title> (<Year>)/<Title> (<Year>).mkv. A Plan is computed from an
+// enriched output first, so it can be shown as a dry run, and Apply makes
+// every change through an fsjournal.Journal so it can be undone.
+package organizer
+
+type Config struct {
+	Input  string `json:"input"`            // enriched output to organize
+	Source string `json:"source,omitempty"` // library root the item paths are relative to; default the input's source_path
+	Target string `json:"target,omitempty"` // root of the new layout; default Source
+
+	// Folder is a formatter template for the item folder relative to
+	// Target, e.g. "{{enriched.genres.0}}/{{metadata.title}} ({{metadata.year}})".
+	// "/" separates folders.
+	Folder string `json:"folder"`
+
+	// File names the main video, without extension; default the last
+	// folder of Folder. Subtitles get the same name plus their language.
+	File string `json:"file,omitempty"`
+
+	JournalDir string `json:"journal_dir,omitempty"` // default fsjournal.DefaultDir
+}
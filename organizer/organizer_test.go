@@ -0,0 +1,211 @@
+package organizer_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/organizer"
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+)
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(filepath.Base(path)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+const items = `[
+  {
+    "slug": "spider-man-homecoming-2017",
+    "type": "directory",
+    "rel_path": "Spider-Man Homecoming (2017)",
+    "metadata": {"title": "Spider-Man: Homecoming", "year": "2017"},
+    "enriched": {"tmdb_id": 315635, "title": "Spider-Man: Homecoming", "genres": ["Action", "Adventure"]},
+    "media": {"path": "Spider-Man Homecoming (2017)/Spider-Man.Homecoming.2017.1080p.mkv", "ext": ".mkv"},
+    "subtitles": {
+      "en": {"path": "Spider-Man Homecoming (2017)/Subs/English.srt", "ext": ".srt"},
+      "en-forced": {"path": "Spider-Man Homecoming (2017)/Spider-Man.Homecoming.2017.eng.forced.srt", "ext": ".srt"}
+    }
+  },
+  {
+    "slug": "alien-1979",
+    "type": "file",
+    "rel_path": "Alien.1979.720p.mkv",
+    "metadata": {"title": "Alien", "year": "1979"},
+    "enriched": {"tmdb_id": 348, "title": "Alien", "genres": ["Horror"]}
+  },
+  {
+    "slug": "no-genre",
+    "type": "directory",
+    "rel_path": "Unknown (2001)",
+    "metadata": {"title": "Unknown", "year": "2001"}
+  },
+  {
+    "slug": "heat-1995",
+    "type": "directory",
+    "rel_path": "Heat (1995)",
+    "metadata": {"title": "Heat", "year": "1995"},
+    "enriched": {"tmdb_id": 949, "title": "Heat", "genres": ["Crime"]}
+  }
+]`
+
+func TestPlanApplyUndo(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "TODO")
+	dst := filepath.Join(root, "Movies")
+
+	touch(t, filepath.Join(src, "Spider-Man Homecoming (2017)", "Spider-Man.Homecoming.2017.1080p.mkv"))
+	touch(t, filepath.Join(src, "Spider-Man Homecoming (2017)", "Subs", "English.srt"))
+	touch(t, filepath.Join(src, "Spider-Man Homecoming (2017)", "Spider-Man.Homecoming.2017.eng.forced.srt"))
+	touch(t, filepath.Join(src, "Alien.1979.720p.mkv"))
+	touch(t, filepath.Join(src, "Heat (1995)", "Heat.mkv"))
+	touch(t, filepath.Join(dst, "Crime", "Heat (1995)", "Heat (1995).mkv")) // already in the library
+
+	var list []any
+	if err := json.Unmarshal([]byte(items), &list); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &organizer.Config{
+		Source: src,
+		Target: dst,
+		Folder: "{{enriched.genres.0}}/{{metadata.title}} ({{metadata.year}})",
+	}
+
+	plan, err := organizer.NewPlan(cfg, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Skipped) != 2 {
+		t.Errorf("skipped = %+v, want the item without genres and the taken folder", plan.Skipped)
+	}
+
+	// nothing happens until Apply
+	if exists(dst + "/Action") {
+		t.Fatal("planning touched the disk")
+	}
+
+	journals := filepath.Join(root, "journal")
+	j, err := fsjournal.Begin(journals, "organize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, err := organizer.Apply(plan, j)
+	j.Close()
+	if err != nil || done != len(plan.Ops) {
+		t.Fatalf("Apply = %d, %v; want %d steps", done, err, len(plan.Ops))
+	}
+
+	movie := filepath.Join(dst, "Action", "Spider-Man - Homecoming (2017)")
+	for _, path := range []string{
+		filepath.Join(movie, "Spider-Man - Homecoming (2017).mkv"),
+		filepath.Join(movie, "Spider-Man - Homecoming (2017).en.srt"),
+		filepath.Join(movie, "Spider-Man - Homecoming (2017).en.forced.srt"),
+		filepath.Join(dst, "Horror", "Alien (1979)", "Alien (1979).mkv"),
+	} {
+		if !exists(path) {
+			t.Errorf("missing %s", path)
+		}
+	}
+
+	// every folder created counts, so there can be more steps than ops
+	if undone, err := fsjournal.Undo(journals, j.ID()); err != nil || undone < done {
+		t.Fatalf("Undo = %d, %v; want at least %d", undone, err, done)
+	}
+
+	for _, path := range []string{
+		filepath.Join(src, "Spider-Man Homecoming (2017)", "Subs", "English.srt"),
+		filepath.Join(src, "Alien.1979.720p.mkv"),
+	} {
+		if !exists(path) {
+			t.Errorf("not restored: %s", path)
+		}
+	}
+	if exists(filepath.Join(dst, "Action")) || exists(filepath.Join(dst, "Horror")) {
+		t.Error("created folders left behind")
+	}
+}
+
+func TestPlanMultiTrackIdx(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "TODO")
+	dst := filepath.Join(root, "Movies")
+
+	touch(t, filepath.Join(src, "Deadpool (2016)", "Movie.2016.mkv"))
+	touch(t, filepath.Join(src, "Deadpool (2016)", "Movie.2016.idx"))
+	touch(t, filepath.Join(src, "Deadpool (2016)", "Movie.2016.sub"))
+
+	// one .idx with an English and a Spanish track
+	var list []any
+	if err := json.Unmarshal([]byte(`[{
+	  "slug": "deadpool-2016",
+	  "type": "directory",
+	  "rel_path": "Deadpool (2016)",
+	  "metadata": {"title": "Deadpool", "year": "2016"},
+	  "media": {"path": "Deadpool (2016)/Movie.2016.mkv", "ext": ".mkv"},
+	  "subtitles": {
+	    "en": {"path": "Deadpool (2016)/Movie.2016.idx", "ext": ".idx", "track": 0},
+	    "es": {"path": "Deadpool (2016)/Movie.2016.idx", "ext": ".idx", "track": 1}
+	  }
+	}]`), &list); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &organizer.Config{Source: src, Target: dst, Folder: "{{metadata.title}} ({{metadata.year}})"}
+	plan, err := organizer.NewPlan(cfg, list)
+	if err != nil || len(plan.Skipped) != 0 {
+		t.Fatalf("NewPlan = %+v, %v", plan, err)
+	}
+
+	j, err := fsjournal.Begin(filepath.Join(root, "journal"), "organize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, err := organizer.Apply(plan, j)
+	j.Close()
+	if err != nil || done != len(plan.Ops) {
+		t.Fatalf("Apply = %d, %v; want %d steps", done, err, len(plan.Ops))
+	}
+
+	movie := filepath.Join(dst, "Deadpool (2016)")
+	for _, name := range []string{"Deadpool (2016).mkv", "Deadpool (2016).idx", "Deadpool (2016).sub"} {
+		if !exists(filepath.Join(movie, name)) {
+			t.Errorf("missing %s", name)
+		}
+	}
+
+	// two items for one file would move it twice
+	touch(t, filepath.Join(src, "Alien.1979.mkv"))
+	list = []any{
+		map[string]any{"slug": "alien-1979", "type": "file", "rel_path": "Alien.1979.mkv", "metadata": map[string]any{"title": "Alien", "year": "1979"}},
+		map[string]any{"slug": "aliens-1986", "type": "file", "rel_path": "Alien.1979.mkv", "metadata": map[string]any{"title": "Aliens", "year": "1986"}},
+	}
+	if _, err := organizer.NewPlan(cfg, list); err == nil {
+		t.Error("planned to move one file twice")
+	}
+}
+
+func TestSafeName(t *testing.T) {
+	tests := map[string]string{
+		"Spider-Man: Homecoming": "Spider-Man - Homecoming",
+		`What "If"?`:             "What If",
+		"Mission: Impossible...": "Mission - Impossible",
+	}
+	for in, want := range tests {
+		if got := organizer.SafeName(in); got != want {
+			t.Errorf("SafeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package organizer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/normalizer/actions/formatter"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+// Operation kinds.
+const (
+	OpMkdir = "mkdir"
+	OpMove  = "move"
+)
+
+// Op is one step of a plan. Paths are absolute; a move of a folder is
+// followed by the renames inside it, whose Src is already the new folder.
+type Op struct {
+	Kind string `json:"op"`
+	Src  string `json:"src,omitempty"`
+	Dst  string `json:"dst"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// Skip is an item the plan leaves alone.
+type Skip struct {
+	Slug   string `json:"slug,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type Plan struct {
+	Ops     []Op   `json:"ops"`
+	Skipped []Skip `json:"skipped,omitempty"`
+}
+
+// PlanFile plans the items of cfg.Input. Source defaults to the input's
+// source_path.
+func PlanFile(cfg Config) (*Plan, error) {
+	var doc struct {
+		SourcePath string `json:"source_path"`
+		Items      []any  `json:"items"`
+	}
+	if err := utils.LoadJSON(cfg.Input, &doc); err != nil {
+		return nil, fmt.Errorf("load %s: %w", cfg.Input, err)
+	}
+
+	if cfg.Source == "" {
+		cfg.Source = doc.SourcePath
+	}
+
+	return NewPlan(&cfg, doc.Items)
+}
+
+// NewPlan works out the moves and renames for every item of an enriched
+// output. An item whose names cannot be rendered, or whose destination is
+// taken on disk or by an earlier item, is skipped as a whole. Items that
+// would move one file twice make the whole plan fail.
+func NewPlan(cfg *Config, items []any) (*Plan, error) {
+	if cfg.Folder == "" {
+		return nil, errors.New("organizer: no folder template")
+	}
+	if cfg.Source == "" {
+		return nil, errors.New("organizer: no source directory")
+	}
+
+	target := cfg.Target
+	if target == "" {
+		target = cfg.Source
+	}
+
+	p := &planner{
+		cfg:     cfg,
+		target:  target,
+		claimed: map[string]bool{},
+		dirs:    map[string]bool{},
+		plan:    &Plan{Ops: []Op{}},
+	}
+
+	for _, item := range items {
+		slug := str(item, "slug")
+		ops, err := p.item(item)
+		if err != nil {
+			p.plan.Skipped = append(p.plan.Skipped, Skip{Slug: slug, Path: str(item, "rel_path"), Reason: err.Error()})
+			continue
+		}
+
+		for i := range ops {
+			ops[i].Slug = slug
+			p.claimed[key(ops[i].Dst)] = true
+			if ops[i].Kind == OpMkdir {
+				p.dirs[key(ops[i].Dst)] = true
+			}
+		}
+		p.plan.Ops = append(p.plan.Ops, ops...)
+	}
+
+	// a file moved twice would be gone by the second move, half way
+	// through Apply
+	moves := map[string]bool{}
+	for _, op := range p.plan.Ops {
+		if op.Kind != OpMove {
+			continue
+		}
+		if moves[key(op.Src)] {
+			return nil, fmt.Errorf("organizer: %s would be moved twice", op.Src)
+		}
+		moves[key(op.Src)] = true
+	}
+
+	return p.plan, nil
+}
+
+type planner struct {
+	cfg     *Config
+	target  string
+	claimed map[string]bool // destinations of earlier items
+	dirs    map[string]bool // folders earlier items create
+	plan    *Plan
+}
+
+// item plans one item: a directory is moved as a whole and then its files
+// renamed, a single file gets a new folder.
+func (p *planner) item(item any) ([]Op, error) {
+	rel := str(item, "rel_path")
+	if rel == "" {
+		return nil, errors.New("no rel_path")
+	}
+
+	folder, err := p.render(item, p.cfg.Folder)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Base(folder)
+	if p.cfg.File != "" {
+		name, err := p.render(item, p.cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		base = filepath.Base(name)
+	}
+
+	srcDir := p.abs(rel)
+	dstDir := filepath.Join(p.target, folder)
+
+	var (
+		ops   []Op
+		from  string // the folder that moves to dstDir, if any
+		loose = str(item, "type") != "directory"
+	)
+
+	if loose {
+		// a single video gets a new folder of its own
+		ops = append(ops, p.mkdir(dstDir)...)
+	} else if srcDir != dstDir {
+		if err := p.free(dstDir, srcDir); err != nil {
+			return nil, err
+		}
+		ops = append(ops, p.mkdir(filepath.Dir(dstDir))...)
+		ops = append(ops, Op{Kind: OpMove, Src: srcDir, Dst: dstDir})
+		from = srcDir
+	}
+
+	// files are renamed where they are after the folder move
+	moved := func(path string) string {
+		return rebase(path, from, dstDir)
+	}
+
+	var files []Op
+	if media := str(item, "media.path"); media != "" {
+		src := moved(p.abs(media))
+		files = append(files, Op{Kind: OpMove, Src: src, Dst: filepath.Join(dstDir, base+filepath.Ext(src))})
+	} else if loose {
+		src := moved(p.abs(rel))
+		files = append(files, Op{Kind: OpMove, Src: src, Dst: filepath.Join(dstDir, base+filepath.Ext(src))})
+	}
+
+	for _, sub := range subtitles(item) {
+		src := moved(p.abs(sub.path))
+		dst := filepath.Join(dstDir, base+sub.suffix+sub.ext)
+		files = append(files, Op{Kind: OpMove, Src: src, Dst: dst})
+
+		// the .sub half of a VobSub pair goes along with its .idx
+		if strings.EqualFold(sub.ext, ".idx") {
+			pair := strings.TrimSuffix(p.abs(sub.path), sub.ext) + ".sub"
+			if _, err := os.Stat(pair); err == nil {
+				files = append(files, Op{Kind: OpMove, Src: moved(pair), Dst: strings.TrimSuffix(dst, sub.ext) + ".sub"})
+			}
+		}
+	}
+
+	// check the files against what is in the folder today, before any move
+	original := func(path string) string {
+		return rebase(path, dstDir, from)
+	}
+
+	seen := map[string]bool{}
+	for _, op := range files {
+		if op.Src == op.Dst {
+			continue
+		}
+		if seen[key(op.Dst)] {
+			return nil, fmt.Errorf("two files would be named %s", filepath.Base(op.Dst))
+		}
+		seen[key(op.Dst)] = true
+
+		if err := p.free(original(op.Dst), original(op.Src)); err != nil {
+			return nil, err
+		}
+		if p.claimed[key(op.Dst)] {
+			return nil, fmt.Errorf("%s is planned for another item", op.Dst)
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+// rebase moves path from under the folder old to under new; paths
+// elsewhere, or any path when old is empty, are returned as they are.
+func rebase(path, old, new string) string {
+	if old == "" {
+		return path
+	}
+	if rest, err := filepath.Rel(old, path); err == nil && rest != ".." && !strings.HasPrefix(rest, ".."+string(filepath.Separator)) {
+		return filepath.Join(new, rest)
+	}
+	return path
+}
+
+// render fills a template and makes each folder of it a valid name.
+func (p *planner) render(item any, tmpl string) (string, error) {
+	out, err := formatter.DefaultFormatter(item, tmpl)
+	if err != nil {
+		return "", fmt.Errorf("template %q: %w", tmpl, err)
+	}
+	if strings.Contains(out, "[unknown]") {
+		return "", fmt.Errorf("template %q: missing value", tmpl)
+	}
+
+	var parts []string
+	for _, part := range strings.Split(out, "/") {
+		if part = SafeName(part); part != "" && part != "." && part != ".." {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("template %q: empty name", tmpl)
+	}
+
+	return filepath.Join(parts...), nil
+}
+
+// free fails when dst exists on disk or is claimed. A change of case
+// only is fine when both names are one file on disk; on a case-sensitive
+// filesystem they can be two, and dst must not be overwritten.
+func (p *planner) free(dst, src string) error {
+	if dst == src {
+		return nil
+	}
+	if key(dst) != key(src) && p.claimed[key(dst)] {
+		return fmt.Errorf("%s is planned for another item", dst)
+	}
+	if _, err := os.Lstat(dst); err == nil && !fsjournal.SameFile(dst, src) {
+		return fmt.Errorf("%s already exists", dst)
+	}
+
+	return nil
+}
+
+// mkdir plans dir unless it exists or an earlier item creates it.
+func (p *planner) mkdir(dir string) []Op {
+	if p.dirs[key(dir)] {
+		return nil
+	}
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return nil
+	}
+
+	return []Op{{Kind: OpMkdir, Dst: dir}}
+}
+
+func (p *planner) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(p.cfg.Source, filepath.FromSlash(path))
+}
+
+type subtitle struct {
+	path, ext, suffix string
+}
+
+// subtitles lists the item's subtitle files in key order. The key becomes
+// the name suffix: "en-forced" is Title (2016).en.forced.srt. A file with
+// several tracks, such as a VobSub .idx, is listed once and named without
+// a language, since it holds them all.
+func subtitles(item any) []subtitle {
+	v, err := jsonpath.Get(item, "subtitles")
+	if err != nil {
+		return nil
+	}
+
+	subs, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(subs))
+	for k := range subs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tracks := map[string]int{}
+	for _, k := range keys {
+		if path := str(subs[k], "path"); path != "" {
+			tracks[path]++
+		}
+	}
+
+	var out []subtitle
+	for _, k := range keys {
+		path := str(subs[k], "path")
+		if path == "" || tracks[path] == 0 {
+			continue
+		}
+
+		ext := str(subs[k], "ext")
+		if ext == "" {
+			ext = filepath.Ext(path)
+		}
+
+		suffix := "." + strings.ReplaceAll(k, "-", ".")
+		if tracks[path] > 1 {
+			suffix = ""
+		}
+		tracks[path] = 0 // listed
+
+		out = append(out, subtitle{path: path, ext: ext, suffix: suffix})
+	}
+
+	return out
+}
+
+// SafeName makes s a valid file name on Windows: "Title: Sub" becomes
+// "Title - Sub" and the other reserved characters are dropped.
+func SafeName(s string) string {
+	s = strings.ReplaceAll(s, ": ", " - ")
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '\\':
+			return '-'
+		case '<', '>', '"', '|', '?', '*':
+			return -1
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, s)
+
+	return strings.TrimRight(strings.TrimSpace(strings.Join(strings.Fields(s), " ")), ". ")
+}
+
+func str(item any, path string) string {
+	v, err := jsonpath.Get(item, path)
+	if err != nil {
+		return ""
+	}
+	s, _ := v.(string)
+	return s
+}
+
+// key compares paths the way Windows does, case-insensitively.
+func key(path string) string {
+	return strings.ToLower(filepath.Clean(path))
+}
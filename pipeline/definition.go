@@ -0,0 +1,139 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+// Definition is a pipeline file. Stages under Template are expanded once per
+// entry in Labels, replacing {label} in every string field, which covers the
+// usual scan → normalize → enrich chain per library. Pipelines can also be
+// listed explicitly. Label pipelines are independent of each other and run
+// in parallel; After runs once all of them have succeeded (e.g. webgen).
+type Definition struct {
+	Parallel int    `json:"parallel,omitempty" yaml:"parallel,omitempty"` // label pipelines running at once, default 1
+	State    string `json:"state,omitempty" yaml:"state,omitempty"`       // input hash store, default DefaultStatePath
+
+	Labels    []string   `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Template  []Stage    `json:"template,omitempty" yaml:"template,omitempty"`
+	Pipelines []Pipeline `json:"pipelines,omitempty" yaml:"pipelines,omitempty"`
+	After     []Stage    `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+// Pipeline is an ordered list of stages; each stage usually reads the
+// previous stage's output.
+type Pipeline struct {
+	Name   string  `json:"name" yaml:"name"`
+	Stages []Stage `json:"stages" yaml:"stages"`
+}
+
+// Stage is one step of a pipeline.
+type Stage struct {
+	Name   string `json:"name" yaml:"name"`                         // unique within its pipeline
+	Run    string `json:"run" yaml:"run"`                           // registered stage kind: scan, normalize, enrich, webgen
+	Config string `json:"config,omitempty" yaml:"config,omitempty"` // stage config file
+	Input  string `json:"input,omitempty" yaml:"input,omitempty"`   // data file (or directory for webgen)
+	Output string `json:"output" yaml:"output"`
+	Stream bool   `json:"stream,omitempty" yaml:"stream,omitempty"` // process items one at a time
+
+	// Validate checks the output against the stage schema (scan, normalize
+	// and enrich) and fails the stage instead of writing drifted output.
+	Validate bool `json:"validate,omitempty" yaml:"validate,omitempty"`
+
+	// Watch lists extra files or directories whose content invalidates the
+	// stage, e.g. templates used by webgen.
+	Watch []string `json:"watch,omitempty" yaml:"watch,omitempty"`
+}
+
+const (
+	DefaultStatePath = ".medix/pipeline.state.json"
+	afterPipeline    = "after"
+)
+
+// LoadDefinition reads a pipeline file (JSON or YAML).
+func LoadDefinition(path string) (*Definition, error) {
+	def, err := utils.LoadConfig[Definition](path)
+	if err != nil {
+		return nil, err
+	}
+
+	if def.State == "" {
+		def.State = DefaultStatePath
+	}
+
+	return &def, def.validate()
+}
+
+// Expand returns the label pipelines: the template expanded per label
+// followed by the explicit pipelines.
+func (d *Definition) Expand() []Pipeline {
+	var out []Pipeline
+	for _, label := range d.Labels {
+		p := Pipeline{Name: label}
+		for _, st := range d.Template {
+			p.Stages = append(p.Stages, st.withLabel(label))
+		}
+
+		out = append(out, p)
+	}
+
+	return append(out, d.Pipelines...)
+}
+
+func (s Stage) withLabel(label string) Stage {
+	r := strings.NewReplacer("{label}", label)
+
+	s.Name = r.Replace(s.Name)
+	s.Config = r.Replace(s.Config)
+	s.Input = r.Replace(s.Input)
+	s.Output = r.Replace(s.Output)
+
+	watch := make([]string, len(s.Watch))
+	for i, w := range s.Watch {
+		watch[i] = r.Replace(w)
+	}
+	s.Watch = watch
+
+	return s
+}
+
+func (d *Definition) validate() error {
+	seen := map[string]bool{}
+
+	check := func(p Pipeline) error {
+		if p.Name == "" {
+			return fmt.Errorf("pipeline without a name")
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate pipeline %q", p.Name)
+		}
+		seen[p.Name] = true
+
+		stages := map[string]bool{}
+		for _, st := range p.Stages {
+			if st.Name == "" {
+				return fmt.Errorf("pipeline %q: stage without a name", p.Name)
+			}
+			if stages[st.Name] {
+				return fmt.Errorf("pipeline %q: duplicate stage %q", p.Name, st.Name)
+			}
+			stages[st.Name] = true
+
+			if _, ok := stageRegistry.Get(st.Run); !ok {
+				return fmt.Errorf("pipeline %q stage %q: unknown run %q", p.Name, st.Name, st.Run)
+			}
+		}
+
+		return nil
+	}
+
+	for _, p := range d.Expand() {
+		if err := check(p); err != nil {
+			return err
+		}
+	}
+
+	return check(Pipeline{Name: afterPipeline, Stages: d.After})
+}
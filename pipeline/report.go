@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+type Status string
+
+const (
+	StatusRan     Status = "ran"
+	StatusSkipped Status = "skipped" // inputs unchanged since the last success
+	StatusFailed  Status = "failed"
+	StatusBlocked Status = "blocked" // an earlier stage failed or the run was cancelled
+)
+
+// StageResult is the outcome of one stage.
+type StageResult struct {
+	Pipeline   string `json:"pipeline"`
+	Stage      string `json:"stage"`
+	Run        string `json:"run"`
+	Status     Status `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+
+	Error     string `json:"error,omitempty"`
+	ItemIndex *int   `json:"item_index,omitempty"` // failing item, when known
+	ItemName  string `json:"item_name,omitempty"`
+}
+
+// Report lists every stage in definition order.
+type Report struct {
+	StartedAt  string        `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+	Results    []StageResult `json:"results"`
+}
+
+func newResult(p string, st Stage, status Status, took time.Duration, err error) StageResult {
+	r := StageResult{
+		Pipeline:   p,
+		Stage:      st.Name,
+		Run:        st.Run,
+		Status:     status,
+		DurationMs: took.Milliseconds(),
+	}
+
+	if err != nil {
+		r.Error = err.Error()
+
+		var itemErr *utils.ItemError
+		if errors.As(err, &itemErr) {
+			idx := itemErr.Index
+			r.ItemIndex = &idx
+			r.ItemName = itemErr.Name
+		}
+	}
+
+	return r
+}
+
+// Failures returns the failed stages.
+func (r *Report) Failures() []StageResult {
+	var out []StageResult
+	for _, res := range r.Results {
+		if res.Status == StatusFailed {
+			out = append(out, res)
+		}
+	}
+
+	return out
+}
+
+// Err summarises the failures, or returns nil if every stage ran or was
+// skipped.
+func (r *Report) Err() error {
+	failed := r.Failures()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, len(failed))
+	for i, f := range failed {
+		msgs[i] = f.Pipeline + "/" + f.Stage
+	}
+
+	return fmt.Errorf("%d stage(s) failed: %s", len(failed), strings.Join(msgs, ", "))
+}
+
+// Print writes a human readable summary.
+func (r *Report) Print(w io.Writer) {
+	icons := map[Status]string{
+		StatusRan:     "✅",
+		StatusSkipped: "⏭️ ",
+		StatusFailed:  "❌",
+		StatusBlocked: "⛔",
+	}
+
+	fmt.Fprintln(w, "📋 Pipeline report")
+	for _, res := range r.Results {
+		fmt.Fprintf(w, "  %s %-24s %-10s %-8s %6dms\n",
+			icons[res.Status], res.Pipeline+"/"+res.Stage, res.Run, res.Status, res.DurationMs)
+
+		if res.Status != StatusFailed {
+			continue
+		}
+
+		if res.ItemIndex != nil {
+			item := fmt.Sprintf("#%d", *res.ItemIndex)
+			if res.ItemName != "" {
+				item += " " + res.ItemName
+			}
+			fmt.Fprintf(w, "      item: %s\n", item)
+		}
+		fmt.Fprintf(w, "      error: %s\n", res.Error)
+	}
+
+	fmt.Fprintf(w, "⏱️ Total: %dms\n", r.DurationMs)
+}
@@ -0,0 +1,157 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mrizkifadil26/medix/utils/concurrency"
+)
+
+// Options tunes a run.
+type Options struct {
+	Force    bool     // ignore stored hashes and run every stage
+	Only     []string // run only these label pipelines (After is skipped)
+	Parallel int      // overrides Definition.Parallel when > 0
+}
+
+// Run executes the label pipelines, at most Parallel at a time, then the
+// After stages if all of them succeeded. A failing stage blocks the rest of
+// its own pipeline only. The report is always returned; use Report.Err to
+// check for failures.
+func Run(ctx context.Context, def *Definition, opts Options) (*Report, error) {
+	start := time.Now()
+	st := loadState(def.State)
+
+	pipelines := def.Expand()
+	if len(opts.Only) > 0 {
+		pipelines = slices.DeleteFunc(pipelines, func(p Pipeline) bool {
+			return !slices.Contains(opts.Only, p.Name)
+		})
+
+		if len(pipelines) == 0 {
+			return nil, fmt.Errorf("no pipeline matches %v", opts.Only)
+		}
+	}
+
+	parallel := def.Parallel
+	if opts.Parallel > 0 {
+		parallel = opts.Parallel
+	}
+
+	exec := concurrency.SequentialExecutor()
+	if parallel > 1 {
+		exec = concurrency.GoroutineExecutor(parallel)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		results = make([][]StageResult, len(pipelines))
+	)
+
+	for i, p := range pipelines {
+		wg.Add(1)
+
+		err := exec(ctx, func(ctx context.Context) error {
+			defer wg.Done()
+			results[i] = runPipeline(ctx, p.Name, p.Stages, st, opts.Force)
+			return nil
+		})
+		if err != nil {
+			// cancelled before the pipeline could start
+			results[i] = blockAll(p.Name, p.Stages, err)
+			wg.Done()
+		}
+	}
+
+	wg.Wait()
+
+	report := &Report{StartedAt: start.Format(time.RFC3339)}
+	ok := true
+	for _, res := range results {
+		report.Results = append(report.Results, res...)
+		for _, r := range res {
+			ok = ok && r.Status != StatusFailed && r.Status != StatusBlocked
+		}
+	}
+
+	if len(def.After) > 0 && len(opts.Only) == 0 {
+		if ok {
+			report.Results = append(report.Results,
+				runPipeline(ctx, afterPipeline, def.After, st, opts.Force)...)
+		} else {
+			report.Results = append(report.Results,
+				blockAll(afterPipeline, def.After, fmt.Errorf("a label pipeline failed"))...)
+		}
+	}
+
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	if err := st.save(); err != nil {
+		return report, fmt.Errorf("save pipeline state: %w", err)
+	}
+
+	return report, nil
+}
+
+func runPipeline(ctx context.Context, name string, stages []Stage, st *state, force bool) []StageResult {
+	results := make([]StageResult, 0, len(stages))
+
+	for i, stage := range stages {
+		if err := ctx.Err(); err != nil {
+			return append(results, blockAll(name, stages[i:], err)...)
+		}
+
+		res := runStage(ctx, name, stage, st, force)
+		results = append(results, res)
+
+		if res.Status == StatusFailed {
+			return append(results, blockAll(name, stages[i+1:], fmt.Errorf("stage %q failed", stage.Name))...)
+		}
+	}
+
+	return results
+}
+
+func runStage(ctx context.Context, pipeline string, stage Stage, st *state, force bool) StageResult {
+	start := time.Now()
+	kind, _ := stageRegistry.Get(stage.Run)
+	key := pipeline + "/" + stage.Name
+
+	sum, err := stageHash(stage)
+	if err != nil {
+		return newResult(pipeline, stage, StatusFailed, time.Since(start), fmt.Errorf("hash inputs: %w", err))
+	}
+
+	if !force && !kind.AlwaysRun && st.get(key) == sum && exists(stage.Output) {
+		return newResult(pipeline, stage, StatusSkipped, time.Since(start), nil)
+	}
+
+	if err := kind.Run(ctx, stage); err != nil {
+		return newResult(pipeline, stage, StatusFailed, time.Since(start), err)
+	}
+
+	st.set(key, sum)
+	return newResult(pipeline, stage, StatusRan, time.Since(start), nil)
+}
+
+func blockAll(pipeline string, stages []Stage, cause error) []StageResult {
+	out := make([]StageResult, len(stages))
+	for i, s := range stages {
+		out[i] = newResult(pipeline, s, StatusBlocked, 0, cause)
+	}
+
+	return out
+}
+
+func exists(path string) bool {
+	if path == "" {
+		return true
+	}
+
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,112 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/pipeline"
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+var copies atomic.Int32
+
+func init() {
+	// copy: output is the input file, counting runs
+	pipeline.Register("test-copy", pipeline.Kind{Run: func(_ context.Context, st pipeline.Stage) error {
+		copies.Add(1)
+
+		data, err := os.ReadFile(st.Input)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(st.Output, data, 0644)
+	}})
+
+	pipeline.Register("test-fail", pipeline.Kind{Run: func(_ context.Context, st pipeline.Stage) error {
+		return utils.NewItemError(4, nil, errors.New("boom"))
+	}})
+}
+
+func TestRun_SkipsUnchangedStages(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "a.in")
+	os.WriteFile(in, []byte("v1"), 0644)
+
+	def := &pipeline.Definition{
+		State:  filepath.Join(dir, "state.json"),
+		Labels: []string{"a"},
+		Template: []pipeline.Stage{
+			{Name: "one", Run: "test-copy", Input: filepath.Join(dir, "{label}.in"), Output: filepath.Join(dir, "{label}.one")},
+			{Name: "two", Run: "test-copy", Input: filepath.Join(dir, "{label}.one"), Output: filepath.Join(dir, "{label}.two")},
+		},
+	}
+
+	run := func() []pipeline.Status {
+		t.Helper()
+
+		report, err := pipeline.Run(context.Background(), def, pipeline.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var statuses []pipeline.Status
+		for _, r := range report.Results {
+			statuses = append(statuses, r.Status)
+		}
+		return statuses
+	}
+
+	copies.Store(0)
+	run()
+	if got := run(); got[0] != pipeline.StatusSkipped || got[1] != pipeline.StatusSkipped {
+		t.Errorf("expected second run to skip, got %v", got)
+	}
+	if copies.Load() != 2 {
+		t.Errorf("expected 2 stage runs, got %d", copies.Load())
+	}
+
+	os.WriteFile(in, []byte("v2"), 0644)
+	if got := run(); got[0] != pipeline.StatusRan || got[1] != pipeline.StatusRan {
+		t.Errorf("expected changed input to rerun the chain, got %v", got)
+	}
+}
+
+func TestRun_ReportsFailingItem(t *testing.T) {
+	dir := t.TempDir()
+
+	def := &pipeline.Definition{
+		State:    filepath.Join(dir, "state.json"),
+		Parallel: 2,
+		Pipelines: []pipeline.Pipeline{
+			{Name: "bad", Stages: []pipeline.Stage{
+				{Name: "enrich", Run: "test-fail", Output: filepath.Join(dir, "bad.out")},
+				{Name: "next", Run: "test-fail", Output: filepath.Join(dir, "bad.next")},
+			}},
+		},
+		After: []pipeline.Stage{{Name: "site", Run: "test-fail"}},
+	}
+
+	report, err := pipeline.Run(context.Background(), def, pipeline.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Err() == nil {
+		t.Fatal("expected report error")
+	}
+
+	failed := report.Failures()
+	if len(failed) != 1 || failed[0].Stage != "enrich" || failed[0].ItemIndex == nil || *failed[0].ItemIndex != 4 {
+		t.Errorf("unexpected failures %+v", failed)
+	}
+
+	last := report.Results[len(report.Results)-1]
+	if report.Results[1].Status != pipeline.StatusBlocked || last.Status != pipeline.StatusBlocked {
+		t.Errorf("expected later stages to be blocked, got %+v", report.Results)
+	}
+}
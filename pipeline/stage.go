@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+// StageFunc runs one stage. Errors caused by a single item should wrap a
+// *utils.ItemError so the report can point at it.
+type StageFunc func(ctx context.Context, st Stage) error
+
+// Kind is a registered stage type, referenced by Stage.Run.
+type Kind struct {
+	Run StageFunc
+
+	// AlwaysRun marks stages whose real input is not a file that can be
+	// hashed, such as scan reading a directory tree. Later stages are still
+	// skipped when the output they read did not change.
+	AlwaysRun bool
+}
+
+var stageRegistry = utils.NewRegistry[Kind]()
+
+// Register adds a stage kind.
+func Register(name string, k Kind) {
+	stageRegistry.Register(name, k)
+}
@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mrizkifadil26/medix/enricher"
+	_ "github.com/mrizkifadil26/medix/enricher/local"
+	_ "github.com/mrizkifadil26/medix/enricher/tmdb"
+	scanner "github.com/mrizkifadil26/medix/legacy/scanner"
+	"github.com/mrizkifadil26/medix/normalizer"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/extractor"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/formatter"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/replacer"
+	_ "github.com/mrizkifadil26/medix/normalizer/actions/transformer"
+	"github.com/mrizkifadil26/medix/schema"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/webgen"
+)
+
+func init() {
+	Register("scan", Kind{Run: runScan, AlwaysRun: true})
+	Register("normalize", Kind{Run: runNormalize})
+	Register("enrich", Kind{Run: runEnrich})
+	Register("webgen", Kind{Run: runWebgen})
+}
+
+// runScan writes the JSON scan output for a scanner config. The root comes
+// from the config unless the stage sets Input.
+func runScan(_ context.Context, st Stage) error {
+	config := scanner.DefaultConfig()
+
+	fileConfig, err := utils.LoadConfig[scanner.Config](st.Config)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if err := utils.MergeInto(&config, &fileConfig, utils.MergeOptions{
+		Overwrite: true,
+		Recursive: true,
+	}); err != nil {
+		return err
+	}
+
+	if st.Input != "" {
+		config.Root = st.Input
+	}
+
+	result, err := scanner.Scan(config.Root, *config.Options, *config.Output, config.Tags)
+	if err != nil {
+		return err
+	}
+
+	if st.Validate {
+		if err := schema.Validate(schema.Scan, result); err != nil {
+			return err
+		}
+	}
+
+	// keep the previous file when only timings changed, so later stages
+	// see identical input and can be skipped
+	var previous scanner.ScanOutput
+	if err := utils.LoadJSON(st.Output, &previous); err == nil && sameScan(previous, result) {
+		return nil
+	}
+
+	return utils.WriteJSON(st.Output, result)
+}
+
+// sameScan compares the parts of two scans that do not depend on when the
+// scan ran. Both go through a JSON round trip first, since a fresh scan may
+// hold empty slices where a decoded one has nil.
+func sameScan(a, b scanner.ScanOutput) bool {
+	content := func(o scanner.ScanOutput) []byte {
+		var rt scanner.ScanOutput
+		raw, _ := json.Marshal(o)
+		_ = json.Unmarshal(raw, &rt)
+
+		raw, _ = json.Marshal([]any{rt.SourcePath, rt.Mode, rt.Tags, rt.Items, rt.Errors})
+		return raw
+	}
+
+	return bytes.Equal(content(a), content(b))
+}
+
+func runNormalize(_ context.Context, st Stage) error {
+	config, err := utils.LoadConfig[normalizer.Config](st.Config)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if st.Input != "" {
+		config.Root = st.Input
+	}
+	config.Validate = config.Validate || st.Validate
+
+	n := normalizer.New(&config)
+	if st.Stream {
+		return n.NormalizeFile(config.Root, st.Output)
+	}
+
+	data := utils.NewOrderedMap[string, any]()
+	if err := utils.LoadJSON(config.Root, data); err != nil {
+		return err
+	}
+
+	result, err := n.Normalize(data)
+	if err != nil {
+		return err
+	}
+
+	if config.Validate {
+		if err := schema.Validate(schema.Normalized, result); err != nil {
+			return err
+		}
+	}
+
+	return utils.WriteJSON(st.Output, result)
+}
+
+func runEnrich(ctx context.Context, st Stage) error {
+	config, err := enricher.LoadConfig(st.Config)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if st.Input != "" {
+		config.Root = st.Input
+	}
+	config.Output = st.Output
+	config.Validate = config.Validate || st.Validate
+
+	if st.Stream {
+		return enricher.EnrichFile(ctx, config.Root, config.Output, &config)
+	}
+
+	data := utils.NewOrderedMap[string, any]()
+	if err := utils.LoadJSON(config.Root, data); err != nil {
+		return err
+	}
+
+	enriched, err := enricher.Enrich(ctx, data, &config)
+	if err != nil {
+		return err
+	}
+
+	if config.Validate {
+		if err := schema.Validate(schema.Enriched, enriched); err != nil {
+			return err
+		}
+	}
+
+	return utils.WriteJSON(config.Output, enriched)
+}
+
+func runWebgen(_ context.Context, st Stage) error {
+	return webgen.GenerateSite(st.Input, st.Output)
+}
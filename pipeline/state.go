@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+// state remembers the input hash each stage last succeeded with.
+type state struct {
+	path string
+
+	mu     sync.Mutex
+	Hashes map[string]string `json:"hashes"` // "pipeline/stage" → sha256
+}
+
+func loadState(path string) *state {
+	s := &state{path: path, Hashes: map[string]string{}}
+
+	// a missing or corrupt state only means everything runs once
+	_ = utils.LoadJSON(path, s)
+	if s.Hashes == nil {
+		s.Hashes = map[string]string{}
+	}
+
+	return s
+}
+
+func (s *state) get(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Hashes[key]
+}
+
+func (s *state) set(key, sum string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Hashes[key] = sum
+}
+
+func (s *state) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return utils.WriteJSON(s.path, s)
+}
+
+// stageHash covers the stage definition itself plus the content of its
+// config, input and watched paths. Directories are hashed file by file in
+// path order; missing paths hash as missing, so creating them counts as a
+// change.
+func stageHash(st Stage) (string, error) {
+	h := sha256.New()
+
+	def, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+	h.Write(def)
+
+	paths := append([]string{st.Config, st.Input}, st.Watch...)
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+
+		if err := hashPath(h, p); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashPath(h hash.Hash, path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		io.WriteString(h, "\x00missing:"+path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return hashFile(h, path, path)
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(files)
+	for _, f := range files {
+		rel, _ := filepath.Rel(path, f)
+		if err := hashFile(h, f, rel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hashFile(h hash.Hash, path, label string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	io.WriteString(h, "\x00file:"+label+"\x00")
+	_, err = io.Copy(h, f)
+	return err
+}
@@ -1,9 +1,10 @@
-package main
+// Package progress counts icon work per genre across the RAW, PNG and ICO
+// folders of an icon pack.
+package progress
 
 import (
-	"encoding/json"
-	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -16,7 +17,7 @@ type GenreStatus struct {
 	Png    int    `json:"png"`
 	Ico    int    `json:"ico"`
 	Status string `json:"status"`
-	Icon   string `json:"icon"` // ➕ Add this line
+	Icon   string `json:"icon"`
 }
 
 type Progress struct {
@@ -26,6 +27,8 @@ type Progress struct {
 	Percent int           `json:"percent"`
 }
 
+var formats = []string{"RAW", "PNG", "ICO"}
+
 var genreIcons = map[string]string{
 	"Action":      "🔥",
 	"Comedy":      "😂",
@@ -46,24 +49,18 @@ var genreIcons = map[string]string{
 	"War":         "⚔️",
 }
 
-func main() {
-	baseDir := "/mnt/c/Users/Rizki/OneDrive/Pictures/Icons/Personal Icon Pack/Movies"
-	outputPath := flag.String("out", "data/progress.json", "Output JSON file")
-	flag.Parse()
-
-	formats := []string{"RAW", "PNG", "ICO"}
-	formatDirs := map[string]string{}
-	for _, format := range formats {
-		formatDirs[format] = filepath.Join(baseDir, format)
+// Collect counts files per genre under baseDir/{RAW,PNG,ICO}/<Genre>,
+// ignoring Collection folders. A genre is done when all three counts match.
+func Collect(baseDir string) (*Progress, error) {
+	if _, err := os.Stat(baseDir); err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("Scanning directories: %s\n", formatDirs)
-
 	genreSet := make(map[string]bool)
 	table := make(map[string]map[string]int)
 
 	for _, format := range formats {
-		base := formatDirs[format]
+		base := filepath.Join(baseDir, format)
 		if _, err := os.Stat(base); os.IsNotExist(err) {
 			continue
 		}
@@ -105,9 +102,7 @@ func main() {
 		return table[genres[i]]["RAW"] > table[genres[j]]["RAW"]
 	})
 
-	fmt.Printf("\033[1;32m%-15s%8s%8s%8s%10s\033[0m\n", "Genre", "RAW", "PNG", "ICO", "Completed")
-
-	var progress Progress
+	progress := &Progress{}
 	for _, genre := range genres {
 		raw := table[genre]["RAW"]
 		png := table[genre]["PNG"]
@@ -119,17 +114,13 @@ func main() {
 			progress.Done++
 		}
 
-		icon := genreIcons[genre]
-		genreDisplay := fmt.Sprintf("%s %s", icon, genre)
-		fmt.Printf("%-15s%8d%8d%8d%10s\n", genreDisplay, raw, png, ico, status)
-
 		progress.Genres = append(progress.Genres, GenreStatus{
 			Genre:  genre,
 			Raw:    raw,
 			Png:    png,
 			Ico:    ico,
 			Status: status,
-			Icon:   icon,
+			Icon:   genreIcons[genre],
 		})
 	}
 
@@ -137,25 +128,25 @@ func main() {
 	if progress.Total > 0 {
 		progress.Percent = (progress.Done * 100) / progress.Total
 	}
-	barWidth := 50
-	filled := (progress.Done * barWidth) / progress.Total
-	bar := strings.Repeat("#", filled) + strings.Repeat("-", barWidth-filled)
-	fmt.Printf("\nProgress: [%s] %d%% (%d/%d genres completed)\n",
-		bar, progress.Percent, progress.Done, progress.Total)
-
-	os.MkdirAll(filepath.Dir(*outputPath), 0755)
-	f, err := os.Create(*outputPath)
-	if err != nil {
-		fmt.Printf("❌ Error writing JSON: %v\n", err)
-		return
+
+	return progress, nil
+}
+
+// Print writes the per-genre table and a progress bar.
+func (p *Progress) Print(w io.Writer) {
+	fmt.Fprintf(w, "\033[1;32m%-15s%8s%8s%8s%10s\033[0m\n", "Genre", "RAW", "PNG", "ICO", "Completed")
+
+	for _, g := range p.Genres {
+		genreDisplay := fmt.Sprintf("%s %s", g.Icon, g.Genre)
+		fmt.Fprintf(w, "%-15s%8d%8d%8d%10s\n", genreDisplay, g.Raw, g.Png, g.Ico, g.Status)
 	}
-	defer f.Close()
 
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(progress); err != nil {
-		fmt.Printf("❌ Failed to encode JSON: %v\n", err)
-		return
+	barWidth := 50
+	filled := 0
+	if p.Total > 0 {
+		filled = (p.Done * barWidth) / p.Total
 	}
-	fmt.Printf("✅ JSON written to %s\n", *outputPath)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", barWidth-filled)
+	fmt.Fprintf(w, "\nProgress: [%s] %d%% (%d/%d genres completed)\n",
+		bar, p.Percent, p.Done, p.Total)
 }
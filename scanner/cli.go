@@ -6,14 +6,18 @@ import (
 )
 
 type CLIArgs struct {
+	Args       []string // positional arguments after the flags
 	ConfigPath *string
 	Config     Config // Full config with all options
 }
 
-func ParseCLI() (*CLIArgs, error) {
-	flag.Usage = func() {
+// ParseCLI parses the scan subcommand flags. ConfigPath is nil when -config
+// is not given.
+func ParseCLI(argv []string) (*CLIArgs, error) {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.Usage = func() {
 		helpText := `
-Usage: scan [OPTIONS]
+Usage: medix scan [OPTIONS]
 
 Options:
   -config      Path to config file (JSON or YAML)
@@ -24,26 +28,28 @@ Options:
   -verbose     Enable verbose logging
 
 Example:
-  scan -root ./media -mode dirs -depth 2 -verbose
+  medix scan -root ./media -mode dirs -depth 2 -verbose
 
 If -config is provided, it overrides everything except -output.
+
+Scan stores files in the database and writes no scan JSON, so there is no
+-validate. Use "validate": true on the pipeline's scan stage, or
+medix validate -stage scan <file>, to check a JSON scan.
 `
 		fmt.Println(helpText)
 	}
 
 	var (
-		configPath = flag.String("config", "", "Path to config file (JSON or YAML)")
-		outputPath = flag.String("output", "", "Output result path")
-		root       = flag.String("root", "", "Root directory to scan")
-		mode       = flag.String("mode", "", "Scan mode: files, dirs, or mixed")
-		depth      = flag.Int("depth", -1, "Max scan depth")
-		verbose    = flag.Bool("verbose", false, "Enable verbose logging")
+		configPath = fs.String("config", "", "Path to config file (JSON or YAML)")
+		outputPath = fs.String("output", "", "Output result path")
+		root       = fs.String("root", "", "Root directory to scan")
+		mode       = fs.String("mode", "", "Scan mode: files, dirs, or mixed")
+		depth      = fs.Int("depth", -1, "Max scan depth")
+		verbose    = fs.Bool("verbose", false, "Enable verbose logging")
 	)
 
-	flag.Parse()
-
-	if configPath == nil || *configPath == "" {
-		return nil, fmt.Errorf("missing required -config argument")
+	if err := fs.Parse(argv); err != nil {
+		return nil, err
 	}
 
 	var cfg Config
@@ -53,6 +59,9 @@ If -config is provided, it overrides everything except -output.
 		cfg.Root = *root
 		shouldPopulate = true
 	}
+	if (mode != nil && *mode != "") || (depth != nil && *depth != -1) || (verbose != nil && *verbose) {
+		cfg.Options = &ScanOptions{}
+	}
 	if mode != nil && *mode != "" {
 		cfg.Options.Mode = *mode
 		shouldPopulate = true
@@ -74,8 +83,10 @@ If -config is provided, it overrides everything except -output.
 		shouldPopulate = true
 	}
 
-	args := &CLIArgs{
-		ConfigPath: configPath,
+	args := &CLIArgs{}
+	args.Args = fs.Args()
+	if *configPath != "" {
+		args.ConfigPath = configPath
 	}
 
 	if shouldPopulate {
@@ -0,0 +1,156 @@
+// Package schema holds the JSON Schemas for the output of each stage and
+// validates documents or single items against them.
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/jsonschema"
+)
+
+type Stage string
+
+const (
+	Scan       Stage = "scan"
+	Normalized Stage = "normalized"
+	Enriched   Stage = "enriched"
+)
+
+// Stages lists every stage in pipeline order.
+var Stages = []Stage{Scan, Normalized, Enriched}
+
+var (
+	//go:embed scan.schema.json
+	scanSchema []byte
+	//go:embed normalized.schema.json
+	normalizedSchema []byte
+	//go:embed enriched.schema.json
+	enrichedSchema []byte
+)
+
+var schemas = map[Stage]*jsonschema.Schema{
+	Scan:       jsonschema.MustParse(scanSchema),
+	Normalized: jsonschema.MustParse(normalizedSchema),
+	Enriched:   jsonschema.MustParse(enrichedSchema),
+}
+
+// ParseStage accepts a stage name as given on the command line.
+func ParseStage(name string) (Stage, error) {
+	switch Stage(name) {
+	case Scan, "scanned":
+		return Scan, nil
+	case Normalized, "normalize":
+		return Normalized, nil
+	case Enriched, "enrich":
+		return Enriched, nil
+	}
+
+	return "", fmt.Errorf("unknown stage %q (want scan, normalized or enriched)", name)
+}
+
+// StageFromPath guesses the stage from the output directory layout,
+// e.g. output/normalized/media/movies.json.
+func StageFromPath(path string) (Stage, bool) {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if stage, err := ParseStage(part); err == nil {
+			return stage, true
+		}
+	}
+
+	return "", false
+}
+
+// ValidationError is returned when a document or item breaks its schema.
+type ValidationError struct {
+	Stage      Stage
+	Violations []jsonschema.Violation
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 1 {
+		return fmt.Sprintf("%s schema: %s", e.Stage, e.Violations[0])
+	}
+
+	return fmt.Sprintf("%s schema: %s (and %d more)", e.Stage, e.Violations[0], len(e.Violations)-1)
+}
+
+// Check validates a whole stage output and returns every violation.
+func Check(stage Stage, doc any) ([]jsonschema.Violation, error) {
+	s, ok := schemas[stage]
+	if !ok {
+		return nil, fmt.Errorf("no schema for stage %q", stage)
+	}
+
+	value, err := toJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Validate(value), nil
+}
+
+// Validate is Check that turns violations into a *ValidationError.
+func Validate(stage Stage, doc any) error {
+	violations, err := Check(stage, doc)
+	if err != nil {
+		return err
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Stage: stage, Violations: violations}
+	}
+
+	return nil
+}
+
+// ValidateItem checks one entry of the items array, as produced while
+// streaming. Violation paths are reported as items.<index>....
+func ValidateItem(stage Stage, index int, item any) error {
+	s, ok := schemas[stage]
+	if !ok {
+		return fmt.Errorf("no schema for stage %q", stage)
+	}
+
+	entry, _ := s.Def("entry")
+
+	value, err := toJSON(item)
+	if err != nil {
+		return err
+	}
+
+	if violations := entry.ValidateAt(value, "items."+strconv.Itoa(index)); len(violations) > 0 {
+		return &ValidationError{Stage: stage, Violations: violations}
+	}
+
+	return nil
+}
+
+// toJSON round-trips v through encoding/json so Go structs set by the
+// enrichers are validated exactly as they will be written.
+func toJSON(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var out utils.OrderedMap[string, any]
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
@@ -0,0 +1,71 @@
+package schema_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/schema"
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+// The sample entry at the repository root documents the enriched shape and
+// must stay valid.
+func TestEntryExampleIsValid(t *testing.T) {
+	entry := utils.NewOrderedMap[string, any]()
+	if err := utils.LoadJSON("../schema.entry.json", entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := schema.ValidateItem(schema.Enriched, 0, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	// the documented fields are checked, not just allowed
+	if err := jsonpath.Set(entry, "metadata.imdb_id", "2178470"); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.ValidateItem(schema.Enriched, 0, entry); err == nil {
+		t.Error("accepted an IMDb ID without tt")
+	}
+}
+
+func TestValidateItemReportsDrift(t *testing.T) {
+	item := map[string]any{
+		"path":     "/movies/Alien (1979)",
+		"rel_path": "Alien (1979)",
+		"name":     "Alien (1979)",
+		"type":     "directory",
+		"slug":     "alien-1979",
+		"enriched": map[string]any{"matched_title": "Alien"},
+	}
+
+	err := schema.ValidateItem(schema.Enriched, 4, item)
+
+	var verr *schema.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+
+	for _, v := range verr.Violations {
+		if !strings.HasPrefix(v.Path, "items.4.enriched") {
+			t.Errorf("unexpected violation %s", v)
+		}
+	}
+
+	if err := schema.ValidateItem(schema.Normalized, 4, item); err == nil {
+		t.Error("normalized schema accepted an enriched field")
+	}
+}
+
+func TestStageFromPath(t *testing.T) {
+	stage, ok := schema.StageFromPath("output/normalized/media/movies.year.json")
+	if !ok || stage != schema.Normalized {
+		t.Errorf("got %q, %v", stage, ok)
+	}
+
+	if _, ok := schema.StageFromPath("data/movies.json"); ok {
+		t.Error("expected no stage for data/movies.json")
+	}
+}
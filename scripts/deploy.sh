@@ -4,7 +4,7 @@ set -e
 export $(cat .env.github | xargs)
 
 # Step 1: Build the static site
-go run cmd/webgen/main.go
+go run ./cmd/medix webgen
 
 # Step 2: Prepare temporary gh-pages worktree
 rm -rf /tmp/gh-pages
@@ -3,6 +3,7 @@ package cache
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"sync"
 )
 
@@ -33,7 +34,8 @@ func (m *Manager[T]) Load() error {
 	return json.Unmarshal(data, &m.data)
 }
 
-// Save memory → file
+// Save memory → file. The file is replaced only once fully written, so
+// saving periodically during a run never leaves a truncated cache.
 func (m *Manager[T]) Save() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -43,7 +45,26 @@ func (m *Manager[T]) Save() error {
 		return err
 	}
 
-	return os.WriteFile(m.filepath, enc, 0644)
+	// a temp file of its own, so two writers never share one
+	tmp, err := os.CreateTemp(filepath.Dir(m.filepath), filepath.Base(m.filepath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(enc); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), m.filepath)
 }
 
 func (m *Manager[T]) Has(category, key string) bool {
@@ -5,27 +5,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// LoadConfig reads a JSON or YAML config into T. ${VAR} and ${VAR:-default}
+// references are replaced from the environment first; see ExpandEnv.
 func LoadConfig[T any](path string) (T, error) {
 	var out T
 
-	f, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return out, fmt.Errorf("open config file: %w", err)
 	}
-	defer f.Close()
 
 	switch ext := strings.ToLower(filepath.Ext(path)); ext {
 	case ".json":
-		if err := json.NewDecoder(f).Decode(&out); err != nil {
+		data, err = expandEnv(data, jsonEscape)
+		if err != nil {
+			return out, err
+		}
+
+		if err := json.Unmarshal(data, &out); err != nil {
 			return out, fmt.Errorf("decode JSON: %w", err)
 		}
 	case ".yaml", ".yml":
-		if err := yaml.NewDecoder(f).Decode(&out); err != nil {
+		data, err = expandEnv(data, nil)
+		if err != nil {
+			return out, err
+		}
+
+		if err := yaml.Unmarshal(data, &out); err != nil {
 			return out, fmt.Errorf("decode YAML: %w", err)
 		}
 	default:
@@ -34,3 +46,56 @@ func LoadConfig[T any](path string) (T, error) {
 
 	return out, nil
 }
+
+// envRef matches ${NAME}, ${NAME:-default} and the escaped form $${NAME}.
+// Names must start with a letter or underscore, so regexp replacement
+// templates such as ${1} pass through untouched.
+var envRef = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// ExpandEnv replaces ${NAME} with the value of the environment variable
+// NAME, or with default for ${NAME:-default} when NAME is unset or empty.
+// $${NAME} is left as the literal ${NAME}. Referencing an unset or empty
+// variable without a default is an error, so a missing secret fails loudly instead
+// of becoming an empty string.
+func ExpandEnv(data []byte) ([]byte, error) {
+	return expandEnv(data, nil)
+}
+
+func expandEnv(data []byte, escape func(string) string) ([]byte, error) {
+	var missing []string
+
+	out := envRef.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := envRef.FindSubmatch(m)
+		if len(sub[1]) > 0 {
+			return m[1:]
+		}
+
+		name := string(sub[2])
+		value := os.Getenv(name)
+		if value == "" {
+			if sub[3] == nil {
+				missing = append(missing, name)
+				return m
+			}
+			value = string(sub[3])
+		}
+
+		if escape != nil {
+			value = escape(value)
+		}
+
+		return []byte(value)
+	})
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("undefined environment variable(s): %s", strings.Join(missing, ", "))
+	}
+
+	return out, nil
+}
+
+// jsonEscape makes value safe inside a JSON string literal.
+func jsonEscape(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted[1 : len(quoted)-1])
+}
@@ -0,0 +1,52 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+func TestLoadConfigExpandsEnv(t *testing.T) {
+	t.Setenv("MEDIX_TEST_KEY", `se"cret`)
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+		"key": "${MEDIX_TEST_KEY}",
+		"fallback": "${MEDIX_TEST_UNSET:-none}",
+		"literal": "$${MEDIX_TEST_KEY}",
+		"pattern": "${1} (${2})"
+	}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := utils.LoadConfig[map[string]string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"key":      `se"cret`,
+		"fallback": "none",
+		"literal":  "${MEDIX_TEST_KEY}",
+		"pattern":  "${1} (${2})",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestLoadConfigUndefinedEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("key: ${MEDIX_TEST_UNSET}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := utils.LoadConfig[map[string]string](path); err == nil {
+		t.Error("expected an error for an undefined variable")
+	}
+}
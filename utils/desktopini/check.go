@@ -0,0 +1,235 @@
+package desktopini
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+)
+
+// Status of a folder's icon setup.
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusMissing Status = "missing" // an .ico but no desktop.ini
+	StatusInvalid Status = "invalid"
+)
+
+// Attribute is a file attribute Explorer needs: desktop.ini hidden and
+// system, its folder read-only, or it ignores the file. Linux cannot see
+// or set them, even on a mounted Windows drive, so they are reported as
+// attrib commands to run on Windows.
+type Attribute struct {
+	Path    string `json:"path"`
+	Flags   string `json:"flags"` // e.g. "+s +h"
+	Command string `json:"command"`
+}
+
+// Report is the check of one folder.
+type Report struct {
+	Dir        string      `json:"dir"`
+	Status     Status      `json:"status"`
+	Ini        string      `json:"ini,omitempty"`
+	Encoding   Encoding    `json:"encoding,omitempty"`
+	Icon       string      `json:"icon,omitempty"` // relative, as in IconResource, or the .ico to use
+	Problems   []string    `json:"problems,omitempty"`
+	Attributes []Attribute `json:"attributes,omitempty"`
+}
+
+// Scan checks every folder under root that holds a desktop.ini or an
+// .ico file. Hidden folders such as .medix are skipped.
+func Scan(root string) ([]Report, error) {
+	var reports []Report
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		r, err := Check(path)
+		if err != nil {
+			return err
+		}
+		if r != nil {
+			reports = append(reports, *r)
+		}
+		return nil
+	})
+	return reports, err
+}
+
+// Check reads the desktop.ini of dir and checks that it names an icon by
+// a relative path that exists. A folder with .ico files and no ini gets
+// StatusMissing and the icon to use. Folders with neither return nil.
+func Check(dir string) (*Report, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ini string
+	var icons []string
+	for _, e := range entries {
+		switch {
+		case e.IsDir():
+		case strings.EqualFold(e.Name(), Name):
+			ini = e.Name()
+		case strings.EqualFold(filepath.Ext(e.Name()), ".ico"):
+			icons = append(icons, e.Name())
+		}
+	}
+
+	if ini == "" && len(icons) == 0 {
+		return nil, nil
+	}
+
+	r := &Report{Dir: dir, Status: StatusOK}
+
+	if ini == "" {
+		r.Status = StatusMissing
+		r.Icon = pickIcon(dir, icons)
+		if r.Icon == "" {
+			r.Problems = append(r.Problems, fmt.Sprintf("%d icons, none named after the folder", len(icons)))
+		}
+		return r, nil
+	}
+
+	r.Ini = filepath.Join(dir, ini)
+	f, err := Read(r.Ini)
+	if err != nil {
+		r.Status = StatusInvalid
+		r.Problems = append(r.Problems, err.Error())
+		return r, nil
+	}
+	r.Encoding = f.Encoding
+
+	icon, _, ok := f.Icon()
+	switch {
+	case !ok || icon == "":
+		r.Problems = append(r.Problems, "no IconResource in ["+ShellClassInfo+"]")
+	case IsAbsolute(icon):
+		r.Icon = icon
+		r.Problems = append(r.Problems, "icon path is absolute, it breaks when the library moves: "+icon)
+	default:
+		r.Icon = icon
+		if !existsFold(filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(icon, `\`, "/")))) {
+			r.Problems = append(r.Problems, "icon not found: "+icon)
+		}
+	}
+	if f.Encoding == UTF8 {
+		r.Problems = append(r.Problems, "UTF-8 is not read by Explorer, save it as ANSI or UTF-16")
+	}
+
+	if len(r.Problems) > 0 {
+		r.Status = StatusInvalid
+	}
+	r.Attributes = Attributes(dir, r.Ini)
+	return r, nil
+}
+
+// Generate writes a desktop.ini for a StatusMissing report through the
+// journal, and fills in the attributes it then needs.
+func Generate(r *Report, enc Encoding, j *fsjournal.Journal) error {
+	if r.Status != StatusMissing || r.Icon == "" {
+		return fmt.Errorf("%s: nothing to generate", r.Dir)
+	}
+
+	data, err := New(r.Icon).Encode(enc)
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.Dir, err)
+	}
+
+	r.Ini = filepath.Join(r.Dir, Name)
+	if err := j.WriteFile(r.Ini, data, 0o644); err != nil {
+		return err
+	}
+
+	r.Status = StatusOK
+	r.Attributes = Attributes(r.Dir, r.Ini)
+	return nil
+}
+
+// Attributes lists what Explorer needs set on a folder and its ini.
+func Attributes(dir, ini string) []Attribute {
+	return []Attribute{
+		attribute(dir, "+r"),
+		attribute(ini, "+s +h"),
+	}
+}
+
+func attribute(path, flags string) Attribute {
+	return Attribute{
+		Path:    path,
+		Flags:   flags,
+		Command: fmt.Sprintf(`attrib %s "%s"`, flags, WindowsPath(path)),
+	}
+}
+
+var mountPath = regexp.MustCompile(`^/mnt/([a-zA-Z])(/|$)`)
+
+// WindowsPath turns a WSL path such as /mnt/d/Movies into D:\Movies, so
+// attrib commands can be run from Windows. Other paths only get their
+// separators changed.
+func WindowsPath(path string) string {
+	path = filepath.ToSlash(path)
+	if m := mountPath.FindStringSubmatch(path); m != nil {
+		path = strings.ToUpper(m[1]) + ":/" + path[len(m[0]):]
+	}
+	return strings.ReplaceAll(path, "/", `\`)
+}
+
+// IsAbsolute reports whether an IconResource path is absolute on Windows
+// or here: C:\..., \\server\..., \... or /....
+func IsAbsolute(path string) bool {
+	if len(path) >= 2 && path[1] == ':' {
+		return true
+	}
+	return strings.HasPrefix(path, `\`) || strings.HasPrefix(path, "/")
+}
+
+// pickIcon returns the only .ico in a folder, or the one named after the
+// folder when there are several.
+func pickIcon(dir string, icons []string) string {
+	if len(icons) == 1 {
+		return icons[0]
+	}
+
+	sort.Strings(icons)
+	base := filepath.Base(dir)
+	for _, icon := range icons {
+		if strings.EqualFold(strings.TrimSuffix(icon, filepath.Ext(icon)), base) {
+			return icon
+		}
+	}
+	return ""
+}
+
+// existsFold reports whether path exists, matching the file name without
+// case as Windows does.
+func existsFold(path string) bool {
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.Name(), filepath.Base(path)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+// Package desktopini reads, writes and checks the desktop.ini files that
+// give Windows Explorer folders their icon.
+package desktopini
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Name is the file Explorer looks for in a folder.
+const Name = "desktop.ini"
+
+// ShellClassInfo is the section holding the folder icon.
+const ShellClassInfo = ".ShellClassInfo"
+
+// Encoding of a desktop.ini. Explorer reads UTF-16 LE with a byte order
+// mark or the ANSI code page; UTF-8 only works for plain ASCII.
+type Encoding string
+
+const (
+	Auto  Encoding = "auto"  // ANSI when every character fits, else UTF-16
+	ANSI  Encoding = "ansi"  // Windows-1252
+	UTF16 Encoding = "utf16" // UTF-16 LE with BOM
+	UTF8  Encoding = "utf8"  // only ever read, never written
+)
+
+// Key is one name=value line.
+type Key struct {
+	Name  string
+	Value string
+}
+
+// Section is a [name] and its keys, in file order.
+type Section struct {
+	Name string
+	Keys []Key
+}
+
+// File is a parsed desktop.ini. Sections and keys keep their order and
+// case, so that rewriting a file changes only what was set.
+type File struct {
+	Encoding Encoding
+	Sections []Section
+}
+
+// New returns a desktop.ini that gives the folder icon, a path relative
+// to the folder.
+func New(icon string) *File {
+	f := &File{}
+	f.SetIcon(icon, 0)
+	return f
+}
+
+// Read parses the desktop.ini at path.
+func Read(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes data by its byte order mark and reads its sections.
+// Comments (;) and lines outside a section are dropped.
+func Parse(data []byte) (*File, error) {
+	f := &File{}
+
+	var text string
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		dec, err := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode utf-16: %w", err)
+		}
+		f.Encoding, text = UTF16, string(dec)
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		f.Encoding, text = UTF8, string(data[3:])
+	case utf8.Valid(data) && !isASCII(data):
+		f.Encoding, text = UTF8, string(data)
+	default:
+		dec, err := charmap.Windows1252.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode ansi: %w", err)
+		}
+		f.Encoding, text = ANSI, string(dec)
+	}
+
+	var cur *Section
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		switch {
+		case line == "" || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "["):
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unclosed section %q", i+1, line)
+			}
+			f.Sections = append(f.Sections, Section{Name: strings.TrimSpace(line[1:end])})
+			cur = &f.Sections[len(f.Sections)-1]
+		case cur == nil:
+			continue
+		default:
+			name, value, _ := strings.Cut(line, "=")
+			cur.Keys = append(cur.Keys, Key{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+		}
+	}
+
+	return f, nil
+}
+
+// Get returns the value of key in section; names are case-insensitive
+// as in Windows.
+func (f *File) Get(section, key string) (string, bool) {
+	for _, s := range f.Sections {
+		if !strings.EqualFold(s.Name, section) {
+			continue
+		}
+		for _, k := range s.Keys {
+			if strings.EqualFold(k.Name, key) {
+				return k.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Set replaces the value of key in section, adding either if missing.
+func (f *File) Set(section, key, value string) {
+	for i := range f.Sections {
+		s := &f.Sections[i]
+		if !strings.EqualFold(s.Name, section) {
+			continue
+		}
+		for j := range s.Keys {
+			if strings.EqualFold(s.Keys[j].Name, key) {
+				s.Keys[j].Value = value
+				return
+			}
+		}
+		s.Keys = append(s.Keys, Key{Name: key, Value: value})
+		return
+	}
+	f.Sections = append(f.Sections, Section{Name: section, Keys: []Key{{Name: key, Value: value}}})
+}
+
+// Icon returns the folder icon: IconResource=path,index, or the older
+// IconFile and IconIndex pair.
+func (f *File) Icon() (path string, index int, ok bool) {
+	if v, found := f.Get(ShellClassInfo, "IconResource"); found {
+		path, idx, _ := strings.Cut(v, ",")
+		index, _ = strconv.Atoi(strings.TrimSpace(idx))
+		return strings.TrimSpace(path), index, true
+	}
+	if v, found := f.Get(ShellClassInfo, "IconFile"); found {
+		idx, _ := f.Get(ShellClassInfo, "IconIndex")
+		index, _ = strconv.Atoi(idx)
+		return v, index, true
+	}
+	return "", 0, false
+}
+
+// SetIcon sets IconResource, with Windows path separators.
+func (f *File) SetIcon(path string, index int) {
+	f.Set(ShellClassInfo, "IconResource", strings.ReplaceAll(path, "/", `\`)+","+strconv.Itoa(index))
+}
+
+// Encode writes the file with CRLF line endings in enc. Auto and UTF8
+// pick ANSI when every character has a Windows-1252 byte, else UTF-16.
+func (f *File) Encode(enc Encoding) ([]byte, error) {
+	var b strings.Builder
+	for i, s := range f.Sections {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		fmt.Fprintf(&b, "[%s]\r\n", s.Name)
+		for _, k := range s.Keys {
+			fmt.Fprintf(&b, "%s=%s\r\n", k.Name, k.Value)
+		}
+	}
+	text := b.String()
+
+	if enc != ANSI && enc != UTF16 {
+		enc = ANSI
+		if _, err := charmap.Windows1252.NewEncoder().String(text); err != nil {
+			enc = UTF16
+		}
+	}
+
+	if enc == ANSI {
+		out, err := charmap.Windows1252.NewEncoder().Bytes([]byte(text))
+		if err != nil {
+			return nil, fmt.Errorf("not representable in ANSI, use utf16: %w", err)
+		}
+		return out, nil
+	}
+	return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(text))
+}
+
+func isASCII(data []byte) bool {
+	for _, c := range data {
+		if c >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package desktopini_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/utils/desktopini"
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+)
+
+func TestEncodeParse(t *testing.T) {
+	tests := []struct {
+		icon string
+		want desktopini.Encoding
+	}{
+		{"Amélie.ico", desktopini.ANSI},
+		{"千と千尋の神隠し.ico", desktopini.UTF16},
+	}
+
+	for _, tt := range tests {
+		data, err := desktopini.New(tt.icon).Encode(desktopini.Auto)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.icon, err)
+		}
+		if tt.want == desktopini.UTF16 && !bytes.HasPrefix(data, []byte{0xFF, 0xFE}) {
+			t.Errorf("%s: no UTF-16 byte order mark", tt.icon)
+		}
+		if tt.want == desktopini.ANSI && !bytes.Contains(data, []byte("\r\n")) {
+			t.Errorf("%s: no CRLF line endings", tt.icon)
+		}
+
+		f, err := desktopini.Parse(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		icon, index, ok := f.Icon()
+		if !ok || icon != tt.icon || index != 0 || f.Encoding != tt.want {
+			t.Errorf("%s: got %q,%d %v (%s)", tt.icon, icon, index, ok, f.Encoding)
+		}
+	}
+
+	// the older pair, and names matched without case
+	f, err := desktopini.Parse([]byte("; comment\r\n[.shellclassinfo]\r\niconfile=icons\\Heat.ico\r\nIconIndex=2\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if icon, index, _ := f.Icon(); icon != `icons\Heat.ico` || index != 2 {
+		t.Errorf("IconFile = %q,%d", icon, index)
+	}
+}
+
+func TestCheckGenerate(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(filepath.Join(root, "Alien (1979)", "alien.ico"), "ico")
+	write(filepath.Join(root, "Alien (1979)", "desktop.ini"), "[.ShellClassInfo]\r\nIconResource=Alien.ico,0\r\n")
+	write(filepath.Join(root, "Heat (1995)", "desktop.ini"), "[.ShellClassInfo]\r\nIconResource=C:\\Icons\\Heat.ico,0\r\n")
+	write(filepath.Join(root, "Dune (2021)", "desktop.ini"), "[.ShellClassInfo]\r\nIconResource=Dune.ico,0\r\n")
+	write(filepath.Join(root, "Jaws (1975)", "Jaws (1975).ico"), "ico")
+	write(filepath.Join(root, "Jaws (1975)", "Shark.ico"), "ico")
+	write(filepath.Join(root, "Empty", "movie.mkv"), "video")
+
+	reports, err := desktopini.Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]desktopini.Report{}
+	for _, r := range reports {
+		got[filepath.Base(r.Dir)] = r
+	}
+
+	want := map[string]desktopini.Status{
+		"Alien (1979)": desktopini.StatusOK,
+		"Heat (1995)":  desktopini.StatusInvalid, // absolute
+		"Dune (2021)":  desktopini.StatusInvalid, // icon missing
+		"Jaws (1975)":  desktopini.StatusMissing,
+	}
+	if len(got) != len(want) {
+		t.Errorf("reports for %v", got)
+	}
+	for dir, status := range want {
+		if got[dir].Status != status {
+			t.Errorf("%s: %s %v, want %s", dir, got[dir].Status, got[dir].Problems, status)
+		}
+	}
+
+	jaws := got["Jaws (1975)"]
+	if jaws.Icon != "Jaws (1975).ico" {
+		t.Fatalf("icon to use = %q", jaws.Icon)
+	}
+
+	j, err := fsjournal.Begin(filepath.Join(root, ".medix"), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := desktopini.Generate(&jaws, desktopini.Auto, j); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	r, err := desktopini.Check(jaws.Dir)
+	if err != nil || r.Status != desktopini.StatusOK {
+		t.Fatalf("after Generate: %+v, %v", r, err)
+	}
+	if len(r.Attributes) != 2 || r.Attributes[1].Flags != "+s +h" {
+		t.Errorf("attributes = %+v", r.Attributes)
+	}
+}
+
+func TestWindowsPath(t *testing.T) {
+	if got := desktopini.WindowsPath("/mnt/d/Media/Movies/Alien (1979)"); got != `D:\Media\Movies\Alien (1979)` {
+		t.Errorf("WindowsPath = %q", got)
+	}
+}
@@ -0,0 +1,359 @@
+// Package fsjournal makes changes to the media library reversible. Every
+// move, folder and written file goes through a Journal, which checks that
+// the change is safe before making it and appends it to a JSON-lines file;
+// Undo reverses a whole journal, including one cut short by a crash.
+package fsjournal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+// DefaultDir is where journals go unless a command is told otherwise.
+var DefaultDir = filepath.Join(".medix", "journal")
+
+// Operations.
+const (
+	OpMove  = "move"
+	OpMkdir = "mkdir"
+	OpWrite = "write"
+)
+
+// States of an entry. An operation is journaled as pending before it is
+// made and as done after; one still pending was interrupted and may or may
+// not have happened.
+const (
+	StatePending = "pending"
+	StateDone    = "done"
+	StateUndone  = "undone"
+)
+
+// Entry is one line of a journal. Later lines with the same Seq only
+// update State.
+type Entry struct {
+	Seq      int       `json:"seq"`
+	Op       string    `json:"op,omitempty"`
+	Src      string    `json:"src,omitempty"`
+	Dst      string    `json:"dst,omitempty"`
+	Checksum string    `json:"checksum,omitempty"` // of the file at Dst afterwards
+	Backup   string    `json:"backup,omitempty"`   // copy of a file that OpWrite replaced
+	State    string    `json:"state"`
+	Time     time.Time `json:"time"`
+}
+
+// Journal records one batch of changes. It is safe for concurrent use.
+type Journal struct {
+	id   string
+	dir  string
+	mu   sync.Mutex
+	f    *os.File
+	enc  *json.Encoder
+	seq  int
+	done int
+	bak  int // backups taken, for their names
+}
+
+// Begin starts a new journal in dir (DefaultDir when empty). Its ID is the
+// kind and the time, e.g. organize-20250102-150405.
+func Begin(dir, kind string) (*Journal, error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	id := kind + "-" + time.Now().Format("20060102-150405")
+	for n := 2; ; n++ {
+		f, err := os.OpenFile(journalFile(dir, id), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err == nil {
+			return &Journal{id: id, dir: dir, f: f, enc: json.NewEncoder(f)}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		id = fmt.Sprintf("%s-%s-%d", kind, time.Now().Format("20060102-150405"), n)
+	}
+}
+
+// ID names the journal for Undo.
+func (j *Journal) ID() string { return j.id }
+
+// Done is the number of operations made so far.
+func (j *Journal) Done() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.f.Close()
+}
+
+// Move renames src to dst. dst must not exist, unless it is src under
+// another case on a case-insensitive filesystem, and its folder must.
+func (j *Journal) Move(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("move %s: %w", src, err)
+	}
+	if _, err := os.Lstat(dst); err == nil && !SameFile(src, dst) {
+		return fmt.Errorf("move %s: %s already exists", src, dst)
+	}
+	if _, err := os.Stat(filepath.Dir(dst)); err != nil {
+		return fmt.Errorf("move %s: %w", src, err)
+	}
+
+	var sum string
+	if info.Mode().IsRegular() {
+		if sum, err = QuickChecksum(src); err != nil {
+			return fmt.Errorf("move %s: %w", src, err)
+		}
+	}
+
+	return j.do(Entry{Op: OpMove, Src: src, Dst: dst, Checksum: sum}, func() error {
+		return os.Rename(src, dst)
+	})
+}
+
+// SameFile reports whether both names lead to one file, as a case-only
+// rename does on Windows and macOS. Two files that only differ in case on
+// a case-sensitive filesystem are not the same.
+func SameFile(a, b string) bool {
+	ai, err := os.Lstat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Lstat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
+
+// MkdirAll creates dir and its missing parents, journaling each folder
+// created so Undo can remove it again.
+func (j *Journal) MkdirAll(dir string) error {
+	var missing []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if info, err := os.Stat(d); err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("mkdir %s: %s is not a folder", dir, d)
+			}
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+		missing = append([]string{d}, missing...)
+	}
+
+	for _, d := range missing {
+		if err := j.do(Entry{Op: OpMkdir, Dst: d}, func() error { return os.Mkdir(d, 0o755) }); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteFile writes data to path like os.WriteFile. A file it replaces is
+// copied into the journal folder first, so Undo can put it back.
+func (j *Journal) WriteFile(path string, data []byte, perm os.FileMode) error {
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	sum, err := quickSum(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	e := Entry{Op: OpWrite, Dst: path, Checksum: sum}
+
+	j.mu.Lock()
+	j.bak++
+	n := j.bak
+	j.mu.Unlock()
+
+	if info, err := os.Lstat(path); err == nil {
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("write %s: not a regular file", path)
+		}
+
+		e.Backup = filepath.Join(j.dir, j.id, fmt.Sprintf("%d-%s", n, filepath.Base(path)))
+		if err := os.MkdirAll(filepath.Dir(e.Backup), 0o755); err != nil {
+			return err
+		}
+		if err := utils.CopyFile(path, e.Backup); err != nil {
+			return fmt.Errorf("write %s: backup: %w", path, err)
+		}
+	}
+
+	return j.do(e, func() error { return os.WriteFile(path, data, perm) })
+}
+
+// do journals e as pending, runs fn and journals the outcome.
+func (j *Journal) do(e Entry, fn func() error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	e.Seq = j.seq
+	e.State = StatePending
+	if err := j.append(e); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	j.done++
+	return j.append(Entry{Seq: e.Seq, State: StateDone})
+}
+
+func (j *Journal) append(e Entry) error {
+	e.Time = time.Now().UTC()
+	if err := j.enc.Encode(e); err != nil {
+		return err
+	}
+	return j.f.Sync()
+}
+
+// Info describes a journal on disk.
+type Info struct {
+	ID      string    `json:"id"`
+	Path    string    `json:"path"`
+	Started time.Time `json:"started"`
+	Ops     int       `json:"ops"`    // operations recorded
+	Undone  int       `json:"undone"` // of which reversed
+}
+
+// List returns the journals in dir, newest first.
+func List(dir string) ([]Info, error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []Info
+	for _, path := range matches {
+		entries, err := readJournal(path)
+		if err != nil {
+			return nil, err
+		}
+
+		info := Info{ID: strings.TrimSuffix(filepath.Base(path), ".jsonl"), Path: path}
+		for _, e := range entries {
+			if info.Started.IsZero() {
+				info.Started = e.Time
+			}
+			info.Ops++
+			if e.State == StateUndone {
+				info.Undone++
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(a, b int) bool { return infos[a].Started.After(infos[b].Started) })
+	return infos, nil
+}
+
+// readJournal folds the state updates into one entry per operation, in
+// order. A cut-off last line is ignored.
+func readJournal(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		entries []Entry
+		bySeq   = map[int]int{}
+	)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+
+		if i, ok := bySeq[e.Seq]; ok {
+			entries[i].State = e.State
+			continue
+		}
+		if e.Op == "" {
+			continue
+		}
+
+		bySeq[e.Seq] = len(entries)
+		entries = append(entries, e)
+	}
+
+	return entries, scanner.Err()
+}
+
+func journalFile(dir, id string) string {
+	return filepath.Join(dir, id+".jsonl")
+}
+
+// QuickChecksum identifies a file by its size and SHA-256 of its first and
+// last megabyte, which tells one video from another without reading
+// gigabytes. Files up to 2 MB are hashed whole.
+func QuickChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	return quickSum(f, info.Size())
+}
+
+func quickSum(r io.ReaderAt, size int64) (string, error) {
+	const chunk = 1 << 20
+	h := sha256.New()
+
+	if size <= 2*chunk {
+		if _, err := io.Copy(h, io.NewSectionReader(r, 0, size)); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	fmt.Fprintf(h, "%d:", size)
+	for _, off := range []int64{0, size - chunk} {
+		if _, err := io.Copy(h, io.NewSectionReader(r, off, chunk)); err != nil {
+			return "", err
+		}
+	}
+
+	return "quick:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,165 @@
+package fsjournal_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrizkifadil26/medix/utils/fsjournal"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestUndo(t *testing.T) {
+	root := t.TempDir()
+	journals := filepath.Join(root, "journal")
+	lib := filepath.Join(root, "Movies")
+
+	write(t, filepath.Join(lib, "Alien.1979.mkv"), "video")
+	write(t, filepath.Join(lib, "Heat (1995)", "desktop.ini"), "old")
+
+	j, err := fsjournal.Begin(journals, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folder := filepath.Join(lib, "Horror", "Alien (1979)")
+	steps := []error{
+		j.MkdirAll(folder),
+		j.Move(filepath.Join(lib, "Alien.1979.mkv"), filepath.Join(folder, "Alien (1979).mkv")),
+		j.WriteFile(filepath.Join(folder, "desktop.ini"), []byte("new"), 0o644),
+		j.WriteFile(filepath.Join(lib, "Heat (1995)", "desktop.ini"), []byte("new"), 0o644),
+	}
+	for i, err := range steps {
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+
+	// preconditions: the destination is taken
+	write(t, filepath.Join(lib, "Other.mkv"), "other")
+	if err := j.Move(filepath.Join(lib, "Other.mkv"), filepath.Join(folder, "Alien (1979).mkv")); err == nil {
+		t.Error("Move over an existing file succeeded")
+	}
+	j.Close()
+
+	if got := read(t, filepath.Join(lib, "Heat (1995)", "desktop.ini")); got != "new" {
+		t.Fatalf("desktop.ini = %q", got)
+	}
+
+	undone, err := fsjournal.Undo(journals, j.ID())
+	if err != nil || undone != 5 {
+		t.Fatalf("Undo = %d, %v; want 5 steps", undone, err)
+	}
+
+	if got := read(t, filepath.Join(lib, "Alien.1979.mkv")); got != "video" {
+		t.Errorf("video not moved back: %q", got)
+	}
+	if got := read(t, filepath.Join(lib, "Heat (1995)", "desktop.ini")); got != "old" {
+		t.Errorf("desktop.ini not restored: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(lib, "Horror")); !os.IsNotExist(err) {
+		t.Error("created folder left behind")
+	}
+
+	// a second run has nothing left to do
+	if undone, err := fsjournal.Undo(journals, j.ID()); err != nil || undone != 0 {
+		t.Errorf("second Undo = %d, %v", undone, err)
+	}
+}
+
+// A crash between making a change and journaling it as done leaves it
+// pending; Undo reverses it if it happened.
+func TestUndoInterrupted(t *testing.T) {
+	root := t.TempDir()
+	journals := filepath.Join(root, "journal")
+	src, dst := filepath.Join(root, "a.mkv"), filepath.Join(root, "b.mkv")
+	write(t, dst, "video") // the rename went through
+
+	line, _ := json.Marshal(fsjournal.Entry{
+		Seq: 1, Op: fsjournal.OpMove, Src: src, Dst: dst,
+		State: fsjournal.StatePending, Time: time.Now(),
+	})
+	write(t, filepath.Join(journals, "crash.jsonl"), string(line)+"\n{\"seq\":2,\"op\":\"mo")
+
+	undone, err := fsjournal.Undo(journals, "crash")
+	if err != nil || undone != 1 {
+		t.Fatalf("Undo = %d, %v", undone, err)
+	}
+	if got := read(t, src); got != "video" {
+		t.Errorf("not moved back: %q", got)
+	}
+}
+
+func TestUndoChanged(t *testing.T) {
+	root := t.TempDir()
+	journals := filepath.Join(root, "journal")
+	ini := filepath.Join(root, "desktop.ini")
+
+	j, err := fsjournal.Begin(journals, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.WriteFile(ini, []byte("ours"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	write(t, ini, "edited by hand")
+
+	_, err = fsjournal.Undo(journals, j.ID())
+	if err == nil || !strings.Contains(err.Error(), "changed since") {
+		t.Fatalf("err = %v, want changed since", err)
+	}
+	if got := read(t, ini); got != "edited by hand" {
+		t.Errorf("file overwritten: %q", got)
+	}
+}
+
+// Names that only differ in case are two files on a case-sensitive
+// filesystem; Move must not overwrite one with the other.
+func TestMoveCaseVariants(t *testing.T) {
+	root := t.TempDir()
+	upper, lower := filepath.Join(root, "Movie.mkv"), filepath.Join(root, "movie.mkv")
+	write(t, upper, "A")
+	write(t, lower, "B")
+	if read(t, upper) != "A" {
+		t.Skip("case-insensitive filesystem")
+	}
+
+	j, err := fsjournal.Begin(filepath.Join(root, "journal"), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	if err := j.Move(upper, lower); err == nil {
+		t.Error("Move over a different case variant succeeded")
+	}
+	if got := read(t, lower); got != "B" {
+		t.Errorf("movie.mkv = %q, want B", got)
+	}
+	if got := read(t, upper); got != "A" {
+		t.Errorf("Movie.mkv = %q, want A", got)
+	}
+}
@@ -0,0 +1,139 @@
+package fsjournal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+// Resolve finds a journal by ID in dir, or takes id as the path of one.
+func Resolve(dir, id string) (string, error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+
+	for _, path := range []string{id, journalFile(dir, id)} {
+		if strings.HasSuffix(path, ".jsonl") {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no journal %q in %s", id, dir)
+}
+
+// Undo reverses the journal id in dir, last operation first, and records
+// each reversal in the journal so running it again only retries what
+// failed. Operations left pending by a crash are reversed when they turn
+// out to have happened. Every step is checked first: a moved file must
+// still be where it was moved to, unchanged, and its old name free; a
+// written file must be as written; a folder must be empty. Steps that
+// fail the check are reported and left alone, the rest are still undone.
+func Undo(dir, id string) (undone int, err error) {
+	path, err := Resolve(dir, id)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := readJournal(path)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+
+	var errs []error
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.State == StateUndone {
+			continue
+		}
+		if e.State == StatePending && !happened(e) {
+			continue
+		}
+
+		if err := reverse(e); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		undone++
+		if err := enc.Encode(Entry{Seq: e.Seq, State: StateUndone, Time: time.Now().UTC()}); err != nil {
+			errs = append(errs, err)
+			break
+		}
+	}
+
+	return undone, errors.Join(errs...)
+}
+
+// happened tells whether an operation interrupted before it was journaled
+// as done took place.
+func happened(e Entry) bool {
+	switch e.Op {
+	case OpMove:
+		_, srcErr := os.Lstat(e.Src)
+		_, dstErr := os.Lstat(e.Dst)
+		return dstErr == nil && (srcErr != nil || SameFile(e.Src, e.Dst))
+	case OpMkdir:
+		_, err := os.Stat(e.Dst)
+		return err == nil
+	case OpWrite:
+		sum, err := QuickChecksum(e.Dst)
+		return err == nil && sum == e.Checksum
+	}
+	return false
+}
+
+func reverse(e Entry) error {
+	switch e.Op {
+	case OpMove:
+		if _, err := os.Lstat(e.Dst); err != nil {
+			return fmt.Errorf("undo move %s: %w", e.Dst, err)
+		}
+		if _, err := os.Lstat(e.Src); err == nil && !SameFile(e.Src, e.Dst) {
+			return fmt.Errorf("undo move %s: %s exists again", e.Dst, e.Src)
+		}
+		if e.Checksum != "" {
+			if sum, err := QuickChecksum(e.Dst); err != nil || sum != e.Checksum {
+				return fmt.Errorf("undo move %s: changed since it was moved", e.Dst)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(e.Src), 0o755); err != nil {
+			return fmt.Errorf("undo move %s: %w", e.Dst, err)
+		}
+		return os.Rename(e.Dst, e.Src)
+
+	case OpMkdir:
+		if err := os.Remove(e.Dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("undo mkdir %s: %w", e.Dst, err)
+		}
+		return nil
+
+	case OpWrite:
+		if sum, err := QuickChecksum(e.Dst); err != nil || sum != e.Checksum {
+			return fmt.Errorf("undo write %s: changed since it was written", e.Dst)
+		}
+		if e.Backup == "" {
+			return os.Remove(e.Dst)
+		}
+		if err := utils.CopyFile(e.Backup, e.Dst); err != nil {
+			return fmt.Errorf("undo write %s: %w", e.Dst, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("undo: unknown operation %q", e.Op)
+}
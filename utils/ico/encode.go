@@ -0,0 +1,94 @@
+package ico
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// Encode writes images as one icon, in the order given. Images must be
+// square and at most 256 pixels. The 256 image is stored as PNG, smaller
+// ones as 32-bit BMP, which every Explorer version reads.
+func Encode(w io.Writer, images []image.Image) error {
+	if len(images) == 0 {
+		return fmt.Errorf("no images")
+	}
+
+	blobs := make([][]byte, len(images))
+	for i, img := range images {
+		b := img.Bounds()
+		if b.Dx() != b.Dy() || b.Dx() < 1 || b.Dx() > 256 {
+			return fmt.Errorf("image %d is %dx%d, want square up to 256", i, b.Dx(), b.Dy())
+		}
+
+		var buf bytes.Buffer
+		if b.Dx() == 256 {
+			if err := png.Encode(&buf, img); err != nil {
+				return err
+			}
+		} else {
+			writeBMP(&buf, img)
+		}
+		blobs[i] = buf.Bytes()
+	}
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, []uint16{0, 1, uint16(len(images))})
+
+	offset := 6 + 16*len(images)
+	for i, img := range images {
+		size := img.Bounds().Dx()
+		out.Write([]byte{byte(size % 256), byte(size % 256), 0, 0}) // 0 means 256
+		binary.Write(&out, binary.LittleEndian, []uint16{1, 32})
+		binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(blobs[i])), uint32(offset)})
+		offset += len(blobs[i])
+	}
+	for _, blob := range blobs {
+		out.Write(blob)
+	}
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// writeBMP writes a BITMAPINFOHEADER, bottom-up BGRA rows and the AND
+// mask, which 32-bit icons leave all zero since alpha does the masking.
+func writeBMP(buf *bytes.Buffer, img image.Image) {
+	b := img.Bounds()
+	size := b.Dx()
+	maskRow := (size + 31) / 32 * 4
+
+	binary.Write(buf, binary.LittleEndian, struct {
+		Size          uint32
+		Width, Height int32
+		Planes, Bits  uint16
+		Compression   uint32
+		ImageSize     uint32
+		XPPM, YPPM    int32
+		Used, Import  uint32
+	}{
+		Size:      40,
+		Width:     int32(size),
+		Height:    int32(2 * size), // the color rows and the mask
+		Planes:    1,
+		Bits:      32,
+		ImageSize: uint32(size*size*4 + maskRow*size),
+	})
+
+	row := make([]byte, size*4)
+	for y := b.Max.Y - 1; y >= b.Min.Y; y-- {
+		for x := 0; x < size; x++ {
+			r, g, bl, a := img.At(b.Min.X+x, y).RGBA()
+			if a > 0 {
+				// RGBA is premultiplied, BMP icons are not
+				r, g, bl = r*0xffff/a, g*0xffff/a, bl*0xffff/a
+			}
+			row[4*x], row[4*x+1], row[4*x+2], row[4*x+3] = byte(bl>>8), byte(g>>8), byte(r>>8), byte(a>>8)
+		}
+		buf.Write(row)
+	}
+	buf.Write(make([]byte, maskRow*size))
+}
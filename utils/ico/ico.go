@@ -0,0 +1,219 @@
+// Package ico reads the image directory of Windows .ico files: the size,
+// bit depth and encoding of every embedded image. It checks that an icon
+// looks sharp in Explorer's large views and catches PNG and JPEG files
+// renamed to .ico, which Explorer does not show as folder icons.
+package ico
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	_ "image/jpeg" // DecodeConfig of renamed files
+	_ "image/png"
+	"io"
+	"os"
+)
+
+// Issues reported in Info.Issues.
+const (
+	IssueNo256       = "no_256"       // no 256×256 image, blurry in large views
+	IssueLowRes      = "low_res"      // nothing larger than LowRes
+	IssueRenamedPNG  = "renamed_png"  // a PNG file named .ico
+	IssueRenamedJPEG = "renamed_jpeg" // a JPEG file named .ico
+	IssueNotICO      = "not_ico"
+	IssueTruncated   = "truncated" // an image lies past the end of the file
+)
+
+// Values of Info.Status.
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning" // usable, but worth replacing
+	StatusInvalid = "invalid" // Explorer will not show it
+)
+
+// LowRes is the largest size that still counts as low resolution.
+const LowRes = 48
+
+// Info describes one icon file.
+type Info struct {
+	Format  string   `json:"format"` // "ico", or "png", "jpeg" for renamed images
+	Status  string   `json:"status"` // "ok", "warning" or "invalid"
+	Images  []Image  `json:"images,omitempty"`
+	Largest int      `json:"largest,omitempty"` // width of the largest image
+	Issues  []string `json:"issues,omitempty"`
+}
+
+// Image is one entry of the icon directory.
+type Image struct {
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	BitDepth int    `json:"bit_depth,omitempty"`
+	Format   string `json:"format"` // "png" or "bmp"
+	Bytes    int64  `json:"bytes"`
+}
+
+var (
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	jpegSignature = []byte{0xFF, 0xD8, 0xFF}
+)
+
+// File reads the icon at path. Errors are only returned when the file
+// cannot be read; content that is not an icon is reported in Info.
+func File(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return Read(f, st.Size())
+}
+
+// Read reads an icon of size bytes from r.
+func Read(r io.ReaderAt, size int64) (*Info, error) {
+	head := make([]byte, 8)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, pngSignature):
+		return renamed(r, size, "png", IssueRenamedPNG), nil
+	case bytes.HasPrefix(head, jpegSignature):
+		return renamed(r, size, "jpeg", IssueRenamedJPEG), nil
+	case n < 6 || binary.LittleEndian.Uint16(head[0:]) != 0 || binary.LittleEndian.Uint16(head[2:]) != 1:
+		return &Info{Format: "unknown", Status: StatusInvalid, Issues: []string{IssueNotICO}}, nil
+	}
+
+	count := int(binary.LittleEndian.Uint16(head[4:]))
+	info := &Info{Format: "ico"}
+
+	dir := make([]byte, 16*count)
+	if _, err := r.ReadAt(dir, 6); err != nil {
+		info.Issues = append(info.Issues, IssueTruncated)
+		return info.finish(), nil
+	}
+
+	truncated := false
+	for i := 0; i < count; i++ {
+		e := dir[16*i : 16*i+16]
+		img := Image{
+			Width:    dimension(e[0]),
+			Height:   dimension(e[1]),
+			BitDepth: int(binary.LittleEndian.Uint16(e[6:])),
+			Format:   "bmp",
+			Bytes:    int64(binary.LittleEndian.Uint32(e[8:])),
+		}
+		offset := int64(binary.LittleEndian.Uint32(e[12:]))
+
+		if offset+img.Bytes > size {
+			truncated = true
+			continue
+		}
+		readImage(r, offset, &img)
+		info.Images = append(info.Images, img)
+	}
+
+	if truncated {
+		info.Issues = append(info.Issues, IssueTruncated)
+	}
+	return info.finish(), nil
+}
+
+// readImage fills in the format, and the size and bit depth stored in
+// the image itself, which win over the directory entry.
+func readImage(r io.ReaderAt, offset int64, img *Image) {
+	buf := make([]byte, 26)
+	n, _ := r.ReadAt(buf, offset)
+	buf = buf[:n]
+
+	switch {
+	case bytes.HasPrefix(buf, pngSignature) && n >= 26:
+		// the IHDR chunk follows the signature
+		img.Format = "png"
+		img.Width = int(binary.BigEndian.Uint32(buf[16:]))
+		img.Height = int(binary.BigEndian.Uint32(buf[20:]))
+		img.BitDepth = int(buf[24]) * channels(buf[25])
+	case n >= 16:
+		// BITMAPINFOHEADER; the height counts the AND mask too
+		if bits := int(binary.LittleEndian.Uint16(buf[14:])); bits > 0 {
+			img.BitDepth = bits
+		}
+	}
+}
+
+func (info *Info) finish() *Info {
+	has256 := false
+	for _, img := range info.Images {
+		info.Largest = max(info.Largest, img.Width)
+		if img.Width >= 256 {
+			has256 = true
+		}
+	}
+
+	if info.Format == "ico" {
+		if len(info.Images) == 0 && len(info.Issues) == 0 {
+			info.Issues = append(info.Issues, IssueNotICO)
+		}
+		if len(info.Images) > 0 && !has256 {
+			info.Issues = append(info.Issues, IssueNo256)
+		}
+		if len(info.Images) > 0 && info.Largest <= LowRes {
+			info.Issues = append(info.Issues, IssueLowRes)
+		}
+	}
+
+	info.Status = StatusOK
+	for _, issue := range info.Issues {
+		switch issue {
+		case IssueNo256, IssueLowRes:
+			if info.Status == StatusOK {
+				info.Status = StatusWarning
+			}
+		default:
+			info.Status = StatusInvalid
+		}
+	}
+	return info
+}
+
+// renamed describes a PNG or JPEG named .ico as one image.
+func renamed(r io.ReaderAt, size int64, format, issue string) *Info {
+	info := &Info{Format: format, Issues: []string{issue}}
+
+	cfg, _, err := image.DecodeConfig(io.NewSectionReader(r, 0, size))
+	if err == nil {
+		img := Image{Width: cfg.Width, Height: cfg.Height, Format: format, Bytes: size}
+		info.Images = append(info.Images, img)
+	}
+	return info.finish()
+}
+
+// dimension reads a directory width or height, where 0 means 256.
+func dimension(b byte) int {
+	if b == 0 {
+		return 256
+	}
+	return int(b)
+}
+
+// channels of a PNG color type.
+func channels(colorType byte) int {
+	switch colorType {
+	case 2:
+		return 3 // RGB
+	case 4:
+		return 2 // gray and alpha
+	case 6:
+		return 4 // RGBA
+	default:
+		return 1 // gray or palette
+	}
+}
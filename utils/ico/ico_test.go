@@ -0,0 +1,96 @@
+package ico_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"slices"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/utils/ico"
+)
+
+func pngBytes(t *testing.T, size int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, size, size))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func bmpBytes(size, bits int) []byte {
+	hdr := make([]byte, 40)
+	binary.LittleEndian.PutUint32(hdr[0:], 40)
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(size))
+	binary.LittleEndian.PutUint32(hdr[8:], uint32(2*size))
+	binary.LittleEndian.PutUint16(hdr[12:], 1)
+	binary.LittleEndian.PutUint16(hdr[14:], uint16(bits))
+	return append(hdr, make([]byte, size*size*bits/8)...)
+}
+
+// icoBytes builds an icon from images of the given sizes.
+func icoBytes(sizes []int, images [][]byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint16{0, 1, uint16(len(images))})
+
+	offset := 6 + 16*len(images)
+	for i, img := range images {
+		dim := byte(sizes[i])
+		if sizes[i] == 256 {
+			dim = 0
+		}
+		buf.Write([]byte{dim, dim, 0, 0})
+		binary.Write(&buf, binary.LittleEndian, []uint16{1, 32})
+		binary.Write(&buf, binary.LittleEndian, []uint32{uint32(len(img)), uint32(offset)})
+		offset += len(img)
+	}
+	for _, img := range images {
+		buf.Write(img)
+	}
+	return buf.Bytes()
+}
+
+func read(t *testing.T, data []byte) *ico.Info {
+	t.Helper()
+	info, err := ico.Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func TestRead(t *testing.T) {
+	good := read(t, icoBytes([]int{32, 256}, [][]byte{bmpBytes(32, 8), pngBytes(t, 256)}))
+	if good.Status != ico.StatusOK || len(good.Images) != 2 || good.Largest != 256 {
+		t.Fatalf("good icon = %+v", good)
+	}
+	if img := good.Images[0]; img.Format != "bmp" || img.BitDepth != 8 || img.Width != 32 {
+		t.Errorf("bmp image = %+v", img)
+	}
+	if img := good.Images[1]; img.Format != "png" || img.BitDepth != 32 || img.Height != 256 {
+		t.Errorf("png image = %+v", img)
+	}
+
+	small := read(t, icoBytes([]int{16, 32}, [][]byte{bmpBytes(16, 32), bmpBytes(32, 32)}))
+	if small.Status != ico.StatusWarning ||
+		!slices.Contains(small.Issues, ico.IssueNo256) || !slices.Contains(small.Issues, ico.IssueLowRes) {
+		t.Errorf("low resolution icon = %+v", small)
+	}
+
+	renamed := read(t, pngBytes(t, 512))
+	if renamed.Status != ico.StatusInvalid || renamed.Format != "png" ||
+		!slices.Contains(renamed.Issues, ico.IssueRenamedPNG) || renamed.Largest != 512 {
+		t.Errorf("renamed png = %+v", renamed)
+	}
+
+	cut := icoBytes([]int{256}, [][]byte{pngBytes(t, 256)})
+	if info := read(t, cut[:len(cut)-10]); !slices.Contains(info.Issues, ico.IssueTruncated) {
+		t.Errorf("truncated icon = %+v", info)
+	}
+
+	if info := read(t, []byte("not an icon")); info.Status != ico.StatusInvalid {
+		t.Errorf("text file = %+v", info)
+	}
+}
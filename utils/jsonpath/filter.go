@@ -0,0 +1,337 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// filterExpr is a compiled [?(...)] predicate evaluated against each child.
+type filterExpr interface {
+	eval(current any) bool
+}
+
+type orExpr []filterExpr
+type andExpr []filterExpr
+type notExpr struct{ inner filterExpr }
+
+// existsExpr is a bare operand such as [?(@.ext)]: true if it resolves to a
+// non-nil value.
+type existsExpr struct{ operand operand }
+
+type compareExpr struct {
+	left, right operand
+	op          string
+}
+
+// operand is either a relative path on the current node (@...) or a literal.
+type operand struct {
+	path    *compiled
+	literal any
+}
+
+func (e orExpr) eval(cur any) bool {
+	for _, x := range e {
+		if x.eval(cur) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e andExpr) eval(cur any) bool {
+	for _, x := range e {
+		if !x.eval(cur) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e notExpr) eval(cur any) bool { return !e.inner.eval(cur) }
+
+func (e existsExpr) eval(cur any) bool {
+	v, ok := e.operand.resolve(cur)
+	return ok && v != nil
+}
+
+func (e compareExpr) eval(cur any) bool {
+	l, lok := e.left.resolve(cur)
+	r, rok := e.right.resolve(cur)
+	if !lok || !rok {
+		return e.op == "!=" && lok != rok
+	}
+
+	if lf, ok := toFloat(l); ok {
+		if rf, ok := toFloat(r); ok {
+			switch e.op {
+			case "==":
+				return lf == rf
+			case "!=":
+				return lf != rf
+			case "<":
+				return lf < rf
+			case "<=":
+				return lf <= rf
+			case ">":
+				return lf > rf
+			case ">=":
+				return lf >= rf
+			}
+		}
+	}
+
+	ls, lIsStr := l.(string)
+	rs, rIsStr := r.(string)
+	if lIsStr && rIsStr {
+		switch e.op {
+		case "==":
+			return ls == rs
+		case "!=":
+			return ls != rs
+		case "<":
+			return ls < rs
+		case "<=":
+			return ls <= rs
+		case ">":
+			return ls > rs
+		case ">=":
+			return ls >= rs
+		}
+	}
+
+	// arrays and objects are not comparable with ==
+	switch e.op {
+	case "==":
+		return reflect.DeepEqual(l, r)
+	case "!=":
+		return !reflect.DeepEqual(l, r)
+	}
+
+	return false
+}
+
+func (o operand) resolve(cur any) (any, bool) {
+	if o.path == nil {
+		return o.literal, true
+	}
+
+	var (
+		val   any
+		found bool
+	)
+
+	walk(cur, o.path.segments, nil, func(v any, _ []step) bool {
+		val, found = v, true
+		return false // first match only
+	})
+
+	return val, found
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// --- filter parser ---
+
+type filterParser struct {
+	src string
+	pos int
+}
+
+func parseFilter(src string) (filterExpr, error) {
+	p := &filterParser{src: src}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %w", src, err)
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("filter %q: unexpected %q", src, p.src[p.pos:])
+	}
+
+	return expr, nil
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *filterParser) consume(tok string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	var terms orExpr
+	for {
+		t, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		terms = append(terms, t)
+		if !p.consume("||") {
+			break
+		}
+	}
+
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	var terms andExpr
+	for {
+		t, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		terms = append(terms, t)
+		if !p.consume("&&") {
+			break
+		}
+	}
+
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.consume("!") && !strings.HasPrefix(p.src[p.pos:], "=") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner}, nil
+	}
+
+	if p.consume("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		return inner, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return compareExpr{left: left, right: right, op: op}, nil
+		}
+	}
+
+	return existsExpr{left}, nil
+}
+
+func (p *filterParser) parseOperand() (operand, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return operand{}, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch c := p.src[p.pos]; {
+	case c == '@':
+		start := p.pos + 1
+		end := start
+		for end < len(p.src) {
+			ch := p.src[end]
+			if ch == '[' {
+				close, err := matchBracket(p.src, end)
+				if err != nil {
+					return operand{}, err
+				}
+				end = close + 1
+				continue
+			}
+			if strings.ContainsRune(" =!<>&|()", rune(ch)) {
+				break
+			}
+			end++
+		}
+
+		p.pos = end
+		if start == end {
+			// bare @ refers to the current node itself
+			return operand{path: &compiled{src: "@"}}, nil
+		}
+
+		c, err := parse(p.src[start:end])
+		if err != nil {
+			return operand{}, err
+		}
+		return operand{path: c}, nil
+
+	case c == '\'' || c == '"':
+		end := strings.IndexByte(p.src[p.pos+1:], c)
+		if end < 0 {
+			return operand{}, fmt.Errorf("unterminated string")
+		}
+
+		s := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return operand{literal: s}, nil
+
+	default:
+		start := p.pos
+		for p.pos < len(p.src) && !strings.ContainsRune(" =!<>&|()", rune(p.src[p.pos])) {
+			p.pos++
+		}
+
+		word := p.src[start:p.pos]
+		switch word {
+		case "true":
+			return operand{literal: true}, nil
+		case "false":
+			return operand{literal: false}, nil
+		case "null":
+			return operand{literal: nil}, nil
+		}
+
+		n, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			return operand{}, fmt.Errorf("invalid operand %q", word)
+		}
+		return operand{literal: n}, nil
+	}
+}
@@ -2,51 +2,152 @@ package jsonpath
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/mrizkifadil26/medix/utils"
 )
 
-// Get retrieves a value by path
-func Get(root any, path string) (any, error) {
-	node := root
-	tokens := strings.Split(path, ".")
+type orderedMap = utils.OrderedMap[string, any]
 
-	for i, token := range tokens {
-		isLast := i == len(tokens)-1
-		if token == "#" {
-			arr, ok := node.([]any)
+// step is one concrete hop (object key or array index) on the way to a match.
+type step struct {
+	key     string
+	index   int
+	isIndex bool
+	multi   bool // chosen by a wildcard, slice or filter
+}
 
-			if !ok {
-				return nil, fmt.Errorf("cannot use '#' at %d on type %T", i, node)
-			}
+// Match is a single node selected by a path.
+type Match struct {
+	Value any
+	steps []step
+}
 
-			if i == len(tokens)-1 {
-				if len(arr) == 0 {
-					return nil, nil // empty slice → nil
-				} else if len(arr) == 1 {
-					return arr[0], nil // single element → return it
-				} else {
-					return arr, nil // multiple elements → return the slice
-				}
-			}
+// Path returns the concrete dotted location of the match, e.g. "items.3.name".
+func (m Match) Path() string {
+	parts := make([]string, len(m.steps))
+	for i, s := range m.steps {
+		if s.isIndex {
+			parts[i] = strconv.Itoa(s.index)
+		} else {
+			parts[i] = s.key
+		}
+	}
 
-			results := make([]any, 0, len(arr))
-			for _, elem := range arr {
-				val, err := Get(elem, strings.Join(tokens[i+1:], "."))
-				if err == nil {
-					results = append(results, val)
-				}
-			}
+	return strings.Join(parts, ".")
+}
 
-			if len(results) == 0 {
-				return nil, nil
-			} else if len(results) == 1 {
-				return results[0], nil
-			} else {
-				return results, nil
-			}
+// Indexes returns the array positions picked by each wildcard, slice or
+// filter along the path, outermost first. For "items.#.name" it is the item
+// index, which callers use to resolve '#' in related target paths.
+func (m Match) Indexes() []int {
+	var out []int
+	for _, s := range m.steps {
+		if s.multi && s.isIndex {
+			out = append(out, s.index)
+		}
+	}
+
+	return out
+}
+
+// compiled paths are cached since the same selectors run for every item
+var compileCache sync.Map // string → *compiled
+
+func compile(path string) (*compiled, error) {
+	if c, ok := compileCache.Load(path); ok {
+		return c.(*compiled), nil
+	}
+
+	c, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	compileCache.Store(path, c)
+	return c, nil
+}
+
+// Query returns every value selected by path, always as a list. Missing keys
+// simply produce no results; only malformed paths return an error.
+func Query(root any, path string) ([]any, error) {
+	c, err := compile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []any{}
+	walk(root, c.segments, nil, func(v any, _ []step) bool {
+		results = append(results, v)
+		return true
+	})
+
+	return results, nil
+}
+
+// QueryMatches is like Query but also reports where each value was found.
+func QueryMatches(root any, path string) ([]Match, error) {
+	c, err := compile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectMatches(root, c.segments), nil
+}
+
+func collectMatches(root any, segments []segment) []Match {
+	matches := []Match{}
+	walk(root, segments, nil, func(v any, steps []step) bool {
+		matches = append(matches, Match{
+			Value: v,
+			steps: append([]step(nil), steps...),
+		})
+		return true
+	})
+
+	return matches
+}
+
+// Get retrieves a value by path.
+//
+// Definite paths (keys and indexes only) return the value or an error if it
+// is missing. Paths with selectors keep the historical behaviour of
+// collapsing the result: nil for no match, the element itself for a single
+// match, otherwise a slice. Prefer Query when a list is expected.
+func Get(root any, path string) (any, error) {
+	c, err := compile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.definite() {
+		return getDefinite(root, c.segments)
+	}
+
+	results := []any{}
+	walk(root, c.segments, nil, func(v any, _ []step) bool {
+		results = append(results, v)
+		return true
+	})
+
+	switch len(results) {
+	case 0:
+		return nil, nil
+	case 1:
+		return results[0], nil
+	default:
+		return results, nil
+	}
+}
+
+func getDefinite(node any, segments []segment) (any, error) {
+	for _, seg := range segments {
+		token := seg.key
+		if seg.kind == segIndex {
+			token = strconv.Itoa(seg.index)
 		}
 
 		switch n := node.(type) {
@@ -55,88 +156,341 @@ func Get(root any, path string) (any, error) {
 			if !ok {
 				return nil, fmt.Errorf("key %q not found", token)
 			}
-
 			node = val
 
-		case *utils.OrderedMap[string, any]:
+		case *orderedMap:
 			val, ok := n.Get(token)
 			if !ok {
 				return nil, fmt.Errorf("key %q not found", token)
 			}
-
 			node = val
 
 		case []any:
-			idx, err := strconv.Atoi(token)
-			if err != nil || idx < 0 || idx >= len(n) {
+			idx, ok := arrayIndex(token, len(n))
+			if !ok {
 				return nil, fmt.Errorf("invalid index %q", token)
 			}
-
 			node = n[idx]
 
 		default:
-			if !isLast {
-				return nil, fmt.Errorf("cannot descend into type %T with %q", node, token)
-			}
+			return nil, fmt.Errorf("cannot descend into type %T with %q", node, token)
 		}
 	}
 
 	return node, nil
 }
 
-// Set assigns a value by path
+// Set assigns value at every location selected by path.
+//
+// Missing objects along definite segments are created, so
+// "items.#.metadata.title" adds metadata to each item. Wildcards, slices,
+// filters and recursive descent only select existing nodes.
 func Set(root any, path string, value any) error {
-	node := root
-	tokens := strings.Split(path, ".")
+	c, err := compile(path)
+	if err != nil {
+		return err
+	}
+
+	if len(c.segments) == 0 {
+		return fmt.Errorf("jsonpath %q: cannot set the root", path)
+	}
+
+	// split at the last selector: everything after it is created on demand
+	split := 0
+	for i, seg := range c.segments {
+		if !seg.definite() {
+			split = i + 1
+		}
+	}
+
+	tail := make([]step, 0, len(c.segments)-split)
+	for _, seg := range c.segments[split:] {
+		if seg.kind == segIndex {
+			tail = append(tail, step{index: seg.index, isIndex: true})
+		} else {
+			tail = append(tail, step{key: seg.key})
+		}
+	}
+
+	_, ordered := root.(*orderedMap)
+
+	if split == 0 {
+		return setRoot(root, tail, value, ordered)
+	}
+
+	for _, m := range collectMatches(root, c.segments[:split]) {
+		full := append(append([]step(nil), m.steps...), tail...)
+		if len(full) == 0 {
+			continue
+		}
+
+		if err := setRoot(root, full, value, ordered); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setRoot(root any, steps []step, value any, ordered bool) error {
+	arr, isArr := root.([]any)
+
+	updated, err := setConcrete(root, steps, value, ordered)
+	if err != nil {
+		return err
+	}
+
+	if isArr && len(updated.([]any)) != len(arr) {
+		return fmt.Errorf("cannot grow root array")
+	}
+
+	return nil
+}
+
+// setConcrete writes value at steps below node and returns the node, which
+// differs from the input only when an array had to grow or node was nil.
+func setConcrete(node any, steps []step, value any, ordered bool) (any, error) {
+	if len(steps) == 0 {
+		return value, nil
+	}
+
+	st := steps[0]
+	rest := steps[1:]
+
+	if node == nil {
+		node = newContainer(st, ordered)
+	}
+
+	switch n := node.(type) {
+	case map[string]any:
+		key := st.token()
+		child, err := setConcrete(n[key], rest, value, ordered)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = child
+		return n, nil
+
+	case *orderedMap:
+		key := st.token()
+		existing, _ := n.Get(key)
+		child, err := setConcrete(existing, rest, value, ordered)
+		if err != nil {
+			return nil, err
+		}
+		n.Set(key, child)
+		return n, nil
+
+	case []any:
+		idx := st.index
+		if !st.isIndex {
+			i, err := strconv.Atoi(st.key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q", st.key)
+			}
+			idx = i
+		}
+
+		if idx < 0 {
+			idx += len(n)
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid index %q", st.token())
+		}
+
+		for idx >= len(n) {
+			n = append(n, nil)
+		}
+
+		child, err := setConcrete(n[idx], rest, value, ordered)
+		if err != nil {
+			return nil, err
+		}
+		n[idx] = child
+		return n, nil
+
+	default:
+		return nil, fmt.Errorf("cannot descend into type %T with %q", node, st.token())
+	}
+}
+
+func (s step) token() string {
+	if s.isIndex {
+		return strconv.Itoa(s.index)
+	}
+	return s.key
+}
+
+// newContainer creates the missing parent for next; numeric keys create
+// arrays, mirroring how dotted paths index into them.
+func newContainer(next step, ordered bool) any {
+	if _, err := strconv.Atoi(next.key); next.isIndex || err == nil {
+		return []any{}
+	}
+
+	if ordered {
+		return utils.NewOrderedMap[string, any]()
+	}
+
+	return map[string]any{}
+}
+
+// walk applies segments to node and calls emit for each result with the
+// steps that led to it. emit returns false to stop early.
+func walk(node any, segments []segment, steps []step, emit func(any, []step) bool) bool {
+	w := walker{emit: emit}
+	return w.walk(node, segments, steps)
+}
+
+type walker struct {
+	emit func(any, []step) bool
+
+	// missing is called when a key or index segment finds nothing; nil
+	// ignores it. Returning false stops the walk.
+	missing func(node any, seg segment, steps []step) bool
+}
+
+func (w walker) walk(node any, segments []segment, steps []step) bool {
+	if len(segments) == 0 {
+		return w.emit(node, steps)
+	}
 
-	for i, token := range tokens {
-		isLast := i == len(tokens)-1
+	seg := segments[0]
+	rest := segments[1:]
 
+	cont, found := applySegment(node, seg, func(child any, st step) bool {
+		return w.walk(child, rest, append(steps, st))
+	})
+	if !cont {
+		return false
+	}
+
+	if !found && w.missing != nil && seg.definite() {
+		return w.missing(node, seg, steps)
+	}
+
+	if seg.recursive {
+		// same segment again one level down
+		return eachChild(node, func(child any, st step) bool {
+			st.multi = true
+			return w.walk(child, segments, append(steps, st))
+		})
+	}
+
+	return true
+}
+
+// applySegment calls next for each child selected by seg. found is false
+// when a key or index segment matched nothing.
+func applySegment(node any, seg segment, next func(any, step) bool) (cont, found bool) {
+	switch seg.kind {
+	case segKey:
 		switch n := node.(type) {
 		case map[string]any:
-			if isLast {
-				n[token] = value
-				return nil
+			if v, ok := n[seg.key]; ok {
+				return next(v, step{key: seg.key}), true
+			}
+		case *orderedMap:
+			if v, ok := n.Get(seg.key); ok {
+				return next(v, step{key: seg.key}), true
+			}
+		case []any:
+			if seg.recursive {
+				return true, true
 			}
-			if _, ok := n[token]; !ok {
-				n[token] = make(map[string]any)
+			if idx, ok := arrayIndex(seg.key, len(n)); ok {
+				return next(n[idx], step{index: idx, isIndex: true}), true
 			}
-			node = n[token]
+		}
+
+		return true, false
 
-		case *utils.OrderedMap[string, any]:
-			if isLast {
-				n.Set(token, value)
-				return nil
+	case segIndex:
+		if n, ok := node.([]any); ok {
+			idx := seg.index
+			if idx < 0 {
+				idx += len(n)
 			}
-			if val, ok := n.Get(token); ok {
-				node = val
-			} else {
-				newMap := utils.NewOrderedMap[string, any]()
-				n.Set(token, newMap)
-				node = newMap
+			if idx >= 0 && idx < len(n) {
+				return next(n[idx], step{index: idx, isIndex: true}), true
 			}
+		}
 
-		case []any:
-			idx, err := strconv.Atoi(token)
-			if err != nil || idx < 0 {
-				return fmt.Errorf("invalid index %q", token)
+		return true, false
+
+	case segSlice:
+		if n, ok := node.([]any); ok {
+			for _, idx := range seg.slice.indices(len(n)) {
+				if !next(n[idx], step{index: idx, isIndex: true, multi: true}) {
+					return false, true
+				}
 			}
-			for idx >= len(n) {
-				n = append(n, nil)
+		}
+
+	case segWildcard:
+		return eachChild(node, func(child any, st step) bool {
+			st.multi = true
+			return next(child, st)
+		}), true
+
+	case segFilter:
+		return eachChild(node, func(child any, st step) bool {
+			if !seg.filter.eval(child) {
+				return true
 			}
-			if isLast {
-				n[idx] = value
-				return nil
+			st.multi = true
+			return next(child, st)
+		}), true
+	}
+
+	return true, true
+}
+
+// eachChild visits array elements in order and object values in key order
+// (insertion order for OrderedMap, sorted for plain maps).
+func eachChild(node any, fn func(any, step) bool) bool {
+	switch n := node.(type) {
+	case []any:
+		for i, v := range n {
+			if !fn(v, step{index: i, isIndex: true}) {
+				return false
 			}
-			if n[idx] == nil {
-				n[idx] = make(map[string]any)
+		}
+
+	case map[string]any:
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if !fn(n[k], step{key: k}) {
+				return false
 			}
-			node = n[idx]
+		}
 
-		default:
-			return fmt.Errorf("cannot descend into type %T with %q", node, token)
+	case *orderedMap:
+		for _, k := range n.Keys() {
+			v, _ := n.Get(k)
+			if !fn(v, step{key: k}) {
+				return false
+			}
 		}
 	}
 
-	return nil
+	return true
+}
+
+func arrayIndex(token string, n int) (int, bool) {
+	idx, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, false
+	}
+
+	if idx < 0 {
+		idx += n
+	}
+
+	return idx, idx >= 0 && idx < n
 }
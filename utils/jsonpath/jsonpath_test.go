@@ -0,0 +1,216 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/utils"
+	"github.com/mrizkifadil26/medix/utils/jsonpath"
+)
+
+const sample = `{
+	"item_count": 3,
+	"items": [
+		{"name": "Alien (1979)", "type": "directory", "size": 10, "metadata": {"year": "1979"}},
+		{"name": "sample.mkv", "type": "file", "size": 2},
+		{"name": "Heat (1995)", "type": "directory", "size": 30}
+	]
+}`
+
+func loadSample(t *testing.T) map[string]any {
+	t.Helper()
+
+	var data map[string]any
+	if err := json.Unmarshal([]byte(sample), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestQuery(t *testing.T) {
+	data := loadSample(t)
+
+	tests := []struct {
+		path string
+		want []any
+	}{
+		{"items.#.name", []any{"Alien (1979)", "sample.mkv", "Heat (1995)"}},
+		{"$.items[*].size", []any{10.0, 2.0, 30.0}},
+		{"items[?(@.type=='directory')].name", []any{"Alien (1979)", "Heat (1995)"}},
+		{"items[?(@.size > 5 && @.type != 'file')].size", []any{10.0, 30.0}},
+		{"items[?(@.metadata)].name", []any{"Alien (1979)"}},
+		{"items[?(!@.metadata)].name", []any{"sample.mkv", "Heat (1995)"}},
+		{"items[1:].name", []any{"sample.mkv", "Heat (1995)"}},
+		{"items[-1].name", []any{"Heat (1995)"}},
+		{"items[::-1].size", []any{30.0, 2.0, 10.0}},
+		{"..year", []any{"1979"}},
+		{"items.0['name']", []any{"Alien (1979)"}},
+		{"items.#.missing", []any{}},
+	}
+
+	for _, tt := range tests {
+		got, err := jsonpath.Query(data, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// Arrays and objects compare by content instead of panicking.
+func TestQuery_CompareArrays(t *testing.T) {
+	var data map[string]any
+	err := json.Unmarshal([]byte(`{"items": [
+		{"name": "same", "a": [1, 2], "b": [1, 2]},
+		{"name": "different", "a": [1, 2], "b": {"x": 1}}
+	]}`), &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string][]any{
+		"items[?(@.a == @.b)].name": {"same"},
+		"items[?(@.a != @.b)].name": {"different"},
+	} {
+		got, err := jsonpath.Query(data, path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestQueryMatches_Indexes(t *testing.T) {
+	data := loadSample(t)
+
+	matches, err := jsonpath.QueryMatches(data, "items[?(@.type=='directory')].name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+
+	if got := matches[1].Path(); got != "items.2.name" {
+		t.Errorf("unexpected path %q", got)
+	}
+
+	if got := matches[1].Indexes(); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("unexpected indexes %v", got)
+	}
+}
+
+func TestGet_LegacyBehaviour(t *testing.T) {
+	data := loadSample(t)
+
+	if v, err := jsonpath.Get(data, "item_count"); err != nil || v != 3.0 {
+		t.Errorf("item_count: got %v, %v", v, err)
+	}
+
+	if _, err := jsonpath.Get(data, "missing"); err == nil {
+		t.Error("expected error for missing key")
+	}
+
+	// single match collapses into a scalar
+	if v, _ := jsonpath.Get(data, "items.#.metadata.year"); v != "1979" {
+		t.Errorf("expected collapsed scalar, got %v", v)
+	}
+}
+
+func TestSet(t *testing.T) {
+	data := loadSample(t)
+
+	if err := jsonpath.Set(data, "items.#.metadata.checked", true); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := jsonpath.Query(data, "items.#.metadata.checked")
+	if len(got) != 3 {
+		t.Errorf("expected checked on every item, got %v", got)
+	}
+
+	if err := jsonpath.Set(data, "items[?(@.type=='file')].skip", true); err != nil {
+		t.Fatal(err)
+	}
+
+	skipped, _ := jsonpath.Query(data, "items[?(@.skip==true)].name")
+	if !reflect.DeepEqual(skipped, []any{"sample.mkv"}) {
+		t.Errorf("unexpected filtered set result %v", skipped)
+	}
+
+	if err := jsonpath.Set(data, "tags.2", "x"); err != nil {
+		t.Fatal(err)
+	}
+
+	if tags, _ := jsonpath.Get(data, "tags"); len(tags.([]any)) != 3 {
+		t.Errorf("expected tags to grow to 3, got %v", tags)
+	}
+}
+
+func TestSet_OrderedMap(t *testing.T) {
+	data := utils.NewOrderedMap[string, any]()
+	if err := data.UnmarshalJSON([]byte(sample)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := jsonpath.Set(data, "items.1.metadata.title", "Sample"); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := jsonpath.Get(data, "items.1.metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := meta.(*utils.OrderedMap[string, any]); !ok {
+		t.Errorf("expected created object to be an OrderedMap, got %T", meta)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, path := range []string{"items[", "items[?(@.a ==)]", "items[abc]", "items[1:2:0]"} {
+		if _, err := jsonpath.Query(map[string]any{}, path); err == nil {
+			t.Errorf("%s: expected parse error", path)
+		}
+	}
+}
+
+func TestQueryWithOptions(t *testing.T) {
+	data := loadSample(t)
+
+	vals, soft, err := jsonpath.QueryWithOptions(data, "items.#.metadata.year", jsonpath.Options{
+		Missing:       jsonpath.MissingNil,
+		CollectErrors: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(vals, []any{"1979", nil, nil}) {
+		t.Errorf("expected nil injected for missing values, got %v", vals)
+	}
+
+	if len(soft) != 2 {
+		t.Errorf("expected 2 soft errors, got %v", soft)
+	}
+
+	if _, _, err := jsonpath.QueryWithOptions(data, "items.#.metadata.year", jsonpath.Options{
+		Missing: jsonpath.MissingError,
+	}); err == nil {
+		t.Error("expected hard error for missing value")
+	}
+
+	vals, soft, _ = jsonpath.QueryWithOptions(data, "items.#.metadata.year", jsonpath.Options{})
+	if len(vals) != 1 || soft != nil {
+		t.Errorf("expected missing values to be skipped silently, got %v %v", vals, soft)
+	}
+}
@@ -0,0 +1,94 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingMode controls what a query does when a key or index along the path
+// does not exist.
+type MissingMode int
+
+const (
+	MissingSkip  MissingMode = iota // drop the branch (Query's behaviour)
+	MissingNil                      // yield nil in place of the missing value
+	MissingError                    // fail the whole query
+)
+
+// Options tunes QueryWithOptions.
+type Options struct {
+	Missing MissingMode
+
+	// CollectErrors records every skipped or nil-injected branch as a soft
+	// error instead of discarding it silently.
+	CollectErrors bool
+}
+
+// QueryWithOptions is Query with configurable handling of missing values.
+// Soft errors describe branches that were skipped or replaced by nil; err is
+// set for malformed paths, or for the first missing value under MissingError.
+func QueryWithOptions(root any, path string, opts Options) ([]any, []error, error) {
+	c, err := compile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		results  = []any{}
+		softErrs []error
+		hardErr  error
+	)
+
+	w := walker{
+		emit: func(v any, _ []step) bool {
+			results = append(results, v)
+			return true
+		},
+		missing: func(node any, seg segment, steps []step) bool {
+			msg := missingError(node, seg, steps)
+
+			switch opts.Missing {
+			case MissingError:
+				hardErr = msg
+				return false
+			case MissingNil:
+				results = append(results, nil)
+			}
+
+			if opts.CollectErrors {
+				softErrs = append(softErrs, msg)
+			}
+
+			return true
+		},
+	}
+
+	w.walk(root, c.segments, nil)
+	if hardErr != nil {
+		return nil, nil, hardErr
+	}
+
+	return results, softErrs, nil
+}
+
+func missingError(node any, seg segment, steps []step) error {
+	token := seg.key
+	if seg.kind == segIndex {
+		token = fmt.Sprint(seg.index)
+	}
+
+	parts := make([]string, 0, len(steps)+1)
+	for _, s := range steps {
+		parts = append(parts, s.token())
+	}
+	at := strings.Join(append(parts, token), ".")
+
+	switch node.(type) {
+	case map[string]any, *orderedMap:
+		return fmt.Errorf("field %q not found in object at path %q", token, at)
+	case []any:
+		return fmt.Errorf("index %q out of range at path %q", token, at)
+	default:
+		return fmt.Errorf("unexpected structure at path %q; cannot continue", at)
+	}
+}
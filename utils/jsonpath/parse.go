@@ -0,0 +1,281 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	segKey      segmentKind = iota // .name or ['name'], also numeric index on arrays
+	segIndex                       // [n], negative counts from the end
+	segSlice                       // [start:end:step]
+	segWildcard                    // .*, [*] or the legacy .#
+	segFilter                      // [?(expr)]
+)
+
+type segment struct {
+	kind      segmentKind
+	key       string
+	index     int
+	slice     sliceRange
+	filter    filterExpr
+	recursive bool // preceded by ".." (descend into every level first)
+}
+
+type sliceRange struct {
+	start, end, step int
+	hasStart, hasEnd bool
+}
+
+// definite reports whether the segment selects at most one child.
+func (s segment) definite() bool {
+	return !s.recursive && (s.kind == segKey || s.kind == segIndex)
+}
+
+// compiled is a parsed path expression.
+type compiled struct {
+	src      string
+	segments []segment
+}
+
+func (c *compiled) definite() bool {
+	for _, s := range c.segments {
+		if !s.definite() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parse compiles a path. Supported syntax:
+//
+//	items.0.name            dotted keys, numeric keys index arrays
+//	items.#.name            '#' or '*' selects every child
+//	$.items[*]['name']      optional root '$', bracket notation
+//	items[-1] items[1:5:2]  negative indexes and slices
+//	items[?(@.type=='directory' && @.size > 0)]
+//	..name                  recursive descent
+func parse(path string) (*compiled, error) {
+	p := &pathParser{src: path}
+	c := &compiled{src: path}
+
+	if strings.HasPrefix(p.src, "$") {
+		p.pos = 1
+	}
+
+	for p.pos < len(p.src) {
+		recursive := false
+
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], ".."):
+			recursive = true
+			p.pos += 2
+		case p.src[p.pos] == '.':
+			p.pos++
+		case p.src[p.pos] == '[':
+		case len(c.segments) == 0:
+			// leading bare name, e.g. "items"
+		default:
+			return nil, fmt.Errorf("jsonpath %q: unexpected %q at offset %d", path, p.src[p.pos], p.pos)
+		}
+
+		var (
+			seg segment
+			err error
+		)
+
+		if p.pos < len(p.src) && p.src[p.pos] == '[' {
+			seg, err = p.parseBracket()
+		} else {
+			seg, err = p.parseName()
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("jsonpath %q: %w", path, err)
+		}
+
+		seg.recursive = recursive
+		c.segments = append(c.segments, seg)
+	}
+
+	return c, nil
+}
+
+type pathParser struct {
+	src string
+	pos int
+}
+
+func (p *pathParser) parseName() (segment, error) {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] != '.' && p.src[p.pos] != '[' {
+		p.pos++
+	}
+
+	name := p.src[start:p.pos]
+	switch name {
+	case "":
+		return segment{}, fmt.Errorf("empty key at offset %d", start)
+	case "#", "*":
+		return segment{kind: segWildcard}, nil
+	default:
+		return segment{kind: segKey, key: name}, nil
+	}
+}
+
+func (p *pathParser) parseBracket() (segment, error) {
+	open := p.pos
+	end, err := matchBracket(p.src, open)
+	if err != nil {
+		return segment{}, err
+	}
+
+	p.pos = end + 1
+	body := strings.TrimSpace(p.src[open+1 : end])
+
+	switch {
+	case body == "*" || body == "#":
+		return segment{kind: segWildcard}, nil
+
+	case strings.HasPrefix(body, "?"):
+		expr := strings.TrimSpace(body[1:])
+		if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+			expr = expr[1 : len(expr)-1]
+		}
+
+		f, err := parseFilter(expr)
+		if err != nil {
+			return segment{}, err
+		}
+
+		return segment{kind: segFilter, filter: f}, nil
+
+	case len(body) >= 2 && (body[0] == '\'' || body[0] == '"') && body[len(body)-1] == body[0]:
+		return segment{kind: segKey, key: body[1 : len(body)-1]}, nil
+
+	case strings.Contains(body, ":"):
+		r, err := parseSlice(body)
+		if err != nil {
+			return segment{}, err
+		}
+
+		return segment{kind: segSlice, slice: r}, nil
+
+	default:
+		idx, err := strconv.Atoi(body)
+		if err != nil {
+			return segment{}, fmt.Errorf("invalid selector [%s]", body)
+		}
+
+		return segment{kind: segIndex, index: idx}, nil
+	}
+}
+
+func parseSlice(body string) (sliceRange, error) {
+	parts := strings.Split(body, ":")
+	if len(parts) > 3 {
+		return sliceRange{}, fmt.Errorf("invalid slice [%s]", body)
+	}
+
+	r := sliceRange{step: 1}
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return sliceRange{}, fmt.Errorf("invalid slice [%s]", body)
+		}
+
+		switch i {
+		case 0:
+			r.start, r.hasStart = n, true
+		case 1:
+			r.end, r.hasEnd = n, true
+		case 2:
+			if n == 0 {
+				return sliceRange{}, fmt.Errorf("slice step cannot be 0")
+			}
+			r.step = n
+		}
+	}
+
+	return r, nil
+}
+
+// indices returns the positions selected in an array of length n.
+func (r sliceRange) indices(n int) []int {
+	norm := func(i int) int {
+		if i < 0 {
+			i += n
+		}
+		return i
+	}
+
+	var out []int
+	if r.step > 0 {
+		start, end := 0, n
+		if r.hasStart {
+			start = max(norm(r.start), 0)
+		}
+		if r.hasEnd {
+			end = min(norm(r.end), n)
+		}
+
+		for i := start; i < end; i += r.step {
+			out = append(out, i)
+		}
+
+		return out
+	}
+
+	start, end := n-1, -1
+	if r.hasStart {
+		start = min(norm(r.start), n-1)
+	}
+	if r.hasEnd {
+		end = max(norm(r.end), -1)
+	}
+
+	for i := start; i > end; i += r.step {
+		out = append(out, i)
+	}
+
+	return out
+}
+
+// matchBracket returns the offset of the ']' closing the '[' at open,
+// skipping quoted strings and nested brackets inside filters.
+func matchBracket(src string, open int) (int, error) {
+	depth := 0
+	var quote byte
+
+	for i := open; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("unclosed '[' at offset %d", open)
+}
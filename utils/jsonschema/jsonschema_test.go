@@ -0,0 +1,62 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/utils/jsonschema"
+)
+
+const entrySchema = `{
+  "type": "object",
+  "required": ["items"],
+  "properties": {
+    "items": {"type": "array", "items": {"$ref": "#/$defs/entry"}}
+  },
+  "$defs": {
+    "entry": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string", "minLength": 1},
+        "year": {"type": "string", "pattern": "^[0-9]{4}$"},
+        "kind": {"enum": ["file", "directory"]},
+        "size": {"type": "integer", "minimum": 0},
+        "children": {"type": "array", "items": {"$ref": "#/$defs/entry"}}
+      },
+      "additionalProperties": false
+    }
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := jsonschema.Parse([]byte(entrySchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc any
+	_ = json.Unmarshal([]byte(`{"items": [
+		{"name": "Alien", "year": "1979", "kind": "directory", "children": [{"name": "alien.mkv", "size": 1}]},
+		{"name": "", "year": "79", "kind": "link", "size": 1.5, "extra": true},
+		{"children": [{"name": 3}]}
+	]}`), &doc)
+
+	var got []string
+	for _, v := range s.Validate(doc) {
+		got = append(got, v.Path)
+	}
+
+	// plain maps are walked in key order, so items.1 reports extra < kind < name < size < year
+	want := []string{"items.1.extra", "items.1.kind", "items.1.name", "items.1.size", "items.1.year", "items.2", "items.2.children.0.name"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violation paths\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestParseUnknownRef(t *testing.T) {
+	if _, err := jsonschema.Parse([]byte(`{"items": {"$ref": "#/$defs/missing"}}`)); err == nil {
+		t.Error("expected an error for a ref to a missing definition")
+	}
+}
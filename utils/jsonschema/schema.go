@@ -0,0 +1,252 @@
+// Package jsonschema validates decoded JSON (plain maps or OrderedMap)
+// against a subset of JSON Schema 2020-12: type, properties, required,
+// additionalProperties, items, enum, const, pattern, minLength, minimum,
+// anyOf, oneOf and local $ref into #/$defs. Other keywords are ignored.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema is a compiled schema document.
+type Schema struct {
+	root *node
+	defs map[string]*node
+}
+
+type node struct {
+	types []string
+
+	properties    map[string]*node
+	required      []string
+	additional    *node
+	noAdditional  bool
+	items         *node
+	enum          []any
+	constVal      any
+	hasConst      bool
+	pattern       *regexp.Regexp
+	minLength     *int
+	minimum       *float64
+	anyOf, oneOf  []*node
+	ref           string // name under $defs, "" for the root
+	hasRef        bool
+	alwaysInvalid bool // the schema `false`
+}
+
+// Parse compiles a schema from JSON.
+func Parse(data []byte) (*Schema, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+
+	s := &Schema{defs: map[string]*node{}}
+
+	if obj, ok := raw.(map[string]any); ok {
+		if defs, ok := obj["$defs"].(map[string]any); ok {
+			for name, def := range defs {
+				n, err := compileNode(def, "$defs/"+name)
+				if err != nil {
+					return nil, err
+				}
+				s.defs[name] = n
+			}
+		}
+	}
+
+	root, err := compileNode(raw, "")
+	if err != nil {
+		return nil, err
+	}
+	s.root = root
+
+	if err := s.checkRefs(root, map[*node]bool{}); err != nil {
+		return nil, err
+	}
+	for _, d := range s.defs {
+		if err := s.checkRefs(d, map[*node]bool{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// MustParse is Parse for embedded schemas known to be valid.
+func MustParse(data []byte) *Schema {
+	s, err := Parse(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// Def returns the schema for one entry of $defs, sharing the definitions of
+// its parent so refs keep resolving.
+func (s *Schema) Def(name string) (*Schema, bool) {
+	n, ok := s.defs[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &Schema{root: n, defs: s.defs}, true
+}
+
+func compileNode(raw any, at string) (*node, error) {
+	switch v := raw.(type) {
+	case bool:
+		return &node{alwaysInvalid: !v}, nil
+	case map[string]any:
+		// handled below
+	default:
+		return nil, fmt.Errorf("jsonschema %s: schema must be an object or boolean", at)
+	}
+
+	obj := raw.(map[string]any)
+	n := &node{}
+
+	if ref, ok := obj["$ref"].(string); ok {
+		switch {
+		case ref == "#":
+			n.hasRef = true
+		case strings.HasPrefix(ref, "#/$defs/"):
+			n.hasRef = true
+			n.ref = strings.TrimPrefix(ref, "#/$defs/")
+		default:
+			return nil, fmt.Errorf("jsonschema %s: only local $ref is supported, got %q", at, ref)
+		}
+	}
+
+	switch t := obj["type"].(type) {
+	case string:
+		n.types = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				n.types = append(n.types, s)
+			}
+		}
+	}
+
+	if props, ok := obj["properties"].(map[string]any); ok {
+		n.properties = map[string]*node{}
+		for name, p := range props {
+			child, err := compileNode(p, at+"/properties/"+name)
+			if err != nil {
+				return nil, err
+			}
+			n.properties[name] = child
+		}
+	}
+
+	if req, ok := obj["required"].([]any); ok {
+		for _, r := range req {
+			if s, ok := r.(string); ok {
+				n.required = append(n.required, s)
+			}
+		}
+	}
+
+	switch ap := obj["additionalProperties"].(type) {
+	case bool:
+		n.noAdditional = !ap
+	case map[string]any:
+		child, err := compileNode(ap, at+"/additionalProperties")
+		if err != nil {
+			return nil, err
+		}
+		n.additional = child
+	}
+
+	if items, ok := obj["items"]; ok {
+		child, err := compileNode(items, at+"/items")
+		if err != nil {
+			return nil, err
+		}
+		n.items = child
+	}
+
+	if enum, ok := obj["enum"].([]any); ok {
+		n.enum = enum
+	}
+
+	if c, ok := obj["const"]; ok {
+		n.constVal, n.hasConst = c, true
+	}
+
+	if p, ok := obj["pattern"].(string); ok {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema %s: %w", at, err)
+		}
+		n.pattern = re
+	}
+
+	if m, ok := obj["minLength"].(float64); ok {
+		v := int(m)
+		n.minLength = &v
+	}
+
+	if m, ok := obj["minimum"].(float64); ok {
+		n.minimum = &m
+	}
+
+	for key, dst := range map[string]*[]*node{"anyOf": &n.anyOf, "oneOf": &n.oneOf} {
+		list, ok := obj[key].([]any)
+		if !ok {
+			continue
+		}
+
+		for i, sub := range list {
+			child, err := compileNode(sub, fmt.Sprintf("%s/%s/%d", at, key, i))
+			if err != nil {
+				return nil, err
+			}
+			*dst = append(*dst, child)
+		}
+	}
+
+	return n, nil
+}
+
+// checkRefs reports refs to missing definitions at parse time instead of
+// on first use.
+func (s *Schema) checkRefs(n *node, seen map[*node]bool) error {
+	if n == nil || seen[n] {
+		return nil
+	}
+	seen[n] = true
+
+	if n.hasRef && n.ref != "" {
+		if _, ok := s.defs[n.ref]; !ok {
+			return fmt.Errorf("jsonschema: $ref to unknown definition %q", n.ref)
+		}
+	}
+
+	children := []*node{n.additional, n.items}
+	children = append(children, n.anyOf...)
+	children = append(children, n.oneOf...)
+
+	names := make([]string, 0, len(n.properties))
+	for name := range n.properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		children = append(children, n.properties[name])
+	}
+
+	for _, c := range children {
+		if err := s.checkRefs(c, seen); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,270 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+type orderedMap = utils.OrderedMap[string, any]
+
+// Violation is one failed check. Path is dotted like jsonpath match paths,
+// e.g. "items.3.metadata.year"; the document root is "$".
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v Violation) Error() string {
+	return v.Path + ": " + v.Message
+}
+
+// Validate checks value against the schema and returns every violation.
+func (s *Schema) Validate(value any) []Violation {
+	return s.ValidateAt(value, "")
+}
+
+// ValidateAt is Validate with paths prefixed by base, for values that are
+// part of a larger document such as a single streamed item.
+func (s *Schema) ValidateAt(value any, base string) []Violation {
+	var out []Violation
+	s.validate(s.root, value, base, &out)
+	return out
+}
+
+func (s *Schema) validate(n *node, value any, path string, out *[]Violation) {
+	report := func(format string, args ...any) {
+		p := path
+		if p == "" {
+			p = "$"
+		}
+		*out = append(*out, Violation{Path: p, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.alwaysInvalid {
+		report("not allowed")
+		return
+	}
+
+	if n.hasRef {
+		target := s.root
+		if n.ref != "" {
+			target = s.defs[n.ref]
+		}
+		s.validate(target, value, path, out)
+	}
+
+	if len(n.types) > 0 && !matchesType(n.types, value) {
+		report("expected %s, got %s", strings.Join(n.types, " or "), typeName(value))
+		return
+	}
+
+	if n.hasConst && !equal(n.constVal, value) {
+		report("must be %v", n.constVal)
+	}
+
+	if len(n.enum) > 0 {
+		found := false
+		for _, e := range n.enum {
+			if equal(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report("must be one of %v, got %v", n.enum, value)
+		}
+	}
+
+	if str, ok := value.(string); ok {
+		if n.minLength != nil && utf8.RuneCountInString(str) < *n.minLength {
+			report("shorter than %d characters", *n.minLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(str) {
+			report("%q does not match %s", str, n.pattern)
+		}
+	}
+
+	if num, ok := toNumber(value); ok && n.minimum != nil && num < *n.minimum {
+		report("must be >= %v, got %v", *n.minimum, num)
+	}
+
+	if keys, get, ok := objectView(value); ok {
+		for _, req := range n.required {
+			if _, present := get(req); !present {
+				report("missing required property %q", req)
+			}
+		}
+
+		for _, key := range keys {
+			child, _ := get(key)
+			childPath := join(path, key)
+
+			if prop, ok := n.properties[key]; ok {
+				s.validate(prop, child, childPath, out)
+				continue
+			}
+
+			if n.noAdditional {
+				*out = append(*out, Violation{Path: childPath, Message: "property not allowed"})
+			} else if n.additional != nil {
+				s.validate(n.additional, child, childPath, out)
+			}
+		}
+	}
+
+	if arr, ok := value.([]any); ok && n.items != nil {
+		for i, child := range arr {
+			s.validate(n.items, child, join(path, strconv.Itoa(i)), out)
+		}
+	}
+
+	if len(n.anyOf) > 0 {
+		matched := false
+		for _, sub := range n.anyOf {
+			if len(s.validateSub(sub, value)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			report("does not match any allowed shape")
+		}
+	}
+
+	if len(n.oneOf) > 0 {
+		matches := 0
+		for _, sub := range n.oneOf {
+			if len(s.validateSub(sub, value)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			report("must match exactly one allowed shape, matched %d", matches)
+		}
+	}
+}
+
+// validateSub validates value against a subschema of s.
+func (s *Schema) validateSub(n *node, value any) []Violation {
+	var out []Violation
+	s.validate(n, value, "", &out)
+	return out
+}
+
+// objectView gives a uniform view over both object representations; keys
+// come back in document order for OrderedMap and sorted for plain maps.
+func objectView(value any) ([]string, func(string) (any, bool), bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		return keys, func(k string) (any, bool) {
+			val, ok := v[k]
+			return val, ok
+		}, true
+
+	case *orderedMap:
+		return v.Keys(), v.Get, true
+	}
+
+	return nil, nil, false
+}
+
+func matchesType(types []string, value any) bool {
+	for _, t := range types {
+		switch t {
+		case "object":
+			if _, _, ok := objectView(value); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "number":
+			if _, ok := toNumber(value); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := toNumber(value); ok && n == math.Trunc(n) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func typeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	default:
+		if _, ok := toNumber(v); ok {
+			return "number"
+		}
+		if _, _, ok := objectView(v); ok {
+			return "object"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func toNumber(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+
+	return 0, false
+}
+
+func equal(a, b any) bool {
+	if an, ok := toNumber(a); ok {
+		bn, ok := toNumber(b)
+		return ok && an == bn
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func join(base, key string) string {
+	if base == "" {
+		return key
+	}
+
+	return base + "." + key
+}
@@ -76,3 +76,31 @@ func WriteJSON(path string, data any) error {
 	enc.SetIndent("", "  ")
 	return enc.Encode(data)
 }
+
+// WriteJSONAtomic is WriteJSON through a temporary file that replaces path
+// only once it is complete, so a crash or interrupt mid-write keeps the
+// previous content. Use it for files rewritten during a run.
+func WriteJSONAtomic(path string, data any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
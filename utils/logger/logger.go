@@ -1,6 +1,10 @@
 package logger
 
-import "io"
+import (
+	"fmt"
+	"io"
+	"strings"
+)
 
 // Level defines log severity levels.
 type Level int
@@ -30,6 +34,17 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel converts a name such as "debug" or "WARN" into a Level.
+func ParseLevel(name string) (Level, error) {
+	for l := LevelError; l <= LevelTrace; l++ {
+		if strings.EqualFold(name, l.String()) {
+			return l, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
 // Logger interface supports the specified severities.
 type Logger interface {
 	WithContext(ctx string) Logger
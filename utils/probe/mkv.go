@@ -0,0 +1,500 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Matroska element IDs, with their length marker bits, as in the spec.
+const (
+	idEBML           = 0x1A45DFA3
+	idDocType        = 0x4282
+	idSegment        = 0x18538067
+	idSeekHead       = 0x114D9B74
+	idSeek           = 0x4DBB
+	idSeekID         = 0x53AB
+	idSeekPosition   = 0x53AC
+	idInfo           = 0x1549A966
+	idTimecodeScale  = 0x2AD7B1
+	idDuration       = 0x4489
+	idTracks         = 0x1654AE6B
+	idTrackEntry     = 0xAE
+	idTrackType      = 0x83
+	idFlagDefault    = 0x88
+	idFlagForced     = 0x55AA
+	idName           = 0x536E
+	idLanguage       = 0x22B59C
+	idLanguageBCP47  = 0x22B59D
+	idCodecID        = 0x86
+	idVideo          = 0xE0
+	idPixelWidth     = 0xB0
+	idPixelHeight    = 0xBA
+	idColour         = 0x55B0
+	idBitsPerChannel = 0x55B2
+	idTransfer       = 0x55BA
+	idAudio          = 0xE1
+	idChannels       = 0x9F
+	idBlockAddMap    = 0x41E4
+	idBlockAddIDType = 0x41E7
+	idCluster        = 0x1F43B675
+
+	trackVideo    = 1
+	trackAudio    = 2
+	trackSubtitle = 17
+
+	unknownSize = -1
+
+	// maxHeader bounds how much of an Info or Tracks element is read
+	// into memory; real ones are a few kilobytes.
+	maxHeader = 16 << 20
+)
+
+// ebmlReader walks elements of a seekable stream.
+type ebmlReader struct {
+	r   io.ReadSeeker
+	pos int64
+}
+
+func (e *ebmlReader) byte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(e.r, b[:]); err != nil {
+		return 0, err
+	}
+	e.pos++
+	return b[0], nil
+}
+
+// vint reads a variable-length integer. With keepMarker it is an element
+// ID; otherwise a size, where all value bits set means unknown.
+func (e *ebmlReader) vint(keepMarker bool) (int64, error) {
+	first, err := e.byte()
+	if err != nil {
+		return 0, err
+	}
+
+	length := 1
+	for mask := byte(0x80); length <= 8 && first&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 {
+		return 0, fmt.Errorf("invalid EBML length at offset %d", e.pos-1)
+	}
+
+	value := int64(first)
+	if !keepMarker {
+		value &= int64(0xFF >> length)
+	}
+	allOnes := value == int64(0xFF>>length)
+
+	for i := 1; i < length; i++ {
+		b, err := e.byte()
+		if err != nil {
+			return 0, err
+		}
+		value = value<<8 | int64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+
+	if !keepMarker && allOnes {
+		return unknownSize, nil
+	}
+
+	return value, nil
+}
+
+func (e *ebmlReader) header() (id, size int64, err error) {
+	if id, err = e.vint(true); err != nil {
+		return 0, 0, err
+	}
+	if size, err = e.vint(false); err != nil {
+		return 0, 0, err
+	}
+	return id, size, nil
+}
+
+func (e *ebmlReader) seek(pos int64) error {
+	if _, err := e.r.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	e.pos = pos
+	return nil
+}
+
+func (e *ebmlReader) body(size int64) ([]byte, error) {
+	if size < 0 || size > maxHeader {
+		return nil, fmt.Errorf("element of %d bytes at offset %d is too large", size, e.pos)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(e.r, buf); err != nil {
+		return nil, err
+	}
+	e.pos += size
+	return buf, nil
+}
+
+func readMatroska(r io.ReadSeeker) (*Info, error) {
+	e := &ebmlReader{r: r}
+
+	id, size, err := e.header()
+	if err != nil || id != idEBML {
+		return nil, fmt.Errorf("invalid EBML header")
+	}
+
+	head, err := e.body(size)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{Container: "matroska"}
+	for _, el := range elements(head) {
+		if el.id == idDocType && string(el.data) == "webm" {
+			info.Container = "webm"
+		}
+	}
+
+	id, size, err = e.header()
+	if err != nil || id != idSegment {
+		return nil, fmt.Errorf("no Matroska segment")
+	}
+
+	segStart := e.pos
+	segEnd := int64(math.MaxInt64)
+	if size != unknownSize {
+		segEnd = segStart + size
+	}
+
+	var (
+		infoEl, tracksEl []byte
+		seeks            = map[int64]int64{}
+	)
+
+	for e.pos < segEnd && (infoEl == nil || tracksEl == nil) {
+		id, size, err := e.header()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// the headers normally precede the first cluster; if not, the
+		// seek head says where they are
+		if id == idCluster || size == unknownSize {
+			break
+		}
+
+		switch id {
+		case idInfo:
+			if infoEl, err = e.body(size); err != nil {
+				return nil, err
+			}
+		case idTracks:
+			if tracksEl, err = e.body(size); err != nil {
+				return nil, err
+			}
+		case idSeekHead:
+			data, err := e.body(size)
+			if err != nil {
+				return nil, err
+			}
+			for target, pos := range seekEntries(data) {
+				seeks[target] = segStart + pos
+			}
+		default:
+			if err := e.seek(e.pos + size); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, want := range []struct {
+		id  int64
+		dst *[]byte
+	}{{idInfo, &infoEl}, {idTracks, &tracksEl}} {
+		pos, ok := seeks[want.id]
+		if *want.dst != nil || !ok {
+			continue
+		}
+
+		if err := e.seek(pos); err != nil {
+			return nil, err
+		}
+		id, size, err := e.header()
+		if err != nil || id != want.id {
+			continue
+		}
+		if *want.dst, err = e.body(size); err != nil {
+			return nil, err
+		}
+	}
+
+	if tracksEl == nil {
+		return nil, fmt.Errorf("no Matroska track list")
+	}
+
+	parseSegmentInfo(infoEl, info)
+	parseTracks(tracksEl, info)
+
+	return info, nil
+}
+
+type element struct {
+	id   int64
+	data []byte
+}
+
+// elements splits a master element's body into its children. A malformed
+// tail is dropped.
+func elements(data []byte) []element {
+	var out []element
+
+	e := &ebmlReader{r: bytes.NewReader(data)}
+	for e.pos < int64(len(data)) {
+		id, size, err := e.header()
+		if err != nil || size == unknownSize || e.pos+size > int64(len(data)) {
+			break
+		}
+
+		out = append(out, element{id: id, data: data[e.pos : e.pos+size]})
+		_ = e.seek(e.pos + size)
+	}
+
+	return out
+}
+
+func seekEntries(data []byte) map[int64]int64 {
+	out := map[int64]int64{}
+
+	for _, seek := range elements(data) {
+		if seek.id != idSeek {
+			continue
+		}
+
+		var target, pos int64 = 0, -1
+		for _, el := range elements(seek.data) {
+			switch el.id {
+			case idSeekID:
+				target = int64(uintValue(el.data))
+			case idSeekPosition:
+				pos = int64(uintValue(el.data))
+			}
+		}
+
+		if target != 0 && pos >= 0 {
+			out[target] = pos
+		}
+	}
+
+	return out
+}
+
+func parseSegmentInfo(data []byte, info *Info) {
+	scale := 1_000_000.0 // nanoseconds per tick, the default
+	var duration float64
+
+	for _, el := range elements(data) {
+		switch el.id {
+		case idTimecodeScale:
+			scale = float64(uintValue(el.data))
+		case idDuration:
+			duration = floatValue(el.data)
+		}
+	}
+
+	info.Duration = math.Round(duration*scale/1e6) / 1e3
+}
+
+func parseTracks(data []byte, info *Info) {
+	for _, entry := range elements(data) {
+		if entry.id != idTrackEntry {
+			continue
+		}
+
+		var (
+			kind                    uint64
+			codec, name, lang       string
+			bcp47                   string
+			isDefault               = true
+			forced                  bool
+			width, height, channels int
+			transfer, depth         int
+			dolby                   bool
+		)
+
+		for _, el := range elements(entry.data) {
+			switch el.id {
+			case idTrackType:
+				kind = uintValue(el.data)
+			case idCodecID:
+				codec = stringValue(el.data)
+			case idName:
+				name = stringValue(el.data)
+			case idLanguage:
+				lang = stringValue(el.data)
+			case idLanguageBCP47:
+				bcp47 = stringValue(el.data)
+			case idFlagDefault:
+				isDefault = uintValue(el.data) != 0
+			case idFlagForced:
+				forced = uintValue(el.data) != 0
+			case idVideo:
+				for _, v := range elements(el.data) {
+					switch v.id {
+					case idPixelWidth:
+						width = int(uintValue(v.data))
+					case idPixelHeight:
+						height = int(uintValue(v.data))
+					case idColour:
+						for _, c := range elements(v.data) {
+							switch c.id {
+							case idTransfer:
+								transfer = int(uintValue(c.data))
+							case idBitsPerChannel:
+								depth = int(uintValue(c.data))
+							}
+						}
+					}
+				}
+			case idAudio:
+				for _, a := range elements(el.data) {
+					if a.id == idChannels {
+						channels = int(uintValue(a.data))
+					}
+				}
+			case idBlockAddMap:
+				for _, m := range elements(el.data) {
+					if m.id == idBlockAddIDType {
+						// 'dvcC' or 'dvvC': a Dolby Vision configuration
+						t := uintValue(m.data)
+						dolby = dolby || t == 0x64766343 || t == 0x64767643
+					}
+				}
+			}
+		}
+
+		// the ISO 639-2 code is kept when both are set, to match MP4; an
+		// absent Language element means "eng"
+		if lang == "" {
+			lang = bcp47
+		}
+		if lang == "" {
+			lang = "eng"
+		}
+		lang = language(lang)
+
+		switch kind {
+		case trackVideo:
+			if info.Video != nil {
+				continue
+			}
+
+			hdr := hdrFromTransfer(transfer)
+			if dolby {
+				hdr = "Dolby Vision"
+			}
+
+			info.Video = &VideoTrack{
+				Codec:      matroskaCodec(codec),
+				Width:      width,
+				Height:     height,
+				Resolution: resolution(width, height),
+				HDR:        hdr,
+				BitDepth:   depth,
+			}
+		case trackAudio:
+			if channels == 0 {
+				channels = 1
+			}
+
+			info.Audio = append(info.Audio, AudioTrack{
+				Codec:    matroskaCodec(codec),
+				Language: lang,
+				Channels: channels,
+				Title:    name,
+				Default:  isDefault,
+			})
+		case trackSubtitle:
+			info.Subtitles = append(info.Subtitles, SubtitleTrack{
+				Codec:    matroskaCodec(codec),
+				Language: lang,
+				Title:    name,
+				Default:  isDefault,
+				Forced:   forced,
+			})
+		}
+	}
+}
+
+var matroskaCodecs = map[string]string{
+	"V_MPEG4/ISO/AVC":  "h264",
+	"V_MPEGH/ISO/HEVC": "hevc",
+	"V_AV1":            "av1",
+	"V_VP8":            "vp8",
+	"V_VP9":            "vp9",
+	"V_MPEG4/ISO/ASP":  "mpeg4",
+	"V_MPEG2":          "mpeg2",
+	"V_MS/VFW/FOURCC":  "vfw",
+	"A_AAC":            "aac",
+	"A_AC3":            "ac3",
+	"A_EAC3":           "eac3",
+	"A_DTS":            "dts",
+	"A_TRUEHD":         "truehd",
+	"A_OPUS":           "opus",
+	"A_VORBIS":         "vorbis",
+	"A_FLAC":           "flac",
+	"A_MPEG/L3":        "mp3",
+	"A_PCM/INT/LIT":    "pcm",
+	"S_TEXT/UTF8":      "subrip",
+	"S_TEXT/ASS":       "ass",
+	"S_TEXT/SSA":       "ssa",
+	"S_TEXT/WEBVTT":    "webvtt",
+	"S_HDMV/PGS":       "pgs",
+	"S_VOBSUB":         "vobsub",
+	"S_DVBSUB":         "dvbsub",
+}
+
+// matroskaCodec shortens a CodecID; profile suffixes such as A_AAC/MPEG4/LC
+// or A_DTS/MA map to their family.
+func matroskaCodec(id string) string {
+	for cur := id; cur != ""; {
+		if name, ok := matroskaCodecs[cur]; ok {
+			return name
+		}
+
+		i := strings.LastIndexByte(cur, '/')
+		if i < 0 {
+			break
+		}
+		cur = cur[:i]
+	}
+
+	return strings.ToLower(id)
+}
+
+func uintValue(b []byte) uint64 {
+	var v uint64
+	for _, x := range b {
+		v = v<<8 | uint64(x)
+	}
+	return v
+}
+
+func floatValue(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func stringValue(b []byte) string {
+	return strings.TrimRight(string(b), "\x00")
+}
@@ -0,0 +1,301 @@
+package probe
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// maxMoov bounds the movie box read into memory. Its sample tables grow
+// with the length of the file, but stay far below this.
+const maxMoov = 256 << 20
+
+// isMP4Box reports whether the first box type is one an MP4 or QuickTime
+// file starts with.
+func isMP4Box(typ string) bool {
+	switch typ {
+	case "ftyp", "moov", "mdat", "free", "skip", "wide", "pnot":
+		return true
+	}
+
+	return false
+}
+
+type box struct {
+	typ  string
+	data []byte
+}
+
+// boxes splits data into boxes. A box whose size runs past the end is
+// truncated rather than dropped.
+func boxes(data []byte) []box {
+	var out []box
+
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		typ := string(data[4:8])
+		header := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return out
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			header = 16
+		}
+
+		if size < header {
+			return out
+		}
+		if size > uint64(len(data)) {
+			size = uint64(len(data))
+		}
+
+		out = append(out, box{typ: typ, data: data[header:size]})
+		data = data[size:]
+	}
+
+	return out
+}
+
+func child(data []byte, path ...string) []byte {
+	for _, typ := range path {
+		found := false
+		for _, b := range boxes(data) {
+			if b.typ == typ {
+				data, found = b.data, true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+
+	return data
+}
+
+// readMP4 walks the top-level boxes until it finds moov, which may come
+// before or after the media data.
+func readMP4(r io.ReadSeeker) (*Info, error) {
+	var pos int64
+	var header [16]byte
+
+	for {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return nil, fmt.Errorf("no moov box")
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		typ := string(header[4:8])
+		headerLen := int64(8)
+
+		switch size {
+		case 0:
+			if typ != "moov" {
+				return nil, fmt.Errorf("no moov box")
+			}
+			size = maxMoov
+		case 1:
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return nil, err
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerLen = 16
+		}
+
+		if size < headerLen {
+			return nil, fmt.Errorf("invalid %q box at offset %d", typ, pos)
+		}
+
+		if typ == "moov" {
+			if size-headerLen > maxMoov {
+				return nil, fmt.Errorf("moov box of %d bytes is too large", size)
+			}
+
+			data := make([]byte, size-headerLen)
+			n, err := io.ReadFull(r, data)
+			if err != nil && err != io.ErrUnexpectedEOF {
+				return nil, err
+			}
+
+			return parseMoov(data[:n]), nil
+		}
+
+		pos += size
+	}
+}
+
+func parseMoov(moov []byte) *Info {
+	info := &Info{Container: "mp4"}
+
+	if mvhd := child(moov, "mvhd"); len(mvhd) > 0 {
+		timescale, duration := versionedTimes(mvhd)
+		if timescale > 0 {
+			info.Duration = math.Round(float64(duration)/float64(timescale)*1e3) / 1e3
+		}
+	}
+
+	for _, trak := range boxes(moov) {
+		if trak.typ != "trak" {
+			continue
+		}
+
+		handler := ""
+		if hdlr := child(trak.data, "mdia", "hdlr"); len(hdlr) >= 12 {
+			handler = string(hdlr[8:12])
+		}
+
+		lang := ""
+		if mdhd := child(trak.data, "mdia", "mdhd"); len(mdhd) > 0 {
+			lang = mdhdLanguage(mdhd)
+		}
+
+		enabled := false
+		if tkhd := child(trak.data, "tkhd"); len(tkhd) >= 4 {
+			enabled = tkhd[3]&0x1 != 0
+		}
+
+		stsd := child(trak.data, "mdia", "minf", "stbl", "stsd")
+		if len(stsd) < 8 {
+			continue
+		}
+		entries := boxes(stsd[8:])
+		if len(entries) == 0 {
+			continue
+		}
+		entry := entries[0]
+
+		switch handler {
+		case "vide":
+			if info.Video == nil {
+				info.Video = videoEntry(entry)
+			}
+		case "soun":
+			channels := 0
+			if len(entry.data) >= 18 {
+				channels = int(binary.BigEndian.Uint16(entry.data[16:18]))
+			}
+
+			info.Audio = append(info.Audio, AudioTrack{
+				Codec:    mp4Codec(entry.typ),
+				Language: lang,
+				Channels: channels,
+				Default:  enabled,
+			})
+		case "sbtl", "subt", "text", "clcp":
+			info.Subtitles = append(info.Subtitles, SubtitleTrack{
+				Codec:    mp4Codec(entry.typ),
+				Language: lang,
+				Default:  enabled,
+			})
+		}
+	}
+
+	return info
+}
+
+// visualEntryHeader is the size of a VisualSampleEntry before its child
+// boxes, not counting the box header.
+const visualEntryHeader = 78
+
+func videoEntry(entry box) *VideoTrack {
+	v := &VideoTrack{Codec: mp4Codec(entry.typ)}
+
+	if len(entry.data) < visualEntryHeader {
+		return v
+	}
+
+	v.Width = int(binary.BigEndian.Uint16(entry.data[24:26]))
+	v.Height = int(binary.BigEndian.Uint16(entry.data[26:28]))
+	v.Resolution = resolution(v.Width, v.Height)
+
+	for _, b := range boxes(entry.data[visualEntryHeader:]) {
+		switch b.typ {
+		case "colr":
+			// nclx: primaries, transfer, matrix
+			if len(b.data) >= 10 && string(b.data[:4]) == "nclx" && v.HDR == "" {
+				v.HDR = hdrFromTransfer(int(binary.BigEndian.Uint16(b.data[6:8])))
+			}
+		case "dvcC", "dvvC", "dvwC":
+			v.HDR = "Dolby Vision"
+		}
+	}
+
+	return v
+}
+
+// versionedTimes reads timescale and duration from an mvhd or mdhd body,
+// whose field widths depend on the version.
+func versionedTimes(b []byte) (timescale uint32, duration uint64) {
+	if b[0] == 1 {
+		if len(b) < 32 {
+			return 0, 0
+		}
+		return binary.BigEndian.Uint32(b[20:24]), binary.BigEndian.Uint64(b[24:32])
+	}
+
+	if len(b) < 20 {
+		return 0, 0
+	}
+	return binary.BigEndian.Uint32(b[12:16]), uint64(binary.BigEndian.Uint32(b[16:20]))
+}
+
+// mdhdLanguage decodes the packed ISO 639-2 code: three 5-bit letters
+// offset from 0x60.
+func mdhdLanguage(b []byte) string {
+	off := 20
+	if b[0] == 1 {
+		off = 32
+	}
+	if len(b) < off+2 {
+		return ""
+	}
+
+	packed := binary.BigEndian.Uint16(b[off:])
+	code := []byte{
+		byte(packed>>10&0x1F) + 0x60,
+		byte(packed>>5&0x1F) + 0x60,
+		byte(packed&0x1F) + 0x60,
+	}
+
+	return language(string(code))
+}
+
+var mp4Codecs = map[string]string{
+	"avc1": "h264",
+	"avc3": "h264",
+	"hvc1": "hevc",
+	"hev1": "hevc",
+	"dvh1": "hevc",
+	"dvhe": "hevc",
+	"av01": "av1",
+	"vp09": "vp9",
+	"mp4v": "mpeg4",
+	"mp4a": "aac",
+	"ac-3": "ac3",
+	"ec-3": "eac3",
+	"Opus": "opus",
+	"fLaC": "flac",
+	"alac": "alac",
+	"tx3g": "mov_text",
+	"wvtt": "webvtt",
+	"stpp": "ttml",
+	"c608": "eia_608",
+}
+
+func mp4Codec(fourcc string) string {
+	if name, ok := mp4Codecs[fourcc]; ok {
+		return name
+	}
+
+	return strings.TrimSpace(fourcc)
+}
@@ -0,0 +1,122 @@
+// Package probe reads stream metadata from the headers of Matroska (MKV,
+// WebM) and MP4/MOV files: duration, codecs, resolution, HDR and the audio
+// and subtitle tracks. Only the headers are read, never the media data.
+package probe
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Info describes one media file.
+type Info struct {
+	Container string          `json:"container"`          // "matroska", "webm" or "mp4"
+	Duration  float64         `json:"duration,omitempty"` // seconds
+	Video     *VideoTrack     `json:"video,omitempty"`    // the first video track
+	Audio     []AudioTrack    `json:"audio,omitempty"`
+	Subtitles []SubtitleTrack `json:"subtitles,omitempty"`
+}
+
+type VideoTrack struct {
+	Codec      string `json:"codec"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Resolution string `json:"resolution"`    // "2160p", "1080p", "720p" or "SD"
+	HDR        string `json:"hdr,omitempty"` // "HDR10", "HLG" or "Dolby Vision"
+	BitDepth   int    `json:"bit_depth,omitempty"`
+}
+
+type AudioTrack struct {
+	Codec    string `json:"codec"`
+	Language string `json:"language,omitempty"` // as stored, usually ISO 639-2 ("eng")
+	Channels int    `json:"channels,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Default  bool   `json:"default,omitempty"`
+}
+
+type SubtitleTrack struct {
+	Codec    string `json:"codec"`
+	Language string `json:"language,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Default  bool   `json:"default,omitempty"`
+	Forced   bool   `json:"forced,omitempty"`
+}
+
+// ErrUnsupported is returned for files that are neither Matroska nor MP4.
+var ErrUnsupported = errors.New("unsupported container")
+
+// File probes the file at path.
+func File(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Read probes r, telling the container apart by its first bytes.
+func Read(r io.ReadSeeker) (*Info, error) {
+	var head [12]byte
+	n, err := io.ReadFull(r, head[:])
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case n >= 4 && bytes.Equal(head[:4], []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return readMatroska(r)
+	case n >= 8 && isMP4Box(string(head[4:8])):
+		return readMP4(r)
+	}
+
+	return nil, ErrUnsupported
+}
+
+// resolution buckets by width first, so a 1920x800 scope picture still
+// counts as 1080p.
+func resolution(width, height int) string {
+	switch {
+	case width >= 3200 || height >= 1800:
+		return "2160p"
+	case width >= 1800 || height >= 1000:
+		return "1080p"
+	case width >= 1200 || height >= 700:
+		return "720p"
+	case width == 0 && height == 0:
+		return ""
+	}
+
+	return "SD"
+}
+
+// hdrFromTransfer maps an ITU-T H.273 transfer characteristic to its HDR
+// format; SDR transfers map to "".
+func hdrFromTransfer(tc int) string {
+	switch tc {
+	case 16:
+		return "HDR10"
+	case 18:
+		return "HLG"
+	}
+
+	return ""
+}
+
+// language drops the "undetermined" codes.
+func language(code string) string {
+	switch code {
+	case "", "und", "```", "\x00\x00\x00":
+		return ""
+	}
+
+	return code
+}
@@ -0,0 +1,187 @@
+package probe_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/utils/probe"
+)
+
+// el encodes a Matroska element with an 8-byte size.
+func el(id uint32, children ...[]byte) []byte {
+	var idBytes []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(idBytes) > 0 {
+			idBytes = append(idBytes, b)
+		}
+	}
+
+	body := bytes.Join(children, nil)
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(body)))
+	size[0] = 0x01
+
+	return bytes.Join([][]byte{idBytes, size, body}, nil)
+}
+
+func uintEl(id uint32, v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return el(id, b)
+}
+
+func floatEl(id uint32, v float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(v))
+	return el(id, b)
+}
+
+func strEl(id uint32, s string) []byte { return el(id, []byte(s)) }
+
+func TestMatroska(t *testing.T) {
+	file := bytes.Join([][]byte{
+		el(0x1A45DFA3, strEl(0x4282, "matroska")),
+		el(0x18538067,
+			el(0x1549A966,
+				uintEl(0x2AD7B1, 1_000_000),
+				floatEl(0x4489, 7_052_500), // ms
+			),
+			el(0x1654AE6B,
+				el(0xAE,
+					uintEl(0x83, 1),
+					strEl(0x86, "V_MPEGH/ISO/HEVC"),
+					el(0xE0,
+						uintEl(0xB0, 3840),
+						uintEl(0xBA, 1606),
+						el(0x55B0, uintEl(0x55B2, 10), uintEl(0x55BA, 16)),
+					),
+				),
+				el(0xAE,
+					uintEl(0x83, 2),
+					strEl(0x86, "A_DTS/MA"),
+					strEl(0x22B59C, "eng"),
+					el(0xE1, uintEl(0x9F, 8)),
+				),
+				el(0xAE,
+					uintEl(0x83, 2),
+					strEl(0x86, "A_AC3"),
+					strEl(0x22B59C, "ind"),
+					strEl(0x536E, "Commentary"),
+					uintEl(0x88, 0),
+					el(0xE1, uintEl(0x9F, 2)),
+				),
+				el(0xAE,
+					uintEl(0x83, 17),
+					strEl(0x86, "S_HDMV/PGS"),
+					strEl(0x22B59C, "eng"),
+					uintEl(0x55AA, 1),
+				),
+			),
+			el(0x1F43B675, uintEl(0xE7, 0)),
+		),
+	}, nil)
+
+	info, err := probe.Read(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &probe.Info{
+		Container: "matroska",
+		Duration:  7052.5,
+		Video: &probe.VideoTrack{
+			Codec: "hevc", Width: 3840, Height: 1606,
+			Resolution: "2160p", HDR: "HDR10", BitDepth: 10,
+		},
+		Audio: []probe.AudioTrack{
+			{Codec: "dts", Language: "eng", Channels: 8, Default: true},
+			{Codec: "ac3", Language: "ind", Channels: 2, Title: "Commentary"},
+		},
+		Subtitles: []probe.SubtitleTrack{
+			{Codec: "pgs", Language: "eng", Default: true, Forced: true},
+		},
+	}
+
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("got  %+v\nwant %+v", info, want)
+	}
+}
+
+// mp4Box encodes an ISO BMFF box.
+func mp4Box(typ string, children ...[]byte) []byte {
+	body := bytes.Join(children, nil)
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+	copy(b[4:], typ)
+	return append(b, body...)
+}
+
+func be16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+func trak(handler, lang string, entry []byte) []byte {
+	packed := uint16(lang[0]-0x60)<<10 | uint16(lang[1]-0x60)<<5 | uint16(lang[2]-0x60)
+
+	return mp4Box("trak",
+		mp4Box("tkhd", be32(1)), // version 0, flags: enabled
+		mp4Box("mdia",
+			mp4Box("mdhd", be32(0), be32(0), be32(0), be32(1000), be32(0), be16(packed), be16(0)),
+			mp4Box("hdlr", be32(0), be32(0), []byte(handler)),
+			mp4Box("minf", mp4Box("stbl", mp4Box("stsd", be32(0), be32(1), entry))),
+		),
+	)
+}
+
+func TestMP4(t *testing.T) {
+	visual := make([]byte, 78)
+	binary.BigEndian.PutUint16(visual[24:], 1280)
+	binary.BigEndian.PutUint16(visual[26:], 536)
+	colr := mp4Box("colr", []byte("nclx"), be16(9), be16(18), be16(9), []byte{0})
+
+	audio := make([]byte, 28)
+	binary.BigEndian.PutUint16(audio[16:], 6)
+
+	moov := mp4Box("moov",
+		mp4Box("mvhd", be32(0), be32(0), be32(0), be32(600), be32(600*5400)),
+		trak("vide", "und", mp4Box("avc1", visual, colr)),
+		trak("soun", "eng", mp4Box("ec-3", audio)),
+		trak("sbtl", "ind", mp4Box("tx3g")),
+	)
+
+	// moov after the media data, as many encoders write it
+	file := bytes.Join([][]byte{
+		mp4Box("ftyp", []byte("isom"), be32(0)),
+		mp4Box("mdat", make([]byte, 64)),
+		moov,
+	}, nil)
+
+	info, err := probe.Read(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &probe.Info{
+		Container: "mp4",
+		Duration:  5400,
+		Video: &probe.VideoTrack{
+			Codec: "h264", Width: 1280, Height: 536, Resolution: "720p", HDR: "HLG",
+		},
+		Audio:     []probe.AudioTrack{{Codec: "eac3", Language: "eng", Channels: 6, Default: true}},
+		Subtitles: []probe.SubtitleTrack{{Codec: "mov_text", Language: "ind", Default: true}},
+	}
+
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("got  %+v\nwant %+v", info, want)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	_, err := probe.Read(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI LIST")))
+	if !errors.Is(err, probe.ErrUnsupported) {
+		t.Errorf("err = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,355 @@
+// utils/stream.go
+//
+// This file contains a streaming rewriter for large JSON documents whose bulk
+// is a single top-level array (the "items" of scan, normalize and enrich
+// outputs), so they can be processed without holding every item in memory.
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ItemFunc receives one decoded item (objects arrive as *OrderedMap) and
+// returns the value to write in its place.
+type ItemFunc func(index int, item any) (any, error)
+
+// StreamOptions configures StreamItems.
+type StreamOptions struct {
+	Key     string // top-level array to stream, default "items"
+	Workers int    // items processed concurrently; output order is always kept
+
+	// Trailer is called after the input has been copied and may return extra
+	// top-level keys (e.g. collected errors) to append to the output object.
+	Trailer func() (*OrderedMap[string, any], error)
+}
+
+const streamIndent = "  "
+
+// StreamItems copies the JSON object in r to w, passing each element of the
+// opts.Key array through fn one at a time. Other top-level keys such as
+// item_count are copied verbatim and in their original order. The output is
+// indented like WriteJSON.
+//
+// Example:
+//
+//	err := utils.StreamItems(in, out, utils.StreamOptions{}, func(i int, item any) (any, error) {
+//		return item, jsonpath.Set(item, "seen", true)
+//	})
+func StreamItems(r io.Reader, w io.Writer, opts StreamOptions, fn ItemFunc) error {
+	if opts.Key == "" {
+		opts.Key = "items"
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	out := bufio.NewWriter(w)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	out.WriteString("{")
+	first := true
+
+	writeKey := func(key string) {
+		if !first {
+			out.WriteString(",")
+		}
+		first = false
+
+		keyBytes, _ := json.Marshal(key)
+		out.WriteString("\n" + streamIndent)
+		out.Write(keyBytes)
+		out.WriteString(": ")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("stream: expected object key, got %v", tok)
+		}
+
+		writeKey(key)
+
+		if key == opts.Key {
+			if err := streamArray(dec, out, opts.Workers, fn); err != nil {
+				return fmt.Errorf("stream %q: %w", key, err)
+			}
+			continue
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+
+		if err := writeIndented(out, raw, streamIndent); err != nil {
+			return err
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	if opts.Trailer != nil {
+		extra, err := opts.Trailer()
+		if err != nil {
+			return err
+		}
+
+		if extra != nil {
+			for _, key := range extra.Keys() {
+				val, _ := extra.Get(key)
+
+				raw, err := json.Marshal(val)
+				if err != nil {
+					return fmt.Errorf("stream trailer %q: %w", key, err)
+				}
+
+				writeKey(key)
+				if err := writeIndented(out, raw, streamIndent); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	out.WriteString("\n}\n")
+	return out.Flush()
+}
+
+// StreamFile runs StreamItems from inPath to outPath. Output goes to a
+// temporary file that replaces outPath only on success, so inPath and
+// outPath may be the same file and a failed run never leaves half a file.
+func StreamFile(inPath, outPath string, opts StreamOptions, fn ItemFunc) error {
+	in, err := os.Open(inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(outPath), filepath.Base(outPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := StreamItems(in, tmp, opts, fn); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), outPath)
+}
+
+// ItemError reports which item of a stream failed. Name is the item's
+// "name" field when it has one, to make reports readable.
+type ItemError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("item %d (%s): %v", e.Index, e.Name, e.Err)
+	}
+
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *ItemError) Unwrap() error { return e.Err }
+
+// NewItemError wraps err with the index and name of item.
+func NewItemError(index int, item any, err error) *ItemError {
+	ie := &ItemError{Index: index, Err: err}
+	if om, ok := item.(*OrderedMap[string, any]); ok {
+		if name, ok := om.Get("name"); ok {
+			ie.Name, _ = name.(string)
+		}
+	}
+
+	return ie
+}
+
+// pendingItem is an item being processed; results are written in the order
+// items were read, whichever finishes first.
+type pendingItem struct {
+	index int
+	item  any
+	done  chan struct{}
+	val   any
+	err   error
+}
+
+func streamArray(dec *json.Decoder, out *bufio.Writer, workers int, fn ItemFunc) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	workers = max(workers, 1)
+
+	var (
+		queue    = make(chan *pendingItem, workers)
+		failed   = make(chan struct{}) // closed once writeErr is set
+		writeErr error
+		wg       sync.WaitGroup
+	)
+
+	out.WriteString("[")
+
+	// writer: drains results in input order
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		fail := func(err error) {
+			writeErr = err
+			close(failed)
+		}
+
+		n := 0
+		for p := range queue {
+			<-p.done
+			if writeErr != nil {
+				continue
+			}
+
+			if p.err != nil {
+				fail(NewItemError(p.index, p.item, p.err))
+				continue
+			}
+
+			raw, err := json.Marshal(p.val)
+			if err != nil {
+				fail(NewItemError(p.index, p.item, err))
+				continue
+			}
+
+			if n > 0 {
+				out.WriteString(",")
+			}
+			out.WriteString("\n" + streamIndent + streamIndent)
+			if err := writeIndented(out, raw, streamIndent+streamIndent); err != nil {
+				fail(err)
+			}
+			n++
+		}
+
+		if n > 0 {
+			out.WriteString("\n" + streamIndent)
+		}
+		out.WriteString("]")
+	}()
+
+	sem := make(chan struct{}, workers)
+	var readErr error
+
+	// once an item has failed the output is thrown away, so stop reading
+	// and starting new items; the ones already running are waited for
+dispatch:
+	for idx := 0; dec.More(); idx++ {
+		select {
+		case <-failed:
+			break dispatch
+		default:
+		}
+
+		item, err := decodeItem(dec)
+		if err != nil {
+			readErr = err
+			break
+		}
+
+		p := &pendingItem{index: idx, item: item, done: make(chan struct{})}
+		queue <- p
+
+		sem <- struct{}{}
+		go func(idx int, item any) {
+			defer func() { <-sem }()
+			defer close(p.done)
+			p.val, p.err = fn(idx, item)
+		}(idx, item)
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if readErr != nil {
+		return readErr
+	}
+
+	if writeErr != nil {
+		return writeErr
+	}
+
+	return expectDelim(dec, ']')
+}
+
+// decodeItem decodes the next array element, keeping object key order.
+func decodeItem(dec *json.Decoder) (any, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	if len(raw) > 0 && raw[0] == '{' {
+		om := NewOrderedMap[string, any]()
+		if err := om.UnmarshalJSON(raw); err != nil {
+			return nil, err
+		}
+
+		return om, nil
+	}
+
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("stream: expected %q, got %v", want, tok)
+	}
+
+	return nil
+}
+
+func writeIndented(out *bufio.Writer, raw []byte, prefix string) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, prefix, streamIndent); err != nil {
+		return err
+	}
+
+	_, err := out.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,95 @@
+package utils_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mrizkifadil26/medix/utils"
+)
+
+func TestStreamItems(t *testing.T) {
+	input := `{"version":"1","item_count":3,"items":[{"name":"b","size":2},{"name":"a","size":1},{"name":"c","size":3}],"tags":["x"]}`
+
+	var out bytes.Buffer
+	err := utils.StreamItems(strings.NewReader(input), &out, utils.StreamOptions{
+		Workers: 3,
+		Trailer: func() (*utils.OrderedMap[string, any], error) {
+			extra := utils.NewOrderedMap[string, any]()
+			extra.Set("errors", map[string]string{})
+			return extra, nil
+		},
+	}, func(idx int, item any) (any, error) {
+		item.(*utils.OrderedMap[string, any]).Set("index", idx)
+		return item, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := utils.NewOrderedMap[string, any]()
+	if err := root.UnmarshalJSON(out.Bytes()); err != nil {
+		t.Fatalf("invalid output: %v\n%s", err, out.String())
+	}
+
+	if keys := root.Keys(); !reflect.DeepEqual(keys, []string{"version", "item_count", "items", "tags", "errors"}) {
+		t.Errorf("unexpected top-level keys %v", keys)
+	}
+
+	items, _ := root.Get("items")
+	for i, item := range items.([]any) {
+		m := item.(*utils.OrderedMap[string, any])
+		if idx, _ := m.Get("index"); idx != float64(i) {
+			t.Errorf("item %d out of order: %v", i, idx)
+		}
+
+		if keys := m.Keys(); !reflect.DeepEqual(keys, []string{"name", "size", "index"}) {
+			t.Errorf("item %d key order lost: %v", i, keys)
+		}
+	}
+}
+
+func TestStreamItems_Error(t *testing.T) {
+	input := `{"items":[1,2,3]}`
+
+	err := utils.StreamItems(strings.NewReader(input), &bytes.Buffer{}, utils.StreamOptions{},
+		func(idx int, item any) (any, error) {
+			if idx == 1 {
+				return nil, json.Unmarshal([]byte("{"), &item)
+			}
+			return item, nil
+		})
+	if err == nil || !strings.Contains(err.Error(), "item 1") {
+		t.Errorf("expected error for item 1, got %v", err)
+	}
+}
+
+func TestStreamItems_ErrorStopsDispatch(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"items":[0`)
+	for i := 1; i < 1000; i++ {
+		fmt.Fprintf(&b, ",%d", i)
+	}
+	b.WriteString("]}")
+
+	var calls atomic.Int32
+	err := utils.StreamItems(strings.NewReader(b.String()), &bytes.Buffer{}, utils.StreamOptions{Workers: 4},
+		func(idx int, item any) (any, error) {
+			calls.Add(1)
+			if idx == 0 {
+				return nil, errors.New("boom")
+			}
+			return item, nil
+		})
+	if err == nil || !strings.Contains(err.Error(), "item 0") {
+		t.Fatalf("expected error for item 0, got %v", err)
+	}
+	if n := calls.Load(); n > 100 {
+		t.Errorf("%d items processed after the first one failed", n)
+	}
+}